@@ -19,7 +19,11 @@ provider "clickhouse-schema" {
 resource "clickhouse-schema_table" "example" {
   name     = "example_table"
   database = "default"
-  engine   = "MergeTree"
+  comment  = "Example events"
+
+  engine {
+    name = "MergeTree"
+  }
 
   columns {
     name = "id"
@@ -31,11 +35,20 @@ resource "clickhouse-schema_table" "example" {
     name = "timestamp"
     type = "DateTime"
     comment = "Event timestamp"
+    default_expression = "now()"
+    codec = "CODEC(Delta, ZSTD(3))"
   }
 
   columns {
     name = "message"
     type = "String"
+    ttl  = "timestamp + INTERVAL 30 DAY"
+  }
+
+  columns {
+    name         = "message_lower"
+    type         = "String"
+    materialized = "lower(message)"
   }
 
   columns {
@@ -43,5 +56,134 @@ resource "clickhouse-schema_table" "example" {
     type = "UInt32"
   }
 
-  order_by = ["id"]
-}
\ No newline at end of file
+  indexes {
+    name        = "message_tokens"
+    expression  = "message"
+    type        = "tokenbf_v1(10240, 3, 0)"
+    granularity = 4
+  }
+
+  projections {
+    name  = "events_per_user"
+    query = "SELECT user_id, count() GROUP BY user_id"
+  }
+
+  constraints {
+    name       = "message_length"
+    expression = "length(message) < 4096"
+  }
+
+  order_by    = ["id", "timestamp"]
+  primary_key = ["id"]
+
+  settings = {
+    index_granularity = "8192"
+  }
+}
+
+# Example database schema resource managing every object of a database
+resource "clickhouse-schema_database_schema" "analytics" {
+  database = "analytics"
+
+  tables = {
+    events = {
+      engine = "MergeTree"
+      columns = [
+        { name = "id", type = "UInt64" },
+        { name = "timestamp", type = "DateTime", comment = "Event timestamp" },
+      ]
+      order_by = ["id"]
+    }
+  }
+
+  views = {
+    recent_events = {
+      query = "SELECT id, timestamp FROM analytics.events WHERE timestamp > now() - INTERVAL 1 DAY"
+    }
+  }
+
+  # Wait for ALTERs to reach every replica and allow long mutations
+  execution_settings = {
+    alter_sync         = "2"
+    max_execution_time = "600"
+  }
+}
+
+# Promote the schema of the staging cluster to production
+provider "clickhouse-schema" {
+  alias    = "staging"
+  host     = "staging.clickhouse.internal"
+  database = "default"
+}
+
+data "clickhouse-schema_database_schema" "staging_analytics" {
+  provider = clickhouse-schema.staging
+  database = "analytics"
+}
+
+resource "clickhouse-schema_database_schema" "production_analytics" {
+  database = "analytics"
+  tables   = data.clickhouse-schema_database_schema.staging_analytics.tables
+  views    = data.clickhouse-schema_database_schema.staging_analytics.views
+}
+
+# Example user restricted to the internal network
+resource "clickhouse-schema_user" "analyst" {
+  name             = "analyst"
+  password         = "change-me"
+  host_ips         = ["10.0.0.0/8"]
+  default_database = "analytics"
+}
+
+# Example role limiting the memory of the queries of its members
+resource "clickhouse-schema_role" "analysts" {
+  name = "analysts"
+
+  settings = {
+    max_memory_usage = "10000000000"
+  }
+}
+
+# Example grant of read access on the analytics database
+resource "clickhouse-schema_grant" "analysts_read" {
+  grantee    = clickhouse-schema_role.analysts.name
+  database   = "analytics"
+  privileges = ["SELECT"]
+}
+
+# Example quota limiting the analysts to 1000 queries per hour each
+resource "clickhouse-schema_quota" "analysts" {
+  name     = "analysts"
+  keyed_by = "user_name"
+  to       = [clickhouse-schema_role.analysts.name]
+
+  interval {
+    duration    = 3600
+    max_queries = 1000
+  }
+}
+
+# Example settings profile capping the memory usage of the analysts
+resource "clickhouse-schema_settings_profile" "analysts" {
+  name = "analysts"
+  to   = [clickhouse-schema_role.analysts.name]
+
+  setting {
+    name  = "max_memory_usage"
+    value = "10000000000"
+    max   = "20000000000"
+  }
+
+  setting {
+    name     = "readonly"
+    value    = "1"
+    readonly = true
+  }
+}
+
+# Example SQL user defined function
+resource "clickhouse-schema_function" "clamp_positive" {
+  name       = "clamp_positive"
+  arguments  = ["x"]
+  expression = "if(x > 0, x, 0)"
+}
@@ -0,0 +1,465 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// clickhouseClient is the connection handed to resources and data sources,
+// together with the provider-level execution policies.
+type clickhouseClient struct {
+	*sql.DB
+
+	// addresses is the number of server addresses the pool can fail over to
+	addresses     int
+	replicaHealth *replicaHealthPolicy
+
+	// retry, when set, retries the statements failing with a transient error
+	retry *retryPolicy
+
+	// shards, when set, receive table DDL one by one instead of relying on ON CLUSTER
+	shards []shardConnection
+
+	// cluster is the ON CLUSTER default of the resources that do not set their own
+	cluster string
+
+	// dependents holds the views dropped with a table being replaced, keyed by table ID
+	dependentsMu sync.Mutex
+	dependents   map[string][]dependentView
+}
+
+// stashDependents remembers the views dropped together with a table
+func (c *clickhouseClient) stashDependents(tableID string, views []dependentView) {
+	c.dependentsMu.Lock()
+	defer c.dependentsMu.Unlock()
+
+	if c.dependents == nil {
+		c.dependents = make(map[string][]dependentView)
+	}
+	c.dependents[tableID] = views
+}
+
+// takeDependents returns and forgets the views dropped together with a table
+func (c *clickhouseClient) takeDependents(tableID string) []dependentView {
+	c.dependentsMu.Lock()
+	defer c.dependentsMu.Unlock()
+
+	views := c.dependents[tableID]
+	delete(c.dependents, tableID)
+	return views
+}
+
+// withExecutionSettings returns a context whose statements run with the given
+// settings, overriding the provider defaults for those statements only.
+func withExecutionSettings(ctx context.Context, settings types.Map) context.Context {
+	if settings.IsNull() || settings.IsUnknown() || len(settings.Elements()) == 0 {
+		return ctx
+	}
+
+	values := clickhouse.Settings{}
+	for name, value := range settings.Elements() {
+		if str, ok := value.(types.String); ok && !str.IsNull() && !str.IsUnknown() {
+			values[name] = str.ValueString()
+		}
+	}
+
+	return contextWithSettings(ctx, values)
+}
+
+// executionSettingsKey holds the settings of the statements run with a context
+type executionSettingsKey struct{}
+
+// withDefaultSettings returns a context whose statements also run with the given
+// settings, unless the execution settings already set them.
+func withDefaultSettings(ctx context.Context, defaults clickhouse.Settings) context.Context {
+	if len(defaults) == 0 {
+		return ctx
+	}
+
+	values := clickhouse.Settings{}
+	for name, value := range defaults {
+		values[name] = value
+	}
+	current, _ := ctx.Value(executionSettingsKey{}).(clickhouse.Settings)
+	for name, value := range current {
+		values[name] = value
+	}
+
+	return contextWithSettings(ctx, values)
+}
+
+// contextWithSettings replaces the settings of the statements run with a context
+func contextWithSettings(ctx context.Context, values clickhouse.Settings) context.Context {
+	ctx = context.WithValue(ctx, executionSettingsKey{}, values)
+	return clickhouse.Context(ctx, clickhouse.WithSettings(values))
+}
+
+// ExecContext executes a statement, failing over to another configured address
+// when the connection to the current node is lost and retrying transient errors.
+func (c *clickhouseClient) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := c.withFailover(ctx, false, func() error {
+		var err error
+		result, err = c.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// QueryContext runs a query, failing over to another configured address
+// when the connection to the current node is lost and retrying transient errors.
+func (c *clickhouseClient) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := c.withFailover(ctx, true, func() error {
+		var err error
+		rows, err = c.DB.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowContext runs a query returning at most one row. The query only runs when the row is
+// scanned, so that it fails over to another configured address like QueryContext.
+func (c *clickhouseClient) QueryRowContext(ctx context.Context, query string, args ...any) *row {
+	return &row{client: c, ctx: ctx, query: query, args: args}
+}
+
+// row is the result of QueryRowContext
+type row struct {
+	client *clickhouseClient
+	ctx    context.Context
+	query  string
+	args   []any
+}
+
+// Scan runs the query and copies the columns of its first row into dest. Like sql.Row, it returns
+// sql.ErrNoRows when the query returns no row.
+func (r *row) Scan(dest ...any) error {
+	return r.client.withFailover(r.ctx, true, func() error {
+		return r.client.DB.QueryRowContext(r.ctx, r.query, r.args...).Scan(dest...)
+	})
+}
+
+// withFailover retries fn once per additional address when it fails with a
+// connection error. Broken connections are discarded by the pool, so the
+// retry dials the next reachable address. Once every address failed, or on
+// other transient errors, fn is retried according to the retry policy. Reads
+// are also retried on the errors after which a statement may have been applied.
+// Other statements only fail over when they never reached the server.
+func (c *clickhouseClient) withFailover(ctx context.Context, read bool, fn func() error) error {
+	return retryTransientError(ctx, c.retry, read, func() error {
+		return retryOnConnectionError(ctx, c.addresses, read, fn)
+	})
+}
+
+// retryPolicy bounds the retries of the statements failing with a transient error
+type retryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// transientErrorCodes are the ClickHouse error codes of failures that may succeed when retried
+var transientErrorCodes = map[int32]bool{
+	202: true, // TOO_MANY_SIMULTANEOUS_QUERIES
+	209: true, // SOCKET_TIMEOUT
+	210: true, // NETWORK_ERROR
+	242: true, // TABLE_IS_READ_ONLY
+	999: true, // KEEPER_EXCEPTION
+}
+
+// uncertainErrorCodes are the transient failures after which the statement may have been applied,
+// e.g. DDL still running on the cluster. Retrying DDL would fail on existing objects or run mutations twice.
+var uncertainErrorCodes = map[int32]bool{
+	159: true, // TIMEOUT_EXCEEDED
+	319: true, // UNKNOWN_STATUS_OF_INSERT
+}
+
+// isTransientError reports whether err may go away when the statement is retried. Only reads are
+// retried on the failures that leave the outcome of the statement unknown, such as a connection lost
+// once the statement was sent.
+func isTransientError(err error, read bool) bool {
+	if isConnectionError(err) {
+		return read || isUnsentError(err)
+	}
+
+	var exception *clickhouse.Exception
+	return errors.As(err, &exception) && (transientErrorCodes[exception.Code] || (read && uncertainErrorCodes[exception.Code]))
+}
+
+// retryTransientError runs fn up to the policy attempts while it fails with a transient error,
+// doubling the wait between attempts up to the maximum backoff
+func retryTransientError(ctx context.Context, policy *retryPolicy, read bool, fn func() error) error {
+	err := fn()
+	if policy == nil {
+		return err
+	}
+
+	backoff := policy.InitialBackoff
+	for attempt := 2; attempt <= policy.MaxAttempts && isTransientError(err, read); attempt++ {
+		tflog.Warn(ctx, "Transient ClickHouse error, retrying", map[string]interface{}{
+			"attempt":  attempt,
+			"retry_in": backoff.String(),
+			"error":    err.Error(),
+		})
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, policy.MaxBackoff)
+		err = fn()
+	}
+	return err
+}
+
+// errOutcomeUnknown is returned when the connection was lost after a statement other than a read was sent,
+// as the statement may have been applied and replaying it on another address could apply it twice
+var errOutcomeUnknown = errors.New("connection lost after the statement was sent, it may or may not have been applied")
+
+// retryOnConnectionError runs fn up to addresses times while it fails with a connection error. Statements
+// other than reads are only run again when the failed attempt never reached the server.
+func retryOnConnectionError(ctx context.Context, addresses int, read bool, fn func() error) error {
+	err := fn()
+	for attempt := 1; attempt < addresses && isConnectionError(err) && (read || isUnsentError(err)); attempt++ {
+		tflog.Warn(ctx, "Lost connection to ClickHouse, failing over to another address", map[string]interface{}{
+			"attempt": attempt,
+			"error":   err.Error(),
+		})
+		err = fn()
+	}
+	if !read && isConnectionError(err) && !isUnsentError(err) {
+		return fmt.Errorf("%w: %w", errOutcomeUnknown, err)
+	}
+	return err
+}
+
+// pingUntilAwake pings a ClickHouse Cloud service until it accepts connections. Idled services
+// refuse or drop connections while they wake up, so connection errors are retried with an
+// exponential backoff until timeout.
+func pingUntilAwake(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := time.Second
+
+	for {
+		err := db.PingContext(ctx)
+		if !isConnectionError(err) {
+			return err
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("service did not wake up within %s: %w", timeout, err)
+		}
+
+		tflog.Info(ctx, "ClickHouse Cloud service is not reachable yet, waiting for it to wake up", map[string]interface{}{
+			"retry_in": backoff.String(),
+			"error":    err.Error(),
+		})
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 15*time.Second)
+	}
+}
+
+// shardConnection is a connection pool to the replicas of a single shard
+type shardConnection struct {
+	Num       int
+	DB        *sql.DB
+	addresses int
+}
+
+// shardMode reports whether DDL is executed shard by shard
+func (c *clickhouseClient) shardMode() bool {
+	return len(c.shards) > 0
+}
+
+// defaultCluster returns the cluster of a resource, or the provider cluster when the resource does not set one
+func (c *clickhouseClient) defaultCluster(cluster types.String) string {
+	if cluster.IsNull() || cluster.IsUnknown() {
+		return c.cluster
+	}
+	return cluster.ValueString()
+}
+
+// execOnShards executes a DDL statement on one replica of every shard that is not
+// in done, and returns the shards it succeeded on. Without shards the statement
+// runs on the provider connection.
+func (c *clickhouseClient) execOnShards(ctx context.Context, statement string, done map[int]bool) ([]int, error) {
+	if !c.shardMode() {
+		_, err := c.ExecContext(ctx, statement)
+		return nil, err
+	}
+
+	var applied []int
+	for _, shard := range c.shards {
+		if done[shard.Num] {
+			continue
+		}
+
+		tflog.Info(ctx, "Executing DDL on shard", map[string]interface{}{
+			"shard": shard.Num,
+			"sql":   statement,
+		})
+
+		err := retryTransientError(ctx, c.retry, false, func() error {
+			return retryOnConnectionError(ctx, shard.addresses, false, func() error {
+				_, err := shard.DB.ExecContext(ctx, statement)
+				return err
+			})
+		})
+		if err != nil {
+			return applied, fmt.Errorf("shard %d: %w", shard.Num, err)
+		}
+		applied = append(applied, shard.Num)
+	}
+	return applied, nil
+}
+
+// shardsWithTable lists the shards on which a table exists
+func (c *clickhouseClient) shardsWithTable(ctx context.Context, database, table string) ([]int, error) {
+	var shards []int
+	for _, shard := range c.shards {
+		var exists uint64
+		err := retryOnConnectionError(ctx, shard.addresses, true, func() error {
+			return shard.DB.QueryRowContext(ctx, "SELECT count() FROM system.tables WHERE database = ? AND name = ?",
+				database, table).Scan(&exists)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", shard.Num, err)
+		}
+		if exists > 0 {
+			shards = append(shards, shard.Num)
+		}
+	}
+	return shards, nil
+}
+
+// isConnectionError reports whether err means the node became unreachable
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isUnsentError reports whether err means the connection could not be established, so the statement
+// never reached the server. The driver reports connections broken after sending the statement as
+// driver.ErrBadConn too, so only dial failures are known not to have been sent.
+func isUnsentError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return (errors.As(err, &opErr) && opErr.Op == "dial") || errors.As(err, &dnsErr)
+}
+
+// replicaHealthPolicy bounds the replication state accepted before running DDL on a Replicated table.
+type replicaHealthPolicy struct {
+	MaxQueueSize     uint64
+	MaxAbsoluteDelay uint64
+	WaitTimeout      time.Duration
+	PollInterval     time.Duration
+}
+
+// waitForReplicaHealth blocks until the replicas of a table satisfy the replica
+// health policy, or fails once the wait timeout is exceeded. Tables that are not
+// replicated are always considered healthy.
+func (c *clickhouseClient) waitForReplicaHealth(ctx context.Context, database, table string) error {
+	if c.replicaHealth == nil {
+		return nil
+	}
+
+	deadline := time.Now().Add(c.replicaHealth.WaitTimeout)
+	for {
+		problems, err := c.replicaHealthProblems(ctx, database, table)
+		if err != nil {
+			return fmt.Errorf("could not check replica health of %s.%s: %w", database, table, err)
+		}
+		if len(problems) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("replicas of %s.%s are unhealthy: %s", database, table, strings.Join(problems, "; "))
+		}
+
+		tflog.Warn(ctx, "Waiting for replicas to become healthy before running DDL", map[string]interface{}{
+			"database": database,
+			"table":    table,
+			"problems": problems,
+		})
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.replicaHealth.PollInterval):
+		}
+	}
+}
+
+// replicaHealthProblems lists the reasons why the replicas of a table are not healthy
+func (c *clickhouseClient) replicaHealthProblems(ctx context.Context, database, table string) ([]string, error) {
+	query := `
+        SELECT replica_name, is_readonly, is_session_expired, queue_size, absolute_delay
+        FROM system.replicas
+        WHERE database = ? AND table = ?
+    `
+
+	rows, err := c.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var replica string
+		var readonly, sessionExpired uint8
+		var queueSize uint32
+		var absoluteDelay uint64
+		if err := rows.Scan(&replica, &readonly, &sessionExpired, &queueSize, &absoluteDelay); err != nil {
+			return nil, err
+		}
+
+		if readonly != 0 {
+			problems = append(problems, fmt.Sprintf("replica %s is read-only", replica))
+		}
+		if sessionExpired != 0 {
+			problems = append(problems, fmt.Sprintf("replica %s lost its Keeper session", replica))
+		}
+		if uint64(queueSize) > c.replicaHealth.MaxQueueSize {
+			problems = append(problems, fmt.Sprintf("replica %s has %d queued entries (max %d)",
+				replica, queueSize, c.replicaHealth.MaxQueueSize))
+		}
+		if absoluteDelay > c.replicaHealth.MaxAbsoluteDelay {
+			problems = append(problems, fmt.Sprintf("replica %s is %ds behind (max %ds)",
+				replica, absoluteDelay, c.replicaHealth.MaxAbsoluteDelay))
+		}
+	}
+
+	return problems, rows.Err()
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+func TestRetryTransientError(t *testing.T) {
+	policy := &retryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name     string
+		err      error
+		read     bool
+		attempts int
+	}{
+		{"lost connection on read", io.EOF, true, 3},
+		{"lost connection on DDL", io.EOF, false, 1},
+		{"refused connection on DDL", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, false, 3},
+		{"Keeper session expired", &clickhouse.Exception{Code: 999}, false, 3},
+		{"read timeout", &clickhouse.Exception{Code: 159}, true, 3},
+		{"DDL timeout", &clickhouse.Exception{Code: 159}, false, 1},
+		{"unknown insert status", &clickhouse.Exception{Code: 319}, false, 1},
+		{"syntax error", &clickhouse.Exception{Code: 62}, true, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			err := retryTransientError(context.Background(), policy, test.read, func() error {
+				attempts++
+				return test.err
+			})
+			if err != test.err {
+				t.Errorf("expected the last error to be returned, got %v", err)
+			}
+			if attempts != test.attempts {
+				t.Errorf("expected %d attempts, got %d", test.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransientErrorWithoutPolicy(t *testing.T) {
+	attempts := 0
+	retryTransientError(context.Background(), nil, true, func() error {
+		attempts++
+		return io.EOF
+	})
+	if attempts != 1 {
+		t.Errorf("expected a single attempt without a retry policy, got %d", attempts)
+	}
+}
+
+func TestRetryOnConnectionError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		read     bool
+		attempts int
+		unknown  bool
+	}{
+		{"lost connection on read", io.EOF, true, 3, false},
+		{"lost connection on DDL", io.EOF, false, 1, true},
+		{"refused connection on DDL", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, false, 3, false},
+		{"syntax error", &clickhouse.Exception{Code: 62}, false, 1, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			err := retryOnConnectionError(context.Background(), 3, test.read, func() error {
+				attempts++
+				return test.err
+			})
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected the last error to be returned, got %v", err)
+			}
+			if errors.Is(err, errOutcomeUnknown) != test.unknown {
+				t.Errorf("expected unknown outcome %t, got %v", test.unknown, err)
+			}
+			if attempts != test.attempts {
+				t.Errorf("expected %d attempts, got %d", test.attempts, attempts)
+			}
+		})
+	}
+}
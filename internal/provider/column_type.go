@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = ColumnType{}
+	_ basetypes.StringValuableWithSemanticEquals = ColumnTypeValue{}
+)
+
+// typeAliases maps the case insensitive SQL aliases of ClickHouse types to the names the server reports
+var typeAliases = map[string]string{
+	"BOOL":     "Bool",
+	"BOOLEAN":  "Bool",
+	"TINYINT":  "Int8",
+	"SMALLINT": "Int16",
+	"INT":      "Int32",
+	"INTEGER":  "Int32",
+	"BIGINT":   "Int64",
+	"FLOAT":    "Float32",
+	"REAL":     "Float32",
+	"DOUBLE":   "Float64",
+	"TEXT":     "String",
+	"BLOB":     "String",
+}
+
+// decimalPrecisions are the precisions of the DecimalN(S) spellings of Decimal(P, S)
+var decimalPrecisions = map[string]int{"32": 9, "64": 18, "128": 38, "256": 76}
+
+var (
+	decimalAliasPattern = regexp.MustCompile(`\bDecimal(32|64|128|256)\((\d+)\)`)
+	utcDateTimePattern  = regexp.MustCompile(`\bDateTime(64\(\d+)?(?:\(|,)'(?:UTC|Etc/UTC)'\)`)
+)
+
+// ColumnType is the type of the column type attribute, whose values compare ClickHouse types by meaning.
+type ColumnType struct {
+	basetypes.StringType
+}
+
+func (t ColumnType) Equal(o attr.Type) bool {
+	other, ok := o.(ColumnType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t ColumnType) String() string {
+	return "ColumnType"
+}
+
+func (t ColumnType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return ColumnTypeValue{StringValue: in}, nil
+}
+
+func (t ColumnType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	value, diags := t.ValueFromString(ctx, stringValue)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to ColumnTypeValue: %v", diags)
+	}
+	return value, nil
+}
+
+func (t ColumnType) ValueType(ctx context.Context) attr.Value {
+	return ColumnTypeValue{}
+}
+
+// ColumnTypeValue is a ClickHouse column type. Spellings the server reports differently, such as
+// Decimal64(2) for Decimal(18, 2), are semantically equal so they do not show up as drift.
+type ColumnTypeValue struct {
+	basetypes.StringValue
+}
+
+// NewColumnTypeValue returns a known column type
+func NewColumnTypeValue(value string) ColumnTypeValue {
+	return ColumnTypeValue{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v ColumnTypeValue) Equal(o attr.Value) bool {
+	other, ok := o.(ColumnTypeValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v ColumnTypeValue) Type(ctx context.Context) attr.Type {
+	return ColumnType{}
+}
+
+func (v ColumnTypeValue) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(ColumnTypeValue)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return typesEquivalent(v.ValueString(), newValue.ValueString()), diags
+}
+
+// typesEquivalent compares two ClickHouse types, ignoring whitespace, SQL aliases, the DecimalN(S) spellings
+// of decimals and the UTC time zone
+func typesEquivalent(expected, actual string) bool {
+	return normalizeColumnType(expected) == normalizeColumnType(actual)
+}
+
+// normalizeColumnType rewrites a type into a canonical spelling
+func normalizeColumnType(columnType string) string {
+	normalized := decimalAliasPattern.ReplaceAllStringFunc(typeWithoutAliases(typeWithoutSpaces(columnType)), func(match string) string {
+		groups := decimalAliasPattern.FindStringSubmatch(match)
+		return fmt.Sprintf("Decimal(%d,%s)", decimalPrecisions[groups[1]], groups[2])
+	})
+	return utcDateTimePattern.ReplaceAllStringFunc(normalized, func(match string) string {
+		if precision := utcDateTimePattern.FindStringSubmatch(match)[1]; precision != "" {
+			return "DateTime" + precision + ")"
+		}
+		return "DateTime"
+	})
+}
+
+// typeWithoutSpaces collapses whitespace outside of string literals and drops it around punctuation
+func typeWithoutSpaces(columnType string) string {
+	var out strings.Builder
+	inString, pendingSpace := false, false
+	var last byte
+	for i := 0; i < len(columnType); i++ {
+		c := columnType[i]
+		if inString {
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(columnType) {
+				i++
+				out.WriteByte(columnType[i])
+			} else if c == '\'' {
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			pendingSpace = true
+			continue
+		case '(', ')', ',', '=':
+			pendingSpace = false
+		default:
+			if pendingSpace && last != 0 && !strings.ContainsRune("(,=", rune(last)) {
+				out.WriteByte(' ')
+			}
+			pendingSpace = false
+		}
+		if c == '\'' {
+			inString = true
+		}
+		out.WriteByte(c)
+		last = c
+	}
+	return out.String()
+}
+
+// typeWithoutAliases replaces the SQL aliases of types by their ClickHouse names. Identifiers followed by a
+// space are the element names of named tuples and are kept.
+func typeWithoutAliases(columnType string) string {
+	var out strings.Builder
+	for i := 0; i < len(columnType); {
+		c := columnType[i]
+		if c == '\'' {
+			end := i + 1
+			for end < len(columnType) && columnType[end] != '\'' {
+				if columnType[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(columnType))
+			out.WriteString(columnType[i:end])
+			i = end
+			continue
+		}
+		if !isIdentifierByte(c) || (c >= '0' && c <= '9') {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(columnType) && isIdentifierByte(columnType[end]) {
+			end++
+		}
+		identifier := columnType[i:end]
+		if alias, ok := typeAliases[strings.ToUpper(identifier)]; ok && (end == len(columnType) || columnType[end] != ' ') {
+			identifier = alias
+		}
+		out.WriteString(identifier)
+		i = end
+	}
+	return out.String()
+}
+
+// isIdentifierByte reports whether c may be part of an unquoted identifier
+func isIdentifierByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
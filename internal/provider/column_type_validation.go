@@ -0,0 +1,297 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// knownColumnTypes are the names of the ClickHouse data types
+var knownColumnTypes = map[string]bool{
+	"Int8": true, "Int16": true, "Int32": true, "Int64": true, "Int128": true, "Int256": true,
+	"UInt8": true, "UInt16": true, "UInt32": true, "UInt64": true, "UInt128": true, "UInt256": true,
+	"Float32": true, "Float64": true, "BFloat16": true, "Bool": true, "String": true, "FixedString": true,
+	"Decimal": true, "Decimal32": true, "Decimal64": true, "Decimal128": true, "Decimal256": true,
+	"Date": true, "Date32": true, "DateTime": true, "DateTime32": true, "DateTime64": true, "Time": true, "Time64": true,
+	"UUID": true, "IPv4": true, "IPv6": true, "Enum": true, "Enum8": true, "Enum16": true,
+	"Nullable": true, "LowCardinality": true, "Array": true, "Map": true, "Tuple": true, "Nested": true,
+	"Variant": true, "Dynamic": true, "JSON": true, "Object": true, "Nothing": true,
+	"AggregateFunction": true, "SimpleAggregateFunction": true,
+	"Point": true, "Ring": true, "LineString": true, "MultiLineString": true, "Polygon": true, "MultiPolygon": true,
+	"Geometry": true, "IntervalNanosecond": true, "IntervalMicrosecond": true, "IntervalMillisecond": true,
+	"IntervalSecond": true, "IntervalMinute": true, "IntervalHour": true, "IntervalDay": true, "IntervalWeek": true,
+	"IntervalMonth": true, "IntervalQuarter": true, "IntervalYear": true,
+}
+
+// parameterizedTypeExamples show the types that cannot be used without parameters
+var parameterizedTypeExamples = map[string]string{
+	"FixedString":             "FixedString(16)",
+	"Decimal32":               "Decimal32(4)",
+	"Decimal64":               "Decimal64(4)",
+	"Decimal128":              "Decimal128(4)",
+	"Decimal256":              "Decimal256(4)",
+	"DateTime64":              "DateTime64(3)",
+	"Time64":                  "Time64(3)",
+	"Enum":                    "Enum('a' = 1, 'b' = 2)",
+	"Enum8":                   "Enum8('a' = 1, 'b' = 2)",
+	"Enum16":                  "Enum16('a' = 1, 'b' = 2)",
+	"Nullable":                "Nullable(String)",
+	"LowCardinality":          "LowCardinality(String)",
+	"Array":                   "Array(String)",
+	"Map":                     "Map(String, UInt64)",
+	"Nested":                  "Nested(id UInt64, name String)",
+	"Variant":                 "Variant(String, UInt64)",
+	"Object":                  "Object('json')",
+	"AggregateFunction":       "AggregateFunction(uniq, UInt64)",
+	"SimpleAggregateFunction": "SimpleAggregateFunction(sum, UInt64)",
+}
+
+// typeArgumentCounts are the minimum and maximum number of types the composite types take as arguments,
+// -1 standing for any number
+var typeArgumentCounts = map[string][2]int{
+	"Nullable":       {1, 1},
+	"LowCardinality": {1, 1},
+	"Array":          {1, 1},
+	"Map":            {2, 2},
+	"Tuple":          {0, -1},
+	"Nested":         {1, -1},
+	"Variant":        {1, -1},
+}
+
+// columnTypeAliases are the case insensitive SQL spellings ClickHouse accepts for its types, besides
+// the typeAliases it reports differently
+var columnTypeAliases = map[string]bool{
+	"CHAR": true, "CHARACTER": true, "NCHAR": true, "VARCHAR": true, "NVARCHAR": true, "VARCHAR2": true,
+	"NATIONAL": true, "CLOB": true, "TINYTEXT": true, "MEDIUMTEXT": true, "LONGTEXT": true,
+	"TINYBLOB": true, "MEDIUMBLOB": true, "LONGBLOB": true, "BYTEA": true, "BINARY": true, "VARBINARY": true,
+	"INT1": true, "BYTE": true, "MEDIUMINT": true, "SIGNED": true, "UNSIGNED": true, "BIT": true, "SET": true,
+	"YEAR": true, "DEC": true, "NUMERIC": true, "FIXED": true, "TIMESTAMP": true, "INET4": true, "INET6": true,
+}
+
+// typeModifiers are the words following a type in multi-word SQL spellings such as INT UNSIGNED or
+// DOUBLE PRECISION
+var typeModifiers = map[string]bool{
+	"UNSIGNED": true, "SIGNED": true, "PRECISION": true, "VARYING": true, "ZEROFILL": true,
+	"CHAR": true, "CHARACTER": true, "LARGE": true, "OBJECT": true,
+}
+
+// columnTypeProblem explains why a column type does not follow the ClickHouse type grammar, or returns
+// an empty string. Parameters of types such as DateTime or JSON are left for the server to check.
+func columnTypeProblem(columnType string) string {
+	parser := &typeParser{input: columnType}
+	if problem := parser.parseType(); problem != "" {
+		return problem
+	}
+	if parser.skipSpaces(); parser.pos < len(parser.input) {
+		return fmt.Sprintf("unexpected %q after the type", parser.input[parser.pos:])
+	}
+	return ""
+}
+
+// typeParser reads a column type from left to right
+type typeParser struct {
+	input string
+	pos   int
+}
+
+// parseType reads a type with its arguments
+func (p *typeParser) parseType() string {
+	p.skipSpaces()
+	name := p.identifier()
+	if name == "" {
+		if p.pos >= len(p.input) {
+			return "missing type"
+		}
+		return fmt.Sprintf("expected a type name at %q", p.input[p.pos:])
+	}
+
+	family, ok := lookupColumnType(name)
+	if !ok {
+		problem := "unknown type " + name
+		if suggestion := closestName(name, knownColumnTypes); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		return problem
+	}
+	for p.skipSpaces(); ; p.skipSpaces() {
+		start := p.pos
+		if !typeModifiers[strings.ToUpper(p.identifier())] {
+			p.pos = start
+			break
+		}
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		if example := parameterizedTypeExamples[family]; example != "" {
+			return fmt.Sprintf("%s requires parameters, e.g. %s", name, example)
+		}
+		return ""
+	}
+	p.pos++
+
+	if counts, ok := typeArgumentCounts[family]; ok {
+		count, problem := p.parseTypeList(family == "Tuple" || family == "Nested")
+		if problem != "" {
+			return problem
+		}
+		if count < counts[0] || (counts[1] >= 0 && count > counts[1]) {
+			return fmt.Sprintf("%s takes %s, got %d; e.g. %s", name, argumentCountDescription(counts), count, parameterizedTypeExamples[family])
+		}
+		return p.closeArguments(name)
+	}
+
+	if family == "AggregateFunction" || family == "SimpleAggregateFunction" {
+		// The first argument is the aggregate function, possibly with its own parameters
+		p.skipArgument()
+		if p.pos < len(p.input) && p.input[p.pos] == ',' {
+			p.pos++
+			if _, problem := p.parseTypeList(false); problem != "" {
+				return problem
+			}
+		}
+		return p.closeArguments(name)
+	}
+
+	p.skipArguments()
+	return p.closeArguments(name)
+}
+
+// parseTypeList reads comma separated types up to the closing parenthesis, optionally preceded by
+// element names, and returns their number
+func (p *typeParser) parseTypeList(named bool) (int, string) {
+	count := 0
+	for {
+		p.skipSpaces()
+		if p.pos < len(p.input) && p.input[p.pos] == ')' && count == 0 {
+			return count, ""
+		}
+		if named {
+			p.skipElementName()
+		}
+		if problem := p.parseType(); problem != "" {
+			return count, problem
+		}
+		count++
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ',' {
+			return count, ""
+		}
+		p.pos++
+	}
+}
+
+// skipElementName skips the name of a named tuple element when the type follows it
+func (p *typeParser) skipElementName() {
+	start := p.pos
+	if p.pos < len(p.input) && p.input[p.pos] == '`' {
+		for p.pos++; p.pos < len(p.input) && p.input[p.pos] != '`'; p.pos++ {
+			if p.input[p.pos] == '\\' {
+				p.pos++
+			}
+		}
+		p.pos++
+		return
+	}
+
+	p.identifier()
+	p.skipSpaces()
+	next := p.pos
+	if word := p.identifier(); word == "" || typeModifiers[strings.ToUpper(word)] {
+		p.pos = start
+		return
+	}
+	p.pos = next
+}
+
+// skipArgument skips an argument up to the next top level comma or the closing parenthesis
+func (p *typeParser) skipArgument() {
+	depth := 0
+	for ; p.pos < len(p.input); p.pos++ {
+		switch p.input[p.pos] {
+		case '\'':
+			p.skipString()
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// skipArguments skips all the arguments up to the closing parenthesis
+func (p *typeParser) skipArguments() {
+	for p.skipArgument(); p.pos < len(p.input) && p.input[p.pos] == ','; p.skipArgument() {
+		p.pos++
+	}
+}
+
+// skipString moves to the closing quote of the string literal starting at the current position
+func (p *typeParser) skipString() {
+	for p.pos++; p.pos < len(p.input) && p.input[p.pos] != '\''; p.pos++ {
+		if p.input[p.pos] == '\\' {
+			p.pos++
+		}
+	}
+}
+
+// closeArguments reads the parenthesis closing the arguments of a type
+func (p *typeParser) closeArguments(name string) string {
+	p.skipSpaces()
+	if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+		return fmt.Sprintf("missing closing parenthesis after the arguments of %s", name)
+	}
+	p.pos++
+	return ""
+}
+
+func (p *typeParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.input) && isIdentifierByte(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *typeParser) skipSpaces() {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\n\r", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// lookupColumnType returns the type a name stands for. Names are matched case insensitively so spellings
+// the server may accept are not rejected at plan time.
+func lookupColumnType(name string) (string, bool) {
+	if knownColumnTypes[name] {
+		return name, true
+	}
+	if alias, ok := typeAliases[strings.ToUpper(name)]; ok {
+		return alias, true
+	}
+	if columnTypeAliases[strings.ToUpper(name)] {
+		return name, true
+	}
+	for known := range knownColumnTypes {
+		if strings.EqualFold(known, name) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// argumentCountDescription describes the number of type arguments a composite type takes
+func argumentCountDescription(counts [2]int) string {
+	switch {
+	case counts[0] == counts[1] && counts[0] == 1:
+		return "exactly one type argument"
+	case counts[0] == counts[1]:
+		return fmt.Sprintf("exactly %d type arguments", counts[0])
+	default:
+		return "at least one type argument"
+	}
+}
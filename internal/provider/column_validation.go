@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	plainIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	quotedIdentifier       = regexp.MustCompile("^`(?:[^`\\\\]|\\\\.)+`$")
+	enumTypePattern        = regexp.MustCompile(`Enum(?:8|16)?\(`)
+	enumElementPattern     = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'\s*(?:=\s*(-?\d+))?`)
+)
+
+// tableElementKeywords start an element of a column list other than a column, so
+// the parser does not read them as column names unless they are quoted.
+var tableElementKeywords = map[string]bool{
+	"INDEX":      true,
+	"PROJECTION": true,
+	"CONSTRAINT": true,
+	"PRIMARY":    true,
+}
+
+// validateColumnModels reports configuration mistakes in a column list that the
+// server would otherwise only reject when the statement is executed.
+func validateColumnModels(columns []ColumnModel, columnsPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(columns) == 0 {
+		diags.AddAttributeError(columnsPath, "Missing columns", "A table needs at least one column.")
+		return diags
+	}
+
+	seen := map[string]int{}
+	for i, column := range columns {
+		if column.Name.IsUnknown() || column.Name.IsNull() {
+			continue
+		}
+		name := column.Name.ValueString()
+		namePath := columnsPath.AtListIndex(i).AtName("name")
+
+		if first, ok := seen[name]; ok {
+			diags.AddAttributeError(namePath, "Duplicate column name",
+				fmt.Sprintf("Column %s is already defined at position %d.", name, first+1))
+		} else {
+			seen[name] = i
+		}
+
+		if problem := identifierProblem(name); problem != "" {
+			diags.AddAttributeError(namePath, "Invalid column name", fmt.Sprintf("Column %q: %s.", name, problem))
+		}
+
+		if previous := column.PreviousName; !previous.IsUnknown() && previous.ValueString() == name {
+			diags.AddAttributeError(columnsPath.AtListIndex(i).AtName("previous_name"), "Invalid previous column name",
+				fmt.Sprintf("Column %s cannot be renamed from itself.", name))
+		}
+
+		if kinds := column.defaultKinds(); len(kinds) > 1 {
+			diags.AddAttributeError(columnsPath.AtListIndex(i), "Conflicting column expressions",
+				fmt.Sprintf("Column %s sets %s; only one of them can be used.", name, strings.Join(kinds, " and ")))
+		}
+
+		// ALIAS and EPHEMERAL columns are not stored, so there is nothing to expire or compress
+		if !column.Alias.IsNull() || !column.Ephemeral.IsNull() {
+			if !column.TTL.IsNull() {
+				diags.AddAttributeError(columnsPath.AtListIndex(i).AtName("ttl"), "Invalid column TTL",
+					fmt.Sprintf("Column %s is not stored, so it cannot have a TTL.", name))
+			}
+			if !column.Codec.IsNull() {
+				diags.AddAttributeError(columnsPath.AtListIndex(i).AtName("codec"), "Invalid column codec",
+					fmt.Sprintf("Column %s is not stored, so it cannot have a compression codec.", name))
+			}
+		}
+
+		if column.Type.IsUnknown() || column.Type.IsNull() {
+			continue
+		}
+		if problem := columnTypeProblem(column.Type.ValueString()); problem != "" {
+			diags.AddAttributeError(columnsPath.AtListIndex(i).AtName("type"), "Invalid column type",
+				fmt.Sprintf("Column %s: %s.", name, problem))
+			continue
+		}
+		for _, problem := range enumProblems(column.Type.ValueString()) {
+			diags.AddAttributeError(columnsPath.AtListIndex(i).AtName("type"), "Invalid Enum definition",
+				fmt.Sprintf("Column %s: %s.", name, problem))
+		}
+	}
+
+	return diags
+}
+
+// identifierProblem explains why a name cannot be used as an identifier. Names are quoted in the generated
+// statements, so only names the server rejects even quoted are reported.
+func identifierProblem(name string) string {
+	switch {
+	case name == "":
+		return "names cannot be empty"
+	case quotedIdentifier.MatchString(name):
+		return ""
+	case strings.TrimSpace(name) != name:
+		return "names cannot start or end with whitespace"
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return "names cannot contain control characters"
+	case !utf8.ValidString(name):
+		return "names must be valid UTF-8"
+	}
+	return ""
+}
+
+// objectNameProblem explains why a name cannot be used for a database, table or view, whose names
+// are joined with dots in references and resource IDs
+func objectNameProblem(name string) string {
+	if problem := identifierProblem(name); problem != "" {
+		return problem
+	}
+	if strings.Contains(name, ".") {
+		return "database, table and view names cannot contain dots, which separate the database from the table"
+	}
+	return ""
+}
+
+// validateObjectName reports an invalid database, table or view name
+func validateObjectName(name types.String, namePath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if name.IsNull() || name.IsUnknown() {
+		return diags
+	}
+	if problem := objectNameProblem(name.ValueString()); problem != "" {
+		diags.AddAttributeError(namePath, "Invalid name", fmt.Sprintf("Name %q: %s.", name.ValueString(), problem))
+	}
+	return diags
+}
+
+// validateUniqueNames reports elements of a list block sharing the same name
+func validateUniqueNames(kind string, names []types.String, listPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	seen := map[string]int{}
+	for i, name := range names {
+		if name.IsUnknown() || name.IsNull() {
+			continue
+		}
+
+		if first, ok := seen[name.ValueString()]; ok {
+			diags.AddAttributeError(listPath.AtListIndex(i).AtName("name"), fmt.Sprintf("Duplicate %s name", kind),
+				fmt.Sprintf("%s %s is already defined at position %d.", strings.ToUpper(kind[:1])+kind[1:], name.ValueString(), first+1))
+			continue
+		}
+		seen[name.ValueString()] = i
+	}
+
+	return diags
+}
+
+// enumProblems lists the duplicate names and values of the Enum types found in a column type
+func enumProblems(columnType string) []string {
+	var problems []string
+
+	for _, loc := range enumTypePattern.FindAllStringIndex(columnType, -1) {
+		body := enumBody(columnType[loc[1]:])
+
+		names := map[string]bool{}
+		values := map[string]bool{}
+		for _, element := range enumElementPattern.FindAllStringSubmatch(body, -1) {
+			if names[element[1]] {
+				problems = append(problems, fmt.Sprintf("Enum name '%s' is defined more than once", element[1]))
+			}
+			names[element[1]] = true
+
+			if element[2] == "" {
+				continue
+			}
+			if values[element[2]] {
+				problems = append(problems, fmt.Sprintf("Enum value %s is assigned more than once", element[2]))
+			}
+			values[element[2]] = true
+		}
+	}
+
+	return problems
+}
+
+// enumBody returns the text up to the parenthesis closing an Enum, skipping quoted names
+func enumBody(s string) string {
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case inQuote && s[i] == '\\':
+			i++
+		case s[i] == '\'':
+			inQuote = !inQuote
+		case !inQuote && s[i] == ')':
+			return s[:i]
+		}
+	}
+	return s
+}
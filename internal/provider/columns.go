@@ -0,0 +1,405 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ephemeralDefaultPattern matches the expression the server stores for EPHEMERAL columns declared without one
+var ephemeralDefaultPattern = regexp.MustCompile(`^defaultValueOfTypeName\(.*\)$`)
+
+// columnAttributes returns the attributes of a column, shared by the table and database schema resources
+func columnAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"name": schema.StringAttribute{
+			MarkdownDescription: "Column name",
+			Required:            true,
+		},
+		"type": schema.StringAttribute{
+			MarkdownDescription: "Column type (e.g., UInt64, String, DateTime). Spellings the server reports differently, " +
+				"such as `Decimal64(2)` for `Decimal(18, 2)` or `BIGINT` for `Int64`, are not reported as drift",
+			Required:   true,
+			CustomType: ColumnType{},
+		},
+		"comment": schema.StringAttribute{
+			MarkdownDescription: "Column comment, changed in place with `ALTER TABLE ... COMMENT COLUMN`",
+			Optional:            true,
+		},
+		"default_expression": schema.StringAttribute{
+			MarkdownDescription: "Expression used when a value is not provided on insert, rendered as `DEFAULT <expr>` (e.g. `now()`)",
+			Optional:            true,
+		},
+		"materialized": schema.StringAttribute{
+			MarkdownDescription: "Expression computing the column on insert, rendered as `MATERIALIZED <expr>` (e.g. `lower(url)`). " +
+				"Materialized columns cannot be inserted into and are not returned by `SELECT *`",
+			Optional: true,
+		},
+		"alias": schema.StringAttribute{
+			MarkdownDescription: "Expression computed when the column is read, rendered as `ALIAS <expr>` (e.g. `toDate(ts)`). " +
+				"Alias columns are not stored",
+			Optional: true,
+		},
+		"ephemeral": schema.StringAttribute{
+			MarkdownDescription: "Marks the column as `EPHEMERAL`: it can be used in inserts and in the expressions of other " +
+				"columns but is not stored. Set to the default expression, or to an empty string for none",
+			Optional: true,
+		},
+		"codec": schema.StringAttribute{
+			MarkdownDescription: "Compression codecs of the column (e.g. `CODEC(Delta, ZSTD(3))` or `Delta, ZSTD(3)`). " +
+				"Codecs without parameters match the defaults filled in by the server",
+			Optional: true,
+		},
+		"ttl": schema.StringAttribute{
+			MarkdownDescription: "Column TTL expression (e.g. `ts + INTERVAL 30 DAY`) after which the values are reset to their default",
+			Optional:            true,
+		},
+		"previous_name": schema.StringAttribute{
+			MarkdownDescription: "Former name of the column. When the table still has a column with this name, it is renamed " +
+				"with `ALTER TABLE ... RENAME COLUMN` instead of being dropped and added again, keeping its data",
+			Optional: true,
+		},
+	}
+}
+
+// info converts the column model into a column definition
+func (c ColumnModel) info() ColumnInfo {
+	col := ColumnInfo{
+		Name:    c.Name.ValueString(),
+		Type:    c.Type.ValueString(),
+		Comment: c.Comment.ValueString(),
+		Codec:   c.Codec.ValueString(),
+		TTL:     c.TTL.ValueString(),
+
+		PreviousName: c.PreviousName.ValueString(),
+	}
+	switch {
+	case !c.Default.IsNull():
+		col.DefaultKind = "DEFAULT"
+		col.DefaultExpression = c.Default.ValueString()
+	case !c.Materialized.IsNull():
+		col.DefaultKind = "MATERIALIZED"
+		col.DefaultExpression = c.Materialized.ValueString()
+	case !c.Alias.IsNull():
+		col.DefaultKind = "ALIAS"
+		col.DefaultExpression = c.Alias.ValueString()
+	case !c.Ephemeral.IsNull():
+		col.DefaultKind = "EPHEMERAL"
+		col.DefaultExpression = c.Ephemeral.ValueString()
+	}
+	return col
+}
+
+// columnModel converts a column read from ClickHouse into its Terraform model
+func columnModel(col ColumnInfo) ColumnModel {
+	model := ColumnModel{
+		Name:    types.StringValue(col.Name),
+		Type:    NewColumnTypeValue(col.Type),
+		Comment: optionalString(col.Comment),
+		Codec:   optionalString(col.Codec),
+		TTL:     optionalString(col.TTL),
+		Default: types.StringNull(),
+
+		Materialized: types.StringNull(),
+		Alias:        types.StringNull(),
+		Ephemeral:    types.StringNull(),
+		PreviousName: types.StringNull(),
+	}
+	switch col.DefaultKind {
+	case "DEFAULT":
+		model.Default = types.StringValue(col.DefaultExpression)
+	case "MATERIALIZED":
+		model.Materialized = types.StringValue(col.DefaultExpression)
+	case "ALIAS":
+		model.Alias = types.StringValue(col.DefaultExpression)
+	case "EPHEMERAL":
+		model.Ephemeral = types.StringValue(col.DefaultExpression)
+	}
+	return model
+}
+
+// defaultKinds lists the attributes setting the default expression of a column
+func (c ColumnModel) defaultKinds() []string {
+	var kinds []string
+	if !c.Default.IsNull() {
+		kinds = append(kinds, "default_expression")
+	}
+	if !c.Materialized.IsNull() {
+		kinds = append(kinds, "materialized")
+	}
+	if !c.Alias.IsNull() {
+		kinds = append(kinds, "alias")
+	}
+	if !c.Ephemeral.IsNull() {
+		kinds = append(kinds, "ephemeral")
+	}
+	return kinds
+}
+
+// withSpelling keeps the expressions of the prior column model when the server only
+// reports a different spelling of them
+func (c ColumnModel) withSpelling(ctx context.Context, client *clickhouseClient, prior ColumnModel) ColumnModel {
+	if sameExpression(ctx, client, prior.Default, c.Default) {
+		c.Default = prior.Default
+	}
+	if sameExpression(ctx, client, prior.Materialized, c.Materialized) {
+		c.Materialized = prior.Materialized
+	}
+	if sameExpression(ctx, client, prior.Alias, c.Alias) {
+		c.Alias = prior.Alias
+	}
+	if sameExpression(ctx, client, prior.Ephemeral, c.Ephemeral) {
+		c.Ephemeral = prior.Ephemeral
+	}
+	if !prior.Codec.IsNull() && codecsEquivalent(prior.Codec.ValueString(), c.Codec.ValueString()) {
+		c.Codec = prior.Codec
+	}
+	if sameExpression(ctx, client, prior.TTL, c.TTL) {
+		c.TTL = prior.TTL
+	}
+	// The server does not know former names
+	c.PreviousName = prior.PreviousName
+	return c
+}
+
+// sameExpression checks whether two expression attributes are both set to equivalent expressions
+func sameExpression(ctx context.Context, client *clickhouseClient, prior, current types.String) bool {
+	return !prior.IsNull() && !current.IsNull() && expressionsEquivalent(ctx, client, prior.ValueString(), current.ValueString())
+}
+
+// defaultExpression converts the default expression reported by system.columns into the
+// configured one. EPHEMERAL columns declared without an expression report the default value of their type.
+func defaultExpression(kind, expression string) string {
+	if kind == "EPHEMERAL" && ephemeralDefaultPattern.MatchString(expression) {
+		return ""
+	}
+	return expression
+}
+
+// defaultClauseSQL renders the DEFAULT, MATERIALIZED, ALIAS or EPHEMERAL clause of a column
+func defaultClauseSQL(col ColumnInfo) string {
+	if col.DefaultExpression == "" {
+		return col.DefaultKind
+	}
+	return fmt.Sprintf("%s %s", col.DefaultKind, col.DefaultExpression)
+}
+
+// defaultDescription describes the default expression of a column for error messages
+func defaultDescription(col ColumnInfo) string {
+	if col.DefaultKind == "" {
+		return "no default expression"
+	}
+	return fmt.Sprintf("%s '%s'", col.DefaultKind, col.DefaultExpression)
+}
+
+// optionalString converts an empty value into null
+func optionalString(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+// expressionsEquivalent checks whether two expressions are the same once formatted by the server
+func expressionsEquivalent(ctx context.Context, client *clickhouseClient, a, b string) bool {
+	if strings.TrimSpace(a) == "" || strings.TrimSpace(b) == "" {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return queriesEquivalent(ctx, client, "SELECT "+a, "SELECT "+b)
+}
+
+// codecSQL renders the CODEC clause of a column, accepting codecs written with or without the CODEC wrapper
+func codecSQL(codec string) string {
+	return fmt.Sprintf("CODEC(%s)", strings.Join(codecList(codec), ", "))
+}
+
+// codecsEquivalent compares two codec chains. Codec names are case insensitive and a codec
+// without parameters matches the same codec with the parameters the server fills in, e.g. Delta and Delta(8).
+func codecsEquivalent(expected, actual string) bool {
+	expectedCodecs, actualCodecs := codecList(expected), codecList(actual)
+	if len(expectedCodecs) != len(actualCodecs) {
+		return false
+	}
+
+	for i := range expectedCodecs {
+		expectedName, expectedParams, hasParams := strings.Cut(expectedCodecs[i], "(")
+		actualName, actualParams, _ := strings.Cut(actualCodecs[i], "(")
+		if !strings.EqualFold(expectedName, actualName) {
+			return false
+		}
+		if hasParams && expectedParams != actualParams {
+			return false
+		}
+	}
+	return true
+}
+
+// codecList splits a codec chain into its codecs, without whitespace
+func codecList(codec string) []string {
+	codec = strings.Join(strings.Fields(codec), "")
+	if codec == "" {
+		return nil
+	}
+	if len(codec) > len("CODEC(") && strings.EqualFold(codec[:len("CODEC(")], "CODEC(") && strings.HasSuffix(codec, ")") {
+		codec = codec[len("CODEC(") : len(codec)-1]
+	}
+	return splitTopLevel(codec + ")")
+}
+
+// columnTTLs extracts the TTL expression of each column from a CREATE TABLE statement,
+// since system.columns does not expose them
+func columnTTLs(createQuery string) map[string]string {
+	ttls := map[string]string{}
+
+	start := topLevelIndex(createQuery, "(")
+	if start < 0 {
+		return ttls
+	}
+
+	for _, element := range splitTopLevel(createQuery[start+1:]) {
+		name, rest := splitColumnName(strings.TrimSpace(element))
+		if name == "" {
+			continue
+		}
+
+		ttl := topLevelIndex(rest, " TTL ")
+		if ttl < 0 {
+			continue
+		}
+		expression := rest[ttl+len(" TTL "):]
+		if settings := topLevelIndex(expression, " SETTINGS "); settings >= 0 {
+			expression = expression[:settings]
+		}
+		ttls[name] = strings.TrimSpace(expression)
+	}
+
+	return ttls
+}
+
+// tableElement is a named element of the column list of a CREATE TABLE statement, such as a projection
+type tableElement struct {
+	Name       string
+	Definition string
+}
+
+// tableElements extracts the elements of the column list of a CREATE TABLE statement
+// starting with the given keyword, e.g. PROJECTION or CONSTRAINT
+func tableElements(createQuery, keyword string) []tableElement {
+	start := topLevelIndex(createQuery, "(")
+	if start < 0 {
+		return nil
+	}
+
+	prefix := keyword + " "
+	var elements []tableElement
+	for _, element := range splitTopLevel(createQuery[start+1:]) {
+		element = strings.TrimSpace(element)
+		if len(element) < len(prefix) || !strings.EqualFold(element[:len(prefix)], prefix) {
+			continue
+		}
+
+		name, definition := splitColumnName(strings.TrimSpace(element[len(prefix):]))
+		if name == "" {
+			continue
+		}
+		elements = append(elements, tableElement{Name: name, Definition: strings.TrimSpace(definition)})
+	}
+
+	return elements
+}
+
+// splitColumnName splits a column list element into the column name and the rest of its
+// definition. Elements that are not columns, such as indexes, return an empty name.
+func splitColumnName(element string) (string, string) {
+	if strings.HasPrefix(element, "`") {
+		for i := 1; i < len(element); i++ {
+			switch element[i] {
+			case '\\':
+				i++
+			case '`':
+				return strings.ReplaceAll(element[1:i], "\\`", "`"), element[i+1:]
+			}
+		}
+		return "", ""
+	}
+
+	name, rest, _ := strings.Cut(element, " ")
+	if tableElementKeywords[strings.ToUpper(name)] {
+		return "", ""
+	}
+	return name, " " + rest
+}
+
+// splitTopLevel splits the content of a parenthesized list on its top level commas,
+// stopping at the closing parenthesis
+func splitTopLevel(list string) []string {
+	var elements []string
+	depth, start := 0, 0
+	scanSQL(list, func(i int, c byte) bool {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				elements = append(elements, list[start:i])
+				return false
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				elements = append(elements, list[start:i])
+				start = i + 1
+			}
+		}
+		return true
+	})
+	return elements
+}
+
+// topLevelIndex returns the index of the first occurrence of token outside of
+// parentheses and quotes, or -1
+func topLevelIndex(statement, token string) int {
+	index, depth := -1, 0
+	scanSQL(statement, func(i int, c byte) bool {
+		if depth == 0 && strings.HasPrefix(statement[i:], token) {
+			index = i
+			return false
+		}
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		return true
+	})
+	return index
+}
+
+// scanSQL calls visit for every byte of a statement that is not inside a quoted
+// string or identifier, until visit returns false
+func scanSQL(statement string, visit func(i int, c byte) bool) {
+	var quote byte
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		if !visit(i, c) {
+			return
+		}
+		if c == '\'' || c == '`' || c == '"' {
+			quote = c
+		}
+	}
+}
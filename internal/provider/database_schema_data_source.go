@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DatabaseSchemaDataSource{}
+
+func NewDatabaseSchemaDataSource() datasource.DataSource {
+	return &DatabaseSchemaDataSource{}
+}
+
+// DatabaseSchemaDataSource reads the full schema of a database.
+type DatabaseSchemaDataSource struct {
+	client *clickhouseClient
+}
+
+// DatabaseSchemaDataSourceModel describes the data source data model.
+type DatabaseSchemaDataSourceModel struct {
+	ID         types.String                `tfsdk:"id"`
+	Database   types.String                `tfsdk:"database"`
+	Tables     map[string]SchemaTableModel `tfsdk:"tables"`
+	Views      map[string]SchemaViewModel  `tfsdk:"views"`
+	SchemaJSON types.String                `tfsdk:"schema_json"`
+}
+
+func (d *DatabaseSchemaDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_schema"
+}
+
+func (d *DatabaseSchemaDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads every table and view of a ClickHouse database. The `tables` and `views` " +
+			"outputs have the same shape as the `database_schema` resource inputs, so a schema read through one " +
+			"provider alias can be promoted as the desired state of another. The `schema_json` output can be " +
+			"passed to the `schema_diff` data source of another provider alias to compare environments.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Database schema identifier",
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Name of the database to read",
+				Required:            true,
+			},
+			"tables": schema.MapNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Tables of the database, keyed by table name",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"engine": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Table engine",
+						},
+						"columns": schema.ListNestedAttribute{
+							Computed:            true,
+							MarkdownDescription: "Table columns definition",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: computedColumnAttributes(),
+							},
+						},
+						"order_by": schema.ListAttribute{
+							Computed:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "Sorting key columns",
+						},
+					},
+				},
+			},
+			"views": schema.MapNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Views of the database, keyed by view name",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"query": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "SELECT query of the view",
+						},
+						"materialized": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the view is a materialized view",
+						},
+						"to": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Target table (`database.table`) of a materialized or window view",
+						},
+						"window": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the view is a window view",
+						},
+						"watermark": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Watermark strategy of a window view",
+						},
+						"allowed_lateness": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Delay during which a window view accepts late rows",
+						},
+						"refresh": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Refresh schedule of a refreshable materialized view",
+						},
+						"depends_on": schema.ListAttribute{
+							Computed:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "Refreshable materialized views refreshed before this one",
+						},
+						"append": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether a refresh appends its rows to the target table",
+						},
+						"populate": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Always false: the server does not report whether a view was populated on creation",
+						},
+						"sql_security": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whose privileges the query of the view runs with",
+						},
+						"definer": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "User whose privileges the query runs with",
+						},
+					},
+				},
+			},
+			"schema_json": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JSON encoded schema of the database",
+			},
+		},
+	}
+}
+
+func (d *DatabaseSchemaDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *DatabaseSchemaDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DatabaseSchemaDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database := data.Database.ValueString()
+	def, err := readDatabaseDefinition(ctx, d.client, database)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading database schema",
+			fmt.Sprintf("Could not read schema of database %s: %s", database, err.Error()),
+		)
+		return
+	}
+
+	encoded, err := json.Marshal(def)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error encoding database schema",
+			fmt.Sprintf("Could not encode schema of database %s: %s", database, err.Error()),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(database)
+	data.Tables = schemaTableModels(def)
+	data.Views = schemaViewModels(def)
+	data.SchemaJSON = types.StringValue(string(encoded))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// computedColumnAttributes returns the column attributes of the database_schema resource as computed
+// data source attributes, so the columns read here can be passed to the resource as they are
+func computedColumnAttributes() map[string]schema.Attribute {
+	attributes := map[string]schema.Attribute{}
+	for name, attribute := range columnAttributes() {
+		column := attribute.(resourceschema.StringAttribute)
+		attributes[name] = schema.StringAttribute{
+			Computed:            true,
+			CustomType:          column.CustomType,
+			MarkdownDescription: column.MarkdownDescription,
+		}
+	}
+	return attributes
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestDatabaseSchemaDataSourceState(t *testing.T) {
+	ctx := context.Background()
+	var schemaResp datasource.SchemaResponse
+	(&DatabaseSchemaDataSource{}).Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected schema diagnostics: %v", schemaResp.Diagnostics)
+	}
+
+	def := databaseDefinition{
+		Tables: map[string]tableDefinition{
+			"events": {Name: "events", Engine: "MergeTree", OrderBy: []string{"id"}, Columns: []ColumnInfo{
+				{Name: "id", Type: "UInt64", Comment: "Primary key"},
+				{Name: "ts", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()", Codec: "CODEC(Delta(4), ZSTD(3))"},
+				{Name: "message", Type: "String", TTL: "ts + toIntervalDay(30)"},
+				{Name: "day", Type: "Date", DefaultKind: "MATERIALIZED", DefaultExpression: "toDate(ts)"},
+			}},
+		},
+		Views: map[string]viewDefinition{
+			"events_mv": {Name: "events_mv", Query: "SELECT id FROM analytics.raw", Materialized: true, To: "analytics.events"},
+		},
+	}
+	data := DatabaseSchemaDataSourceModel{
+		ID:         types.StringValue("analytics"),
+		Database:   types.StringValue("analytics"),
+		Tables:     schemaTableModels(def),
+		Views:      schemaViewModels(def),
+		SchemaJSON: types.StringValue("{}"),
+	}
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	if diags := state.Set(ctx, &data); diags.HasError() {
+		t.Fatalf("could not set the data source state: %v", diags)
+	}
+
+	var read DatabaseSchemaDataSourceModel
+	if diags := state.Get(ctx, &read); diags.HasError() {
+		t.Fatalf("could not read the data source state: %v", diags)
+	}
+	columns := read.Tables["events"].Columns
+	if len(columns) != 4 || columns[1].Default.ValueString() != "now()" || columns[2].TTL.ValueString() != "ts + toIntervalDay(30)" ||
+		columns[3].Materialized.ValueString() != "toDate(ts)" {
+		t.Errorf("unexpected columns read back: %+v", columns)
+	}
+}
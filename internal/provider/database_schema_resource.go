@@ -0,0 +1,824 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &DatabaseSchemaResource{}
+var _ resource.ResourceWithImportState = &DatabaseSchemaResource{}
+var _ resource.ResourceWithValidateConfig = &DatabaseSchemaResource{}
+var _ resource.ResourceWithModifyPlan = &DatabaseSchemaResource{}
+
+func NewDatabaseSchemaResource() resource.Resource {
+	return &DatabaseSchemaResource{}
+}
+
+// DatabaseSchemaResource manages every table and view of a database as a single unit.
+type DatabaseSchemaResource struct {
+	client *clickhouseClient
+}
+
+// DatabaseSchemaResourceModel describes the resource data model.
+type DatabaseSchemaResourceModel struct {
+	ID       types.String                `tfsdk:"id"`
+	Database types.String                `tfsdk:"database"`
+	Tables   map[string]SchemaTableModel `tfsdk:"tables"`
+	Views    map[string]SchemaViewModel  `tfsdk:"views"`
+
+	ExecutionSettings types.Map    `tfsdk:"execution_settings"`
+	Cluster           types.String `tfsdk:"cluster"`
+}
+
+type SchemaTableModel struct {
+	Engine  types.String   `tfsdk:"engine"`
+	Columns []ColumnModel  `tfsdk:"columns"`
+	OrderBy []types.String `tfsdk:"order_by"`
+}
+
+type SchemaViewModel struct {
+	Query        types.String `tfsdk:"query"`
+	Materialized types.Bool   `tfsdk:"materialized"`
+	To           types.String `tfsdk:"to"`
+
+	Window          types.Bool   `tfsdk:"window"`
+	Watermark       types.String `tfsdk:"watermark"`
+	AllowedLateness types.String `tfsdk:"allowed_lateness"`
+
+	Refresh   types.String   `tfsdk:"refresh"`
+	DependsOn []types.String `tfsdk:"depends_on"`
+	Append    types.Bool     `tfsdk:"append"`
+
+	Populate types.Bool `tfsdk:"populate"`
+
+	SQLSecurity types.String `tfsdk:"sql_security"`
+	Definer     types.String `tfsdk:"definer"`
+}
+
+func (r *DatabaseSchemaResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_schema"
+}
+
+func (r *DatabaseSchemaResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages every table and view of a ClickHouse database as a single unit. " +
+			"Objects missing from the configuration are dropped, new objects are created and existing " +
+			"tables are altered in place when only their columns change. Engine or ORDER BY changes " +
+			"recreate the table. Plain views are replaced atomically with `CREATE OR REPLACE VIEW` and materialized " +
+			"view queries are changed in place with `ALTER TABLE ... MODIFY QUERY`. Materialized views are never " +
+			"recreated implicitly, as their rows would be lost: when the server rejects the change, the apply fails.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Database schema identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Name of the database whose objects are managed",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tables": schema.MapNestedAttribute{
+				MarkdownDescription: "Tables of the database, keyed by table name",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"engine": schema.StringAttribute{
+							MarkdownDescription: "Table engine with its parameters (e.g., `MergeTree`, `ReplacingMergeTree(ver)`, " +
+								"`ReplicatedMergeTree('/clickhouse/tables/{shard}/{database}/{table}', '{replica}')`)",
+							Required: true,
+						},
+						"columns": schema.ListNestedAttribute{
+							MarkdownDescription: "Table columns definition",
+							Required:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: columnAttributes(),
+							},
+						},
+						"order_by": schema.ListAttribute{
+							MarkdownDescription: "Sorting key expressions: column names or expressions such as `cityHash64(user_id)` " +
+								"(required for MergeTree family engines)",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+			"views": schema.MapNestedAttribute{
+				MarkdownDescription: "Views of the database, keyed by view name",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"query": schema.StringAttribute{
+							MarkdownDescription: "SELECT query of the view",
+							Required:            true,
+						},
+						"materialized": schema.BoolAttribute{
+							MarkdownDescription: "Whether the view is a materialized view",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+						"to": schema.StringAttribute{
+							MarkdownDescription: "Target table (`database.table`) of a materialized or window view. The plan checks " +
+								"that it accepts the columns returned by the query; when another resource manages it, refer to " +
+								"that resource so the table is created first",
+							Optional: true,
+						},
+						"window": schema.BoolAttribute{
+							MarkdownDescription: "Whether the view is a window view, aggregating its source by the `tumble` or `hop` " +
+								"time window its query groups by. Window views are experimental and need the " +
+								"`allow_experimental_window_view` setting, e.g. in `execution_settings`",
+							Optional: true,
+							Computed: true,
+							Default:  booldefault.StaticBool(false),
+						},
+						"watermark": schema.StringAttribute{
+							MarkdownDescription: "Watermark strategy of a window view (e.g. `ASCENDING`, `STRICTLY_ASCENDING`, " +
+								"`BOUNDED(INTERVAL '2' SECOND)`). Windows are closed on processing time when not set",
+							Optional: true,
+						},
+						"allowed_lateness": schema.StringAttribute{
+							MarkdownDescription: "Delay during which a window view still accepts late rows of closed windows " +
+								"(e.g. `INTERVAL '5' SECOND`). Requires `watermark`",
+							Optional: true,
+						},
+						"refresh": schema.StringAttribute{
+							MarkdownDescription: "Refresh schedule of a refreshable materialized view, which runs its query " +
+								"periodically instead of on insert (e.g. `EVERY 1 HOUR OFFSET 10 MINUTE`, " +
+								"`AFTER 30 MINUTE RANDOMIZE FOR 1 MINUTE`). Changed in place with `ALTER TABLE ... MODIFY REFRESH`",
+							Optional: true,
+						},
+						"depends_on": schema.ListAttribute{
+							MarkdownDescription: "Refreshable materialized views (`database.view`) that must be refreshed before " +
+								"this one. Requires `refresh`",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"append": schema.BoolAttribute{
+							MarkdownDescription: "Whether a refresh appends its rows to the target table instead of replacing " +
+								"its content. Requires `refresh`",
+							Optional: true,
+							Computed: true,
+							Default:  booldefault.StaticBool(false),
+						},
+						"populate": schema.BoolAttribute{
+							MarkdownDescription: "Whether a materialized view without `to`, or a window view, is filled with the " +
+								"rows already in its source when it is created. Rows inserted meanwhile may be missed. Only " +
+								"applied on creation, including when the view is recreated",
+							Optional: true,
+							Computed: true,
+							Default:  booldefault.StaticBool(false),
+						},
+						"sql_security": schema.StringAttribute{
+							MarkdownDescription: "Whose privileges the query of the view runs with: `DEFINER`, `INVOKER` (plain " +
+								"views only) or `NONE`. Changed in place with `ALTER TABLE ... MODIFY SQL SECURITY`; the server " +
+								"default applies when not set",
+							Optional: true,
+						},
+						"definer": schema.StringAttribute{
+							MarkdownDescription: "User whose privileges the query runs with (or `CURRENT_USER`). Requires " +
+								"`sql_security = \"DEFINER\"`",
+							Optional: true,
+						},
+					},
+				},
+			},
+			"execution_settings": schema.MapAttribute{
+				MarkdownDescription: "ClickHouse settings (e.g. `max_execution_time`, `alter_sync`) applied to every " +
+					"statement run against the database, overriding the provider defaults",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the tables and views are managed with `ON CLUSTER`, defaulting to the provider `cluster`. " +
+					"An empty string manages them on the connected server only",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *DatabaseSchemaResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var database types.String
+	var tables types.Map
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("database"), &database)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("tables"), &tables)...)
+	resp.Diagnostics.Append(validateObjectName(database, path.Root("database"))...)
+	if resp.Diagnostics.HasError() || tables.IsUnknown() {
+		return
+	}
+
+	for _, name := range sortedKeys(tables.Elements()) {
+		resp.Diagnostics.Append(validateObjectName(types.StringValue(name), path.Root("tables").AtMapKey(name))...)
+		table, ok := tables.Elements()[name].(types.Object)
+		if !ok || table.IsUnknown() {
+			continue
+		}
+		columns, ok := table.Attributes()["columns"].(types.List)
+		if !ok || columns.IsUnknown() || columns.IsNull() {
+			continue
+		}
+
+		var columnModels []ColumnModel
+		resp.Diagnostics.Append(columns.ElementsAs(ctx, &columnModels, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		resp.Diagnostics.Append(validateColumnModels(columnModels, path.Root("tables").AtMapKey(name).AtName("columns"))...)
+	}
+
+	var views types.Map
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("views"), &views)...)
+	if resp.Diagnostics.HasError() || views.IsUnknown() {
+		return
+	}
+
+	for _, name := range sortedKeys(views.Elements()) {
+		resp.Diagnostics.Append(validateObjectName(types.StringValue(name), path.Root("views").AtMapKey(name))...)
+		view, ok := views.Elements()[name].(types.Object)
+		if !ok || view.IsUnknown() {
+			continue
+		}
+		resp.Diagnostics.Append(validateView(view.Attributes(), path.Root("views").AtMapKey(name))...)
+	}
+}
+
+func (r *DatabaseSchemaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var database types.String
+	var tables, views types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("database"), &database)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("tables"), &tables)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("views"), &views)...)
+	if resp.Diagnostics.HasError() || database.IsUnknown() || tables.IsUnknown() || views.IsUnknown() || views.IsNull() {
+		return
+	}
+
+	// Plans with unknown nested values are checked once they are known
+	var tableModels map[string]SchemaTableModel
+	var viewModels map[string]SchemaViewModel
+	if tables.ElementsAs(ctx, &tableModels, false).HasError() || views.ElementsAs(ctx, &viewModels, false).HasError() {
+		return
+	}
+
+	var priorViews map[string]SchemaViewModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("views"), &priorViews)...)
+	}
+
+	for _, name := range sortedKeys(viewModels) {
+		view := viewModels[name]
+		if prior, ok := priorViews[name]; ok && !view.Populate.IsUnknown() && !view.Populate.Equal(prior.Populate) {
+			resp.Diagnostics.AddAttributeWarning(path.Root("views").AtMapKey(name).AtName("populate"), "populate is ignored on update",
+				"populate only applies when the view is created, so changing it does not backfill or clear the existing view.")
+		}
+		if view.To.IsNull() || view.To.IsUnknown() || view.Query.IsUnknown() {
+			continue
+		}
+		resp.Diagnostics.Append(r.checkViewTarget(ctx, database.ValueString(), tableModels, view,
+			path.Root("views").AtMapKey(name))...)
+	}
+}
+
+func (r *DatabaseSchemaResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *DatabaseSchemaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data DatabaseSchemaResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	database := data.Database.ValueString()
+	current, err := readDatabaseDefinition(ctx, r.client, database)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading database schema",
+			fmt.Sprintf("Could not read schema of database %s: %s", database, err.Error()),
+		)
+		return
+	}
+
+	// Refuse to take over a database holding objects that are not part of the
+	// configuration, as the first apply would otherwise drop them.
+	desired := data.definition()
+	desired.Cluster = r.cluster(data)
+	var unmanaged []string
+	for _, name := range sortedKeys(current.Tables) {
+		if _, ok := desired.Tables[name]; !ok {
+			unmanaged = append(unmanaged, name)
+		}
+	}
+	for _, name := range sortedKeys(current.Views) {
+		if _, ok := desired.Views[name]; !ok {
+			unmanaged = append(unmanaged, name)
+		}
+	}
+	if len(unmanaged) > 0 {
+		resp.Diagnostics.AddError(
+			"Unmanaged objects in database",
+			fmt.Sprintf("Database %s contains objects that are not part of the configuration: %s. "+
+				"Add them to the configuration or import the database schema instead.",
+				database, strings.Join(unmanaged, ", ")),
+		)
+		return
+	}
+
+	if err := r.applyChanges(ctx, database, diffDatabaseDefinitions(ctx, r.client, database, current, desired)); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error applying database schema",
+			fmt.Sprintf("Could not apply schema of database %s", database),
+			err,
+		))
+		return
+	}
+
+	data.ID = types.StringValue(database)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseSchemaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DatabaseSchemaResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database := data.ID.ValueString()
+	var exists uint64
+	err := r.client.QueryRowContext(ctx, "SELECT count() FROM system.databases WHERE name = ?", database).Scan(&exists)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error checking database existence",
+			fmt.Sprintf("Could not check if database %s exists: %s", database, err.Error()),
+		)
+		return
+	}
+	if exists == 0 {
+		tflog.Info(ctx, "Database no longer exists, removing from state", map[string]interface{}{
+			"id": database,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	current, err := readDatabaseDefinition(ctx, r.client, database)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading database schema",
+			fmt.Sprintf("Could not read schema of database %s: %s", database, err.Error()),
+		)
+		return
+	}
+
+	data = r.modelFromDefinition(ctx, data, current)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseSchemaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data DatabaseSchemaResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	database := data.Database.ValueString()
+	current, err := readDatabaseDefinition(ctx, r.client, database)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading database schema",
+			fmt.Sprintf("Could not read schema of database %s: %s", database, err.Error()),
+		)
+		return
+	}
+
+	desired := data.definition()
+	desired.Cluster = r.cluster(data)
+	if err := r.applyChanges(ctx, database, diffDatabaseDefinitions(ctx, r.client, database, current, desired)); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error applying database schema",
+			fmt.Sprintf("Could not apply schema of database %s", database),
+			err,
+		))
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseSchemaResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data DatabaseSchemaResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	// Drop every managed object by diffing against an empty schema
+	changes := diffDatabaseDefinitions(ctx, r.client, data.Database.ValueString(), data.definition(), databaseDefinition{Cluster: r.cluster(data)})
+	if err := r.applyChanges(ctx, data.Database.ValueString(), changes); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error dropping database schema",
+			fmt.Sprintf("Could not drop objects of database %s", data.Database.ValueString()),
+			err,
+		))
+		return
+	}
+}
+
+func (r *DatabaseSchemaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID == "" || strings.Contains(req.ID, ".") {
+		resp.Diagnostics.AddError(
+			"Invalid import identifier",
+			fmt.Sprintf("Expected a database name, got: %s", req.ID),
+		)
+		return
+	}
+
+	current, err := readDatabaseDefinition(ctx, r.client, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading database schema",
+			fmt.Sprintf("Could not read schema of database %s: %s", req.ID, err.Error()),
+		)
+		return
+	}
+
+	data := r.modelFromDefinition(ctx, DatabaseSchemaResourceModel{
+		ID:       types.StringValue(req.ID),
+		Database: types.StringValue(req.ID),
+
+		ExecutionSettings: types.MapNull(types.StringType),
+		Cluster:           types.StringNull(),
+	}, current)
+
+	tflog.Info(ctx, "Successfully imported ClickHouse database schema", map[string]interface{}{
+		"id":     req.ID,
+		"tables": len(data.Tables),
+		"views":  len(data.Views),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// checkViewTarget checks that the target table of a materialized or window view exists and accepts the
+// columns returned by its query. Targets managed by this resource are checked against the planned tables.
+func (r *DatabaseSchemaResource) checkViewTarget(ctx context.Context, database string, tables map[string]SchemaTableModel,
+	view SchemaViewModel, viewPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	targetDatabase, targetTable := database, strings.ReplaceAll(view.To.ValueString(), "`", "")
+	if i := strings.LastIndex(targetTable, "."); i >= 0 {
+		targetDatabase, targetTable = targetTable[:i], targetTable[i+1:]
+	}
+
+	targetColumns := map[string]string{}
+	if table, ok := tables[targetTable]; ok && targetDatabase == database {
+		for _, column := range table.Columns {
+			if !column.Type.IsUnknown() {
+				targetColumns[column.Name.ValueString()] = column.Type.ValueString()
+			}
+		}
+	} else {
+		columns, err := readColumnTypes(ctx, r.client, targetDatabase, targetTable)
+		if err != nil {
+			diags.AddWarning("Could not check the target table",
+				fmt.Sprintf("Could not read the columns of %s.%s: %s", targetDatabase, targetTable, err.Error()))
+			return diags
+		}
+		if len(columns) == 0 {
+			diags.AddAttributeWarning(viewPath.AtName("to"), "Target table not found",
+				fmt.Sprintf("%s.%s does not exist on the server. When another resource creates it, refer to that "+
+					"resource in to so it is created first.", targetDatabase, targetTable))
+			return diags
+		}
+		targetColumns = columns
+	}
+
+	// The query can only be described once the tables it reads exist
+	output, err := queryOutputColumns(ctx, r.client, view.Query.ValueString())
+	if err != nil {
+		tflog.Debug(ctx, "Could not describe the query of the view", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return diags
+	}
+
+	for _, column := range output {
+		targetType, ok := targetColumns[column.Name]
+		switch {
+		case !ok:
+			diags.AddAttributeError(viewPath.AtName("query"), "Incompatible target table",
+				fmt.Sprintf("The query returns column %s, which %s.%s does not have; inserts into the view would fail.",
+					column.Name, targetDatabase, targetTable))
+		case !typesEquivalent(targetType, column.Type):
+			diags.AddAttributeWarning(viewPath.AtName("query"), "Column type conversion",
+				fmt.Sprintf("The query returns column %s as %s, which is converted to %s on insert into %s.%s.",
+					column.Name, column.Type, targetType, targetDatabase, targetTable))
+		}
+	}
+
+	return diags
+}
+
+// applyChanges executes the statements of every change in order, gating ALTERs
+// on the replica health policy
+func (r *DatabaseSchemaResource) applyChanges(ctx context.Context, database string, changes []schemaChange) error {
+	for _, change := range changes {
+		if change.Kind == schemaKindTable && change.Action == schemaActionAlter {
+			if err := r.client.waitForReplicaHealth(ctx, database, change.Object); err != nil {
+				return err
+			}
+		}
+
+		if err := r.applyStatements(ctx, change, change.Statements); err != nil {
+			if change.Hint != "" {
+				return fmt.Errorf("%w. %s", err, change.Hint)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// applyStatements executes the statements of a change in order
+func (r *DatabaseSchemaResource) applyStatements(ctx context.Context, change schemaChange, statements []string) error {
+	for _, statement := range statements {
+		tflog.Info(ctx, "Applying ClickHouse schema change", map[string]interface{}{
+			"object": change.Object,
+			"action": change.Action,
+			"sql":    statement,
+		})
+
+		if _, err := r.client.execOnShards(ctx, statement, nil); err != nil {
+			return fmt.Errorf("%s %s %s: %w", change.Action, change.Kind, change.Object, withStatement(statement, err))
+		}
+	}
+	return nil
+}
+
+// cluster returns the cluster the schema statements run on. Schemas applied shard by shard do not use ON CLUSTER.
+func (r *DatabaseSchemaResource) cluster(m DatabaseSchemaResourceModel) string {
+	if m.Cluster.IsNull() && r.client.shardMode() {
+		return ""
+	}
+	return r.client.defaultCluster(m.Cluster)
+}
+
+// modelFromDefinition converts the schema read from ClickHouse into the resource model,
+// keeping the prior values where the server only reports a different spelling.
+func (r *DatabaseSchemaResource) modelFromDefinition(ctx context.Context, prior DatabaseSchemaResourceModel, def databaseDefinition) DatabaseSchemaResourceModel {
+	data := DatabaseSchemaResourceModel{
+		ID:       prior.ID,
+		Database: prior.Database,
+
+		ExecutionSettings: prior.ExecutionSettings,
+		Cluster:           prior.Cluster,
+	}
+
+	if len(def.Tables) > 0 || prior.Tables != nil {
+		data.Tables = schemaTableModels(def)
+	}
+	for name, model := range data.Tables {
+		// The server reports engine parameters in its own spelling, with the macros of Keeper paths substituted
+		if previous := prior.Tables[name].Engine; !previous.IsNull() &&
+			enginesEquivalent(ctx, r.client, data.Database.ValueString(), name, previous.ValueString(), model.Engine.ValueString()) {
+			model.Engine = previous
+		}
+		if len(model.OrderBy) == 0 && prior.Tables[name].OrderBy == nil {
+			model.OrderBy = nil
+		}
+		// The sorting key is reported one expression at a time and formatted by the server
+		if previous := prior.Tables[name].OrderBy; len(previous) > 0 && sortingKeysEquivalent(sortingKeyExpressions(previous), sortingKeyExpressions(model.OrderBy)) {
+			model.OrderBy = previous
+		}
+		// ClickHouse rewrites column expressions; keep the configured spelling when equivalent
+		for i, column := range model.Columns {
+			for _, previous := range prior.Tables[name].Columns {
+				if previous.Name.ValueString() == column.Name.ValueString() {
+					model.Columns[i] = column.withSpelling(ctx, r.client, previous)
+				}
+			}
+		}
+		data.Tables[name] = model
+	}
+
+	if len(def.Views) > 0 || prior.Views != nil {
+		data.Views = schemaViewModels(def)
+	}
+	for name, model := range data.Views {
+		previous, ok := prior.Views[name]
+		if !ok {
+			continue
+		}
+		// ClickHouse reformats view queries; keep the configured spelling when equivalent
+		if queriesEquivalent(ctx, r.client, previous.Query.ValueString(), model.Query.ValueString()) {
+			model.Query = previous.Query
+		}
+		if expressionsEquivalent(ctx, r.client, previous.Watermark.ValueString(), model.Watermark.ValueString()) {
+			model.Watermark = previous.Watermark
+		}
+		if expressionsEquivalent(ctx, r.client, previous.AllowedLateness.ValueString(), model.AllowedLateness.ValueString()) {
+			model.AllowedLateness = previous.AllowedLateness
+		}
+		if strings.EqualFold(normalizeQuery(previous.Refresh.ValueString()), normalizeQuery(model.Refresh.ValueString())) {
+			model.Refresh = previous.Refresh
+		}
+		if !previous.To.IsNull() && !model.To.IsNull() &&
+			qualifiedReference(data.Database.ValueString(), previous.To.ValueString()) == qualifiedReference(data.Database.ValueString(), model.To.ValueString()) {
+			model.To = previous.To
+		}
+		if model.DependsOn == nil && previous.DependsOn != nil {
+			model.DependsOn = []types.String{}
+		}
+		// Dependencies are reported qualified with their database
+		if len(previous.DependsOn) > 0 && equalStrings(
+			qualifiedReferences(data.Database.ValueString(), stringValuesOf(previous.DependsOn)),
+			qualifiedReferences(data.Database.ValueString(), stringValuesOf(model.DependsOn))) {
+			model.DependsOn = previous.DependsOn
+		}
+		// Security left to the server defaults is not reflected
+		if previous.SQLSecurity.IsNull() {
+			model.SQLSecurity, model.Definer = previous.SQLSecurity, previous.Definer
+		} else if strings.EqualFold(previous.SQLSecurity.ValueString(), model.SQLSecurity.ValueString()) {
+			model.SQLSecurity = previous.SQLSecurity
+		}
+		if strings.Trim(previous.Definer.ValueString(), "`") == strings.Trim(model.Definer.ValueString(), "`") {
+			model.Definer = previous.Definer
+		}
+		// The server does not keep whether the view was populated
+		if !previous.Populate.IsNull() {
+			model.Populate = previous.Populate
+		}
+		data.Views[name] = model
+	}
+
+	return data
+}
+
+// schemaTableModels converts the tables of a database definition into their Terraform models
+func schemaTableModels(def databaseDefinition) map[string]SchemaTableModel {
+	tables := make(map[string]SchemaTableModel, len(def.Tables))
+	for name, table := range def.Tables {
+		model := SchemaTableModel{
+			Engine:  types.StringValue(table.Engine),
+			OrderBy: make([]types.String, len(table.OrderBy)),
+		}
+
+		for _, col := range table.Columns {
+			model.Columns = append(model.Columns, columnModel(col))
+		}
+
+		for i, col := range table.OrderBy {
+			model.OrderBy[i] = types.StringValue(col)
+		}
+
+		tables[name] = model
+	}
+	return tables
+}
+
+// schemaViewModels converts the views of a database definition into their Terraform models
+func schemaViewModels(def databaseDefinition) map[string]SchemaViewModel {
+	views := make(map[string]SchemaViewModel, len(def.Views))
+	for name, view := range def.Views {
+		model := SchemaViewModel{
+			Query:        types.StringValue(view.Query),
+			Materialized: types.BoolValue(view.Materialized),
+			To:           optionalString(view.To),
+
+			Window:          types.BoolValue(view.Window),
+			Watermark:       optionalString(view.Watermark),
+			AllowedLateness: optionalString(view.AllowedLateness),
+
+			Refresh: optionalString(view.Refresh),
+			Append:  types.BoolValue(view.Append),
+
+			Populate: types.BoolValue(false),
+
+			SQLSecurity: optionalString(view.SQLSecurity),
+			Definer:     optionalString(view.Definer),
+		}
+		for _, dependency := range view.DependsOn {
+			model.DependsOn = append(model.DependsOn, types.StringValue(dependency))
+		}
+		views[name] = model
+	}
+	return views
+}
+
+// definition converts the resource model into a database definition
+func (m DatabaseSchemaResourceModel) definition() databaseDefinition {
+	def := databaseDefinition{
+		Tables: make(map[string]tableDefinition, len(m.Tables)),
+		Views:  make(map[string]viewDefinition, len(m.Views)),
+	}
+
+	for name, table := range m.Tables {
+		t := tableDefinition{
+			Name:   name,
+			Engine: table.Engine.ValueString(),
+		}
+		for _, col := range table.Columns {
+			t.Columns = append(t.Columns, col.info())
+		}
+		t.OrderBy = sortingKeyExpressions(table.OrderBy)
+		def.Tables[name] = t
+	}
+
+	for name, view := range m.Views {
+		v := viewDefinition{
+			Name:         name,
+			Query:        view.Query.ValueString(),
+			Materialized: view.Materialized.ValueBool(),
+			To:           view.To.ValueString(),
+
+			Window:          view.Window.ValueBool(),
+			Watermark:       view.Watermark.ValueString(),
+			AllowedLateness: view.AllowedLateness.ValueString(),
+
+			Refresh: view.Refresh.ValueString(),
+			Append:  view.Append.ValueBool(),
+
+			Populate: view.Populate.ValueBool(),
+
+			SQLSecurity: strings.ToUpper(view.SQLSecurity.ValueString()),
+			Definer:     view.Definer.ValueString(),
+		}
+		for _, dependency := range view.DependsOn {
+			v.DependsOn = append(v.DependsOn, dependency.ValueString())
+		}
+		def.Views[name] = v
+	}
+
+	return def
+}
+
+// stringValuesOf returns the values of a list of strings
+func stringValuesOf(values []types.String) []string {
+	strs := make([]string, len(values))
+	for i, value := range values {
+		strs[i] = value.ValueString()
+	}
+	return strs
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// statementError attaches the SQL statement that failed to a driver error.
+type statementError struct {
+	Statement string
+	Err       error
+}
+
+func (e *statementError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *statementError) Unwrap() error {
+	return e.Err
+}
+
+// withStatement records the statement that produced err, if any
+func withStatement(statement string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &statementError{Statement: statement, Err: err}
+}
+
+// clickhouseErrorHints maps ClickHouse error codes to remediation text.
+var clickhouseErrorHints = map[int32]string{
+	44:  "The statement uses a column definition the engine does not accept; check the column types and expressions.",
+	57:  "The table already exists. Import it into the state or remove it before creating it again.",
+	60:  "The table does not exist. It may have been dropped outside of Terraform; refresh the state.",
+	62:  "The generated statement is not valid SQL for this server version; check expressions and identifiers in the configuration.",
+	81:  "The database does not exist. Create it first or fix the database name.",
+	159: "The statement exceeded max_execution_time. Increase the timeout for long running DDL.",
+	164: "The user is in readonly mode. Use a user whose profile allows DDL (readonly = 0).",
+	225: "Keeper/ZooKeeper is not configured on this server; Replicated engines and ON CLUSTER DDL require it.",
+	242: "The table is read-only, usually because its replica lost the Keeper session. Check system.replicas and Keeper connectivity.",
+	243: "The server ran out of disk space. Free space or move partitions to another disk before retrying.",
+	497: "The user lacks the privilege required by this statement. Grant it, e.g. GRANT CREATE TABLE, ALTER ON db.* TO user.",
+	516: "Authentication failed. Check the provider username and password.",
+	999: "A Keeper/ZooKeeper operation failed. Check the Keeper cluster health and retry.",
+}
+
+// clickhouseErrorDiagnostic turns an error returned by ClickHouse into a diagnostic
+// naming the ClickHouse error, a remediation hint and the offending SQL.
+func clickhouseErrorDiagnostic(summary, detail string, err error) diag.Diagnostic {
+	message := fmt.Sprintf("%s: %s", detail, err.Error())
+
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		message = fmt.Sprintf("%s: %s (code %d): %s", detail, exception.Name, exception.Code, exception.Message)
+		if hint, ok := clickhouseErrorHints[exception.Code]; ok {
+			message += "\n\n" + hint
+		}
+	} else if isConnectionError(err) {
+		message += "\n\nThe connection to ClickHouse was lost. Check that the server is reachable and retry."
+	}
+
+	var stmtErr *statementError
+	if errors.As(err, &stmtErr) {
+		message += "\n\nSQL:\n" + stmtErr.Statement
+	}
+
+	return diag.NewErrorDiagnostic(summary, message)
+}
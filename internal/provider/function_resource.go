@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &FunctionResource{}
+var _ resource.ResourceWithImportState = &FunctionResource{}
+
+func NewFunctionResource() resource.Resource {
+	return &FunctionResource{}
+}
+
+// FunctionResource manages a SQL user defined function.
+type FunctionResource struct {
+	client *clickhouseClient
+}
+
+// FunctionResourceModel describes the resource data model.
+type FunctionResourceModel struct {
+	ID         types.String   `tfsdk:"id"`
+	Name       types.String   `tfsdk:"name"`
+	Cluster    types.String   `tfsdk:"cluster"`
+	Arguments  []types.String `tfsdk:"arguments"`
+	Expression types.String   `tfsdk:"expression"`
+}
+
+var functionDefinitionPattern = regexp.MustCompile(`(?s)\sAS\s+\((.*?)\)\s*->\s*(.+)$`)
+
+func (r *FunctionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_function"
+}
+
+func (r *FunctionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a SQL user defined function created with `CREATE FUNCTION name AS (args) -> expression`. " +
+			"Changes to the arguments or the expression are applied in place with `CREATE OR REPLACE FUNCTION`.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Function identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Function name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the function is created with `ON CLUSTER`, defaulting to the provider `cluster`. " +
+					"An empty string creates it on the connected server only",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"arguments": schema.ListAttribute{
+				MarkdownDescription: "Argument names",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"expression": schema.StringAttribute{
+				MarkdownDescription: "Expression computing the result from the arguments (e.g. `if(x > 0, x, 0)`)",
+				Required:            true,
+			},
+		},
+	}
+}
+
+func (r *FunctionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *FunctionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FunctionResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createSQL := functionCreateStatement(data, onCluster(r.client.defaultCluster(data.Cluster)), false)
+
+	tflog.Info(ctx, "Creating ClickHouse function", map[string]interface{}{
+		"sql": createSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, createSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error creating function",
+			fmt.Sprintf("Could not create function %s", data.Name.ValueString()),
+			withStatement(createSQL, err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FunctionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FunctionResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createQuery, err := r.readCreateQuery(ctx, data.ID.ValueString())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			tflog.Info(ctx, "Function no longer exists, removing from state", map[string]interface{}{
+				"id": data.ID.ValueString(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading function",
+			fmt.Sprintf("Could not read function %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// ClickHouse reformats the definition and does not keep ON CLUSTER; only report a change when it differs
+	definition := data
+	definition.Cluster = types.StringNull()
+	if !queriesEquivalent(ctx, r.client, createQuery, functionCreateStatement(definition, "", false)) {
+		arguments, expression, ok := parseFunctionDefinition(createQuery)
+		if !ok {
+			resp.Diagnostics.AddError(
+				"Error reading function",
+				fmt.Sprintf("Could not parse the definition of function %s: %s", data.ID.ValueString(), createQuery),
+			)
+			return
+		}
+		data.Arguments = arguments
+		data.Expression = types.StringValue(expression)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FunctionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data FunctionResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	replaceSQL := functionCreateStatement(data, onCluster(r.client.defaultCluster(data.Cluster)), true)
+
+	tflog.Info(ctx, "Replacing ClickHouse function", map[string]interface{}{
+		"sql": replaceSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, replaceSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error updating function",
+			fmt.Sprintf("Could not replace function %s", data.Name.ValueString()),
+			withStatement(replaceSQL, err),
+		))
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FunctionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FunctionResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dropSQL := fmt.Sprintf("DROP FUNCTION IF EXISTS %s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+
+	tflog.Info(ctx, "Dropping ClickHouse function", map[string]interface{}{
+		"sql": dropSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, dropSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error dropping function",
+			fmt.Sprintf("Could not drop function %s", data.Name.ValueString()),
+			withStatement(dropSQL, err),
+		))
+	}
+}
+
+func (r *FunctionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	createQuery, err := r.readCreateQuery(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error importing function",
+			fmt.Sprintf("Could not read function %s from system.functions: %s", req.ID, err.Error()),
+		)
+		return
+	}
+
+	arguments, expression, ok := parseFunctionDefinition(createQuery)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Error importing function",
+			fmt.Sprintf("Could not parse the definition of function %s: %s", req.ID, createQuery),
+		)
+		return
+	}
+
+	data := FunctionResourceModel{
+		ID:         types.StringValue(req.ID),
+		Name:       types.StringValue(req.ID),
+		Cluster:    types.StringNull(),
+		Arguments:  arguments,
+		Expression: types.StringValue(expression),
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readCreateQuery reads the definition of a SQL user defined function
+func (r *FunctionResource) readCreateQuery(ctx context.Context, name string) (string, error) {
+	var createQuery string
+	err := r.client.QueryRowContext(ctx, "SELECT create_query FROM system.functions WHERE name = ? AND origin = 'SQLUserDefined'",
+		name).Scan(&createQuery)
+	return createQuery, err
+}
+
+// functionCreateStatement builds the CREATE FUNCTION statement of a function, run with the given ON CLUSTER clause
+func functionCreateStatement(data FunctionResourceModel, clusterClause string, replace bool) string {
+	create := "CREATE FUNCTION"
+	if replace {
+		create = "CREATE OR REPLACE FUNCTION"
+	}
+	return fmt.Sprintf("%s %s%s AS (%s) -> %s", create, quoteIdentifier(data.Name.ValueString()), clusterClause,
+		joinValues(data.Arguments), data.Expression.ValueString())
+}
+
+// parseFunctionDefinition extracts the arguments and expression of a CREATE FUNCTION statement
+func parseFunctionDefinition(createQuery string) ([]types.String, string, bool) {
+	match := functionDefinitionPattern.FindStringSubmatch(createQuery)
+	if match == nil {
+		return nil, "", false
+	}
+
+	arguments := []types.String{}
+	for _, argument := range strings.Split(match[1], ",") {
+		if argument = strings.TrimSpace(argument); argument != "" {
+			arguments = append(arguments, types.StringValue(argument))
+		}
+	}
+	return arguments, strings.TrimSpace(match[2]), true
+}
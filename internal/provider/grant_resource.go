@@ -0,0 +1,676 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &GrantResource{}
+var _ resource.ResourceWithImportState = &GrantResource{}
+var _ resource.ResourceWithValidateConfig = &GrantResource{}
+
+func NewGrantResource() resource.Resource {
+	return &GrantResource{}
+}
+
+// GrantResource manages the privileges of a user or role on a database, table or columns.
+type GrantResource struct {
+	client *clickhouseClient
+}
+
+// GrantResourceModel describes the resource data model.
+type GrantResourceModel struct {
+	ID              types.String   `tfsdk:"id"`
+	Grantee         types.String   `tfsdk:"grantee"`
+	Database        types.String   `tfsdk:"database"`
+	Table           types.String   `tfsdk:"table"`
+	Columns         []types.String `tfsdk:"columns"`
+	Privileges      []types.String `tfsdk:"privileges"`
+	WithGrantOption types.Bool     `tfsdk:"with_grant_option"`
+	Cluster         types.String   `tfsdk:"cluster"`
+}
+
+func (r *GrantResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_grant"
+}
+
+func (r *GrantResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Grants privileges (`SELECT`, `INSERT`, `ALTER`, ...) on a database, a table or some of its " +
+			"columns to a user or a role. Privileges granted or revoked outside of Terraform on the same target are " +
+			"detected through `system.grants`. Configured privileges also held through a wider wildcard grant " +
+			"(`*.*` or `db.*`) are not reported as missing, and grants on single tables do not change a `db.*` grant. " +
+			"Such privileges are not revoked when they are removed or the resource is destroyed, as this would take " +
+			"them away from the wider grant.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Grant identifier (`grantee/database.table`)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"grantee": schema.StringAttribute{
+				MarkdownDescription: "User or role receiving the privileges",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Database the privileges apply to. Every database when unset.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"table": schema.StringAttribute{
+				MarkdownDescription: "Table the privileges apply to. Every table of the database when unset.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"columns": schema.ListAttribute{
+				MarkdownDescription: "Columns the privileges apply to. Every column of the table when unset.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"privileges": schema.ListAttribute{
+				MarkdownDescription: "Privileges to grant (e.g. `SELECT`, `INSERT`, `ALTER UPDATE`)",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"with_grant_option": schema.BoolAttribute{
+				MarkdownDescription: "Allow the grantee to grant these privileges to others",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the privileges are granted with `ON CLUSTER`, defaulting to the provider `cluster`. " +
+					"An empty string grants them on the connected server only",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *GrantResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var database, table types.String
+	var columns types.List
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("database"), &database)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("table"), &table)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("columns"), &columns)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if database.IsNull() && !table.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("table"),
+			"Missing database",
+			"A table can only be set together with its database.",
+		)
+	}
+	if table.IsNull() && !columns.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("columns"),
+			"Missing table",
+			"Columns can only be set together with their table.",
+		)
+	}
+}
+
+func (r *GrantResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *GrantResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data GrantResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	grantSQL := grantStatement(data, data.Privileges, r.client.defaultCluster(data.Cluster))
+	if err := r.exec(ctx, grantSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error granting privileges",
+			fmt.Sprintf("Could not grant privileges to %s", data.Grantee.ValueString()),
+			err,
+		))
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s/%s", data.Grantee.ValueString(), grantTarget(data)))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *GrantResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data GrantResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	privileges, grantOption, err := r.readPrivileges(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading grants",
+			fmt.Sprintf("Could not read privileges of %s from system.grants: %s", data.Grantee.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	if len(privileges) == 0 {
+		tflog.Info(ctx, "Privileges no longer granted, removing from state", map[string]interface{}{
+			"id": data.ID.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Keep the configured spelling and order when the same privileges are granted
+	if !equalStrings(normalizedPrivileges(data.Privileges), privileges) {
+		data.Privileges = stringValues(privileges, nil)
+	}
+	data.WithGrantOption = types.BoolValue(grantOption)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *GrantResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state GrantResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Revoke the privileges removed from the configuration
+	desired := map[string]bool{}
+	for _, privilege := range normalizedPrivileges(data.Privileges) {
+		desired[privilege] = true
+	}
+	var removed []types.String
+	for _, privilege := range normalizedPrivileges(state.Privileges) {
+		if !desired[privilege] {
+			removed = append(removed, types.StringValue(privilege))
+		}
+	}
+
+	cluster := r.client.defaultCluster(data.Cluster)
+	statements := []string{}
+	if len(removed) > 0 {
+		revokes, kept, err := r.revokeStatements(ctx, state, removed, cluster)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading grants",
+				fmt.Sprintf("Could not read privileges of %s from system.grants: %s", data.Grantee.ValueString(), err.Error()),
+			)
+			return
+		}
+		resp.Diagnostics.Append(keptPrivilegesWarning(state, kept)...)
+		statements = append(statements, revokes...)
+	}
+	statements = append(statements, grantStatement(data, data.Privileges, cluster))
+	if state.WithGrantOption.ValueBool() && !data.WithGrantOption.ValueBool() {
+		statements = append(statements, revokeStatement(data, data.Privileges, true, cluster))
+	}
+
+	for _, statement := range statements {
+		if err := r.exec(ctx, statement); err != nil {
+			resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+				"Error updating grant",
+				fmt.Sprintf("Could not update privileges of %s", data.Grantee.ValueString()),
+				err,
+			))
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *GrantResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data GrantResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	statements, kept, err := r.revokeStatements(ctx, data, data.Privileges, r.client.defaultCluster(data.Cluster))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading grants",
+			fmt.Sprintf("Could not read privileges of %s from system.grants: %s", data.Grantee.ValueString(), err.Error()),
+		)
+		return
+	}
+	resp.Diagnostics.Append(keptPrivilegesWarning(data, kept)...)
+
+	for _, statement := range statements {
+		if err := r.exec(ctx, statement); err != nil {
+			resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+				"Error revoking privileges",
+				fmt.Sprintf("Could not revoke privileges from %s", data.Grantee.ValueString()),
+				err,
+			))
+			return
+		}
+	}
+}
+
+func (r *GrantResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Expected format: grantee/database.table, with * for every database or table
+	grantee, target, ok := strings.Cut(req.ID, "/")
+	database, table, okTarget := strings.Cut(target, ".")
+	if !ok || !okTarget || grantee == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import identifier",
+			fmt.Sprintf("Expected format 'grantee/database.table', got: %s", req.ID),
+		)
+		return
+	}
+
+	data := GrantResourceModel{
+		ID:       types.StringValue(req.ID),
+		Grantee:  types.StringValue(grantee),
+		Database: types.StringNull(),
+		Table:    types.StringNull(),
+		Cluster:  types.StringNull(),
+	}
+	if database != "*" {
+		data.Database = types.StringValue(database)
+	}
+	if table != "*" {
+		data.Table = types.StringValue(table)
+	}
+
+	privileges, grantOption, err := r.readPrivileges(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading grants",
+			fmt.Sprintf("Could not read privileges of %s from system.grants: %s", grantee, err.Error()),
+		)
+		return
+	}
+	if len(privileges) == 0 {
+		resp.Diagnostics.AddError(
+			"Grant not found",
+			fmt.Sprintf("%s holds no privileges on %s", grantee, target),
+		)
+		return
+	}
+
+	data.Privileges = stringValues(privileges, nil)
+	data.WithGrantOption = types.BoolValue(grantOption)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// exec runs a GRANT or REVOKE statement
+func (r *GrantResource) exec(ctx context.Context, statement string) error {
+	tflog.Info(ctx, "Applying ClickHouse grant", map[string]interface{}{
+		"sql": statement,
+	})
+
+	if _, err := r.client.ExecContext(ctx, statement); err != nil {
+		return withStatement(statement, err)
+	}
+	return nil
+}
+
+// readPrivileges returns the privileges the grantee holds on the target of the grant,
+// and whether all of them carry the grant option
+func (r *GrantResource) readPrivileges(ctx context.Context, data GrantResourceModel) ([]string, bool, error) {
+	grants, err := readGrants(ctx, r.client, data.Grantee.ValueString())
+	if err != nil {
+		return nil, false, err
+	}
+
+	privileges, grantOption := reconcilePrivileges(data, grants)
+	return privileges, grantOption, nil
+}
+
+// revokeStatements returns the statements revoking privileges from the target of the grant, and the
+// privileges left in place because a grant on a wider target also holds them
+func (r *GrantResource) revokeStatements(ctx context.Context, data GrantResourceModel, privileges []types.String, cluster string) ([]string, []string, error) {
+	grants, err := readGrants(ctx, r.client, data.Grantee.ValueString())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statements, kept := revokeCoveredStatements(data, privileges, grants, cluster)
+	return statements, kept, nil
+}
+
+// readGrants returns the grants and partial revokes of a user or role reported by system.grants
+func readGrants(ctx context.Context, client *clickhouseClient, grantee string) ([]grantRow, error) {
+	query := `
+        SELECT access_type, database, table, column, grant_option, is_partial_revoke
+        FROM system.grants
+        WHERE user_name = ? OR role_name = ?
+        ORDER BY access_type, database, table, column
+    `
+
+	rows, err := client.QueryContext(ctx, query, grantee, grantee)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []grantRow
+	for rows.Next() {
+		var grant grantRow
+		var option, partialRevoke uint8
+		if err := rows.Scan(&grant.AccessType, &grant.Database, &grant.Table, &grant.Column, &option, &partialRevoke); err != nil {
+			return nil, err
+		}
+		grant.GrantOption, grant.PartialRevoke = option != 0, partialRevoke != 0
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+// grantRow is a grant or partial revoke reported by system.grants
+type grantRow struct {
+	AccessType              string
+	Database, Table, Column sql.NullString
+	GrantOption             bool
+	PartialRevoke           bool
+}
+
+// grantObjectType is the element type of the grants attribute of users and roles
+var grantObjectType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"access_type":    types.StringType,
+	"database":       types.StringType,
+	"table":          types.StringType,
+	"column":         types.StringType,
+	"grant_option":   types.BoolType,
+	"partial_revoke": types.BoolType,
+}}
+
+// grantList converts the rows of system.grants into the grants attribute of a user or role
+func grantList(grants []grantRow) types.List {
+	values := make([]attr.Value, 0, len(grants))
+	for _, grant := range grants {
+		values = append(values, types.ObjectValueMust(grantObjectType.AttrTypes, map[string]attr.Value{
+			"access_type":    types.StringValue(grant.AccessType),
+			"database":       nullableString(grant.Database),
+			"table":          nullableString(grant.Table),
+			"column":         nullableString(grant.Column),
+			"grant_option":   types.BoolValue(grant.GrantOption),
+			"partial_revoke": types.BoolValue(grant.PartialRevoke),
+		}))
+	}
+	return types.ListValueMust(grantObjectType, values)
+}
+
+// reconcilePrivileges returns the privileges held on the exact target of the grant, and whether all of them
+// carry the grant option. Column privileges only count when they are granted on every configured column.
+// Grants on a wider wildcard target (*.* or db.*) also hold the configured privileges on a narrower one, as
+// the server does not report the narrower grant separately, unless a partial revoke excludes the target.
+// Grants and revokes on narrower targets do not change the privileges held on a wildcard target.
+func reconcilePrivileges(data GrantResourceModel, grants []grantRow) ([]string, bool) {
+	columns := []string{""}
+	if len(data.Columns) > 0 {
+		columns = make([]string, len(data.Columns))
+		for i, column := range data.Columns {
+			columns[i] = column.ValueString()
+		}
+	}
+	configured := map[string]bool{}
+	for _, privilege := range normalizedPrivileges(data.Privileges) {
+		configured[privilege] = true
+	}
+
+	// granted holds the grant option of each privilege on each configured column
+	granted := map[string]map[string]bool{}
+	grant := func(accessType, column string, option bool) {
+		if granted[accessType] == nil {
+			granted[accessType] = map[string]bool{}
+		}
+		granted[accessType][column] = granted[accessType][column] || option
+	}
+
+	wider, revoked := map[string]bool{}, map[string]bool{}
+	for _, row := range grants {
+		exact := sameGrantLevel(row.Database, data.Database) && sameGrantLevel(row.Table, data.Table)
+		switch {
+		case row.PartialRevoke:
+			if (exact || coversGrantTarget(row, data)) && (!row.Column.Valid || len(data.Columns) == 0 || slices.Contains(columns, row.Column.String)) {
+				revoked[row.AccessType] = true
+			}
+		case exact:
+			if row.Column.Valid == (len(data.Columns) > 0) && slices.Contains(columns, row.Column.String) {
+				grant(row.AccessType, row.Column.String, row.GrantOption)
+			}
+		case configured[row.AccessType] && !row.Column.Valid && coversGrantTarget(row, data):
+			wider[row.AccessType] = wider[row.AccessType] || row.GrantOption
+		}
+	}
+	for accessType, option := range wider {
+		if revoked[accessType] {
+			continue
+		}
+		for _, column := range columns {
+			grant(accessType, column, option)
+		}
+	}
+
+	var privileges []string
+	grantOption := true
+	for accessType, grantedColumns := range granted {
+		if len(grantedColumns) < len(columns) {
+			continue
+		}
+		privileges = append(privileges, accessType)
+		for _, option := range grantedColumns {
+			grantOption = grantOption && option
+		}
+	}
+	sort.Strings(privileges)
+
+	return privileges, grantOption && len(privileges) > 0
+}
+
+// revokeCoveredStatements builds the statements revoking privileges from the target of the grant. Privileges
+// also held through a grant on a wider target are left out, as revoking them would add a partial revoke taking
+// them away from the wider grant, and are returned so the caller can report them. Only their grant option is
+// revoked when the wider grant does not carry it.
+func revokeCoveredStatements(data GrantResourceModel, privileges []types.String, grants []grantRow, cluster string) ([]string, []string) {
+	wider, revoked := map[string]bool{}, map[string]bool{}
+	for _, row := range grants {
+		switch {
+		case row.PartialRevoke:
+			if coversGrantTarget(row, data) || sameGrantLevel(row.Database, data.Database) && sameGrantLevel(row.Table, data.Table) {
+				revoked[row.AccessType] = true
+			}
+		case !row.Column.Valid && coversGrantTarget(row, data):
+			wider[row.AccessType] = wider[row.AccessType] || row.GrantOption
+		}
+	}
+
+	var revokes, grantOptions []types.String
+	var kept []string
+	for _, privilege := range privileges {
+		accessType := normalizedPrivileges([]types.String{privilege})[0]
+		option, covered := wider[accessType]
+		switch {
+		case !covered || revoked[accessType]:
+			revokes = append(revokes, privilege)
+			continue
+		case data.WithGrantOption.ValueBool() && !option:
+			grantOptions = append(grantOptions, privilege)
+		}
+		kept = append(kept, accessType)
+	}
+
+	var statements []string
+	if len(revokes) > 0 {
+		statements = append(statements, revokeStatement(data, revokes, false, cluster))
+	}
+	if len(grantOptions) > 0 {
+		statements = append(statements, revokeStatement(data, grantOptions, true, cluster))
+	}
+	return statements, kept
+}
+
+// keptPrivilegesWarning reports the privileges left in place because a grant on a wider target also holds them
+func keptPrivilegesWarning(data GrantResourceModel, kept []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(kept) > 0 {
+		diags.AddWarning(
+			"Privileges held through a wider grant",
+			fmt.Sprintf("%s keeps %s on %s, as a grant on a wider target also holds them. Revoke them from that grant to remove them.",
+				data.Grantee.ValueString(), strings.Join(kept, ", "), grantTarget(data)),
+		)
+	}
+	return diags
+}
+
+// sameGrantLevel reports whether a database or table reported by system.grants,
+// NULL for every object, matches the configured one
+func sameGrantLevel(actual sql.NullString, expected types.String) bool {
+	if expected.IsNull() {
+		return !actual.Valid
+	}
+	return actual.Valid && actual.String == expected.ValueString()
+}
+
+// coversGrantTarget reports whether a row of system.grants applies to a strictly wider target than the grant,
+// such as *.* or db.* for a table, or a table prefix wildcard such as db.events_* for db.events_daily
+func coversGrantTarget(row grantRow, data GrantResourceModel) bool {
+	switch {
+	case !row.Database.Valid:
+		return !data.Database.IsNull()
+	case data.Database.IsNull() || !matchesGrantName(row.Database.String, data.Database.ValueString()):
+		return false
+	case !row.Table.Valid:
+		return !data.Table.IsNull() || row.Database.String != data.Database.ValueString()
+	case data.Table.IsNull():
+		return false
+	}
+	return row.Table.String != data.Table.ValueString() && matchesGrantName(row.Table.String, data.Table.ValueString())
+}
+
+// matchesGrantName reports whether a database or table name reported by system.grants, possibly a prefix
+// wildcard ending with *, matches a configured name
+func matchesGrantName(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(strings.TrimSuffix(name, "*"), prefix)
+	}
+	return pattern == name
+}
+
+// grantStatement builds the GRANT statement for the given privileges
+func grantStatement(data GrantResourceModel, privileges []types.String, cluster string) string {
+	statement := fmt.Sprintf("GRANT%s %s ON %s TO %s", onCluster(cluster), privilegeList(data, privileges), grantTarget(data), quoteIdentifier(data.Grantee.ValueString()))
+	if data.WithGrantOption.ValueBool() {
+		statement += " WITH GRANT OPTION"
+	}
+	return statement
+}
+
+// revokeStatement builds the REVOKE statement for the given privileges, or only
+// for their grant option
+func revokeStatement(data GrantResourceModel, privileges []types.String, grantOptionOnly bool, cluster string) string {
+	prefix := "REVOKE" + onCluster(cluster) + " "
+	if grantOptionOnly {
+		prefix += "GRANT OPTION FOR "
+	}
+	return fmt.Sprintf("%s%s ON %s FROM %s", prefix, privilegeList(data, privileges), grantTarget(data), quoteIdentifier(data.Grantee.ValueString()))
+}
+
+// privilegeList renders privileges, restricted to the configured columns if any
+func privilegeList(data GrantResourceModel, privileges []types.String) string {
+	columns := ""
+	if len(data.Columns) > 0 {
+		columns = "(" + joinIdentifiers(data.Columns) + ")"
+	}
+
+	rendered := make([]string, len(privileges))
+	for i, privilege := range privileges {
+		rendered[i] = privilege.ValueString() + columns
+	}
+	return strings.Join(rendered, ", ")
+}
+
+// grantTarget renders the database.table target of a grant
+func grantTarget(data GrantResourceModel) string {
+	database, table := "*", "*"
+	if !data.Database.IsNull() {
+		database = quoteIdentifier(data.Database.ValueString())
+	}
+	if !data.Table.IsNull() {
+		// Wildcards such as events_* match table names by prefix and cannot be quoted
+		table = data.Table.ValueString()
+		if !strings.Contains(table, "*") {
+			table = quoteIdentifier(table)
+		}
+	}
+	return database + "." + table
+}
+
+// normalizedPrivileges returns the privileges in the spelling used by system.grants, sorted
+func normalizedPrivileges(privileges []types.String) []string {
+	normalized := make([]string, len(privileges))
+	for i, privilege := range privileges {
+		normalized[i] = strings.ToUpper(strings.Join(strings.Fields(privilege.ValueString()), " "))
+	}
+	sort.Strings(normalized)
+	return normalized
+}
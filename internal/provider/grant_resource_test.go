@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestGrantStatementsOnCluster(t *testing.T) {
+	data := GrantResourceModel{
+		Grantee:         types.StringValue("analyst"),
+		Database:        types.StringValue("analytics"),
+		Table:           types.StringNull(),
+		WithGrantOption: types.BoolValue(false),
+	}
+	privileges := []types.String{types.StringValue("SELECT")}
+
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{"grant", grantStatement(data, privileges, "main"), "GRANT ON CLUSTER `main` SELECT ON `analytics`.* TO `analyst`"},
+		{"grant without cluster", grantStatement(data, privileges, ""), "GRANT SELECT ON `analytics`.* TO `analyst`"},
+		{"revoke", revokeStatement(data, privileges, false, "main"), "REVOKE ON CLUSTER `main` SELECT ON `analytics`.* FROM `analyst`"},
+		{"revoke grant option", revokeStatement(data, privileges, true, "main"), "REVOKE ON CLUSTER `main` GRANT OPTION FOR SELECT ON `analytics`.* FROM `analyst`"},
+	}
+
+	for _, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.name, test.actual, test.expected)
+		}
+	}
+}
+
+func TestReconcilePrivileges(t *testing.T) {
+	null := sql.NullString{}
+	name := func(value string) sql.NullString { return sql.NullString{String: value, Valid: true} }
+	values := func(names ...string) []types.String { return stringValues(names, nil) }
+
+	databaseGrant := GrantResourceModel{
+		Database:   types.StringValue("analytics"),
+		Table:      types.StringNull(),
+		Privileges: values("SELECT", "INSERT"),
+	}
+	tableGrant := GrantResourceModel{
+		Database:   types.StringValue("analytics"),
+		Table:      types.StringValue("events"),
+		Privileges: values("SELECT"),
+	}
+	columnGrant := GrantResourceModel{
+		Database:   types.StringValue("analytics"),
+		Table:      types.StringValue("events"),
+		Columns:    values("id", "message"),
+		Privileges: values("SELECT"),
+	}
+
+	tests := []struct {
+		name        string
+		data        GrantResourceModel
+		grants      []grantRow
+		privileges  []string
+		grantOption bool
+	}{
+		{
+			name: "table rows next to a database wildcard grant",
+			data: databaseGrant,
+			grants: []grantRow{
+				{AccessType: "SELECT", Database: name("analytics"), Table: null},
+				{AccessType: "INSERT", Database: name("analytics"), Table: null},
+				{AccessType: "ALTER UPDATE", Database: name("analytics"), Table: name("events")},
+				{AccessType: "SELECT", Database: name("analytics"), Table: name("secrets"), PartialRevoke: true},
+			},
+			privileges: []string{"INSERT", "SELECT"},
+		},
+		{
+			name:   "database wildcard grant without the database rows",
+			data:   databaseGrant,
+			grants: []grantRow{{AccessType: "SELECT", Database: name("analytics"), Table: name("events")}},
+		},
+		{
+			name: "table covered by a database wildcard grant",
+			data: tableGrant,
+			grants: []grantRow{
+				{AccessType: "SELECT", Database: name("analytics"), Table: null, GrantOption: true},
+				{AccessType: "INSERT", Database: name("analytics"), Table: null},
+			},
+			privileges:  []string{"SELECT"},
+			grantOption: true,
+		},
+		{
+			name:       "table covered by a global grant",
+			data:       tableGrant,
+			grants:     []grantRow{{AccessType: "SELECT", Database: null, Table: null}},
+			privileges: []string{"SELECT"},
+		},
+		{
+			name:       "table covered by a table prefix wildcard",
+			data:       tableGrant,
+			grants:     []grantRow{{AccessType: "SELECT", Database: name("analytics"), Table: name("ev*")}},
+			privileges: []string{"SELECT"},
+		},
+		{
+			name: "table excluded from a database wildcard grant",
+			data: tableGrant,
+			grants: []grantRow{
+				{AccessType: "SELECT", Database: name("analytics"), Table: null},
+				{AccessType: "SELECT", Database: name("analytics"), Table: name("events"), PartialRevoke: true},
+			},
+		},
+		{
+			name: "privileges granted outside of Terraform on the table",
+			data: tableGrant,
+			grants: []grantRow{
+				{AccessType: "SELECT", Database: name("analytics"), Table: name("events")},
+				{AccessType: "INSERT", Database: name("analytics"), Table: name("events")},
+			},
+			privileges: []string{"INSERT", "SELECT"},
+		},
+		{
+			name: "columns granted one by one",
+			data: columnGrant,
+			grants: []grantRow{
+				{AccessType: "SELECT", Database: name("analytics"), Table: name("events"), Column: name("id")},
+				{AccessType: "SELECT", Database: name("analytics"), Table: name("events"), Column: name("message")},
+				{AccessType: "INSERT", Database: name("analytics"), Table: name("events"), Column: name("id")},
+			},
+			privileges: []string{"SELECT"},
+		},
+		{
+			name:       "columns covered by a database wildcard grant",
+			data:       columnGrant,
+			grants:     []grantRow{{AccessType: "SELECT", Database: name("analytics"), Table: null}},
+			privileges: []string{"SELECT"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			privileges, grantOption := reconcilePrivileges(test.data, test.grants)
+			if !equalStrings(privileges, test.privileges) || grantOption != test.grantOption {
+				t.Errorf("expected %v with grant option %t, got %v with grant option %t",
+					test.privileges, test.grantOption, privileges, grantOption)
+			}
+		})
+	}
+}
+
+func TestRevokeCoveredStatements(t *testing.T) {
+	null := sql.NullString{}
+	name := func(value string) sql.NullString { return sql.NullString{String: value, Valid: true} }
+	privileges := stringValues([]string{"SELECT", "insert"}, nil)
+
+	data := GrantResourceModel{
+		Grantee:         types.StringValue("analyst"),
+		Database:        types.StringValue("analytics"),
+		Table:           types.StringValue("events"),
+		WithGrantOption: types.BoolValue(true),
+	}
+
+	tests := []struct {
+		name       string
+		grants     []grantRow
+		statements []string
+		kept       []string
+	}{
+		{
+			name:   "no wider grant",
+			grants: []grantRow{{AccessType: "SELECT", Database: name("analytics"), Table: name("events"), GrantOption: true}},
+			statements: []string{
+				"REVOKE SELECT, insert ON `analytics`.`events` FROM `analyst`",
+			},
+		},
+		{
+			name: "covered by a database wildcard grant with grant option",
+			grants: []grantRow{
+				{AccessType: "SELECT", Database: name("analytics"), Table: null, GrantOption: true},
+				{AccessType: "INSERT", Database: name("analytics"), Table: name("events"), GrantOption: true},
+			},
+			statements: []string{"REVOKE insert ON `analytics`.`events` FROM `analyst`"},
+			kept:       []string{"SELECT"},
+		},
+		{
+			name:   "covered by a global grant without grant option",
+			grants: []grantRow{{AccessType: "INSERT", Database: null, Table: null}},
+			statements: []string{
+				"REVOKE SELECT ON `analytics`.`events` FROM `analyst`",
+				"REVOKE GRANT OPTION FOR insert ON `analytics`.`events` FROM `analyst`",
+			},
+			kept: []string{"INSERT"},
+		},
+		{
+			name: "wider grant partially revoked on the table",
+			grants: []grantRow{
+				{AccessType: "SELECT", Database: name("analytics"), Table: null},
+				{AccessType: "SELECT", Database: name("analytics"), Table: name("events"), PartialRevoke: true},
+			},
+			statements: []string{
+				"REVOKE SELECT, insert ON `analytics`.`events` FROM `analyst`",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements, kept := revokeCoveredStatements(data, privileges, test.grants, "")
+			if !equalStrings(statements, test.statements) || !equalStrings(kept, test.kept) {
+				t.Errorf("expected %q keeping %v, got %q keeping %v", test.statements, test.kept, statements, kept)
+			}
+		})
+	}
+}
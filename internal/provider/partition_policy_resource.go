@@ -0,0 +1,537 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &PartitionPolicyResource{}
+var _ resource.ResourceWithValidateConfig = &PartitionPolicyResource{}
+var _ resource.ResourceWithModifyPlan = &PartitionPolicyResource{}
+
+func NewPartitionPolicyResource() resource.Resource {
+	return &PartitionPolicyResource{}
+}
+
+// PartitionPolicyResource applies a partition operation to the partitions of a table.
+type PartitionPolicyResource struct {
+	client *clickhouseClient
+}
+
+// PartitionPolicyResourceModel describes the resource data model.
+type PartitionPolicyResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Database          types.String `tfsdk:"database"`
+	Table             types.String `tfsdk:"table"`
+	Action            types.String `tfsdk:"action"`
+	Partitions        types.List   `tfsdk:"partitions"`
+	OlderThan         types.String `tfsdk:"older_than"`
+	ToDisk            types.String `tfsdk:"to_disk"`
+	ToVolume          types.String `tfsdk:"to_volume"`
+	ToTable           types.String `tfsdk:"to_table"`
+	PendingPartitions types.List   `tfsdk:"pending_partitions"`
+	AppliedPartitions types.List   `tfsdk:"applied_partitions"`
+	ExecutionSettings types.Map    `tfsdk:"execution_settings"`
+}
+
+// partitionTarget is a partition selected by the policy
+type partitionTarget struct {
+	Label  string
+	Clause string
+}
+
+const (
+	partitionActionDrop   = "drop"
+	partitionActionDetach = "detach"
+	partitionActionAttach = "attach"
+	partitionActionMove   = "move"
+)
+
+var intervalPattern = regexp.MustCompile(`(?i)^\d+\s+(SECOND|MINUTE|HOUR|DAY|WEEK|MONTH|QUARTER|YEAR)S?$`)
+
+func (r *PartitionPolicyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_partition_policy"
+}
+
+func (r *PartitionPolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Declaratively drops, detaches, attaches or moves partitions of a table. Partitions are " +
+			"selected either explicitly or by age; partitions matching the policy on refresh are reported in " +
+			"`pending_partitions` and processed on the next apply. Destroying the resource does not revert anything.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Partition policy identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Database of the table",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"table": schema.StringAttribute{
+				MarkdownDescription: "Table whose partitions are managed",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"action": schema.StringAttribute{
+				MarkdownDescription: "Operation applied to the selected partitions: `drop`, `detach`, `attach` or `move`",
+				Required:            true,
+			},
+			"partitions": schema.ListAttribute{
+				MarkdownDescription: "Partition expressions as reported by `system.parts.partition` (e.g. `202401`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"older_than": schema.StringAttribute{
+				MarkdownDescription: "Select every partition whose newest row is older than this interval (e.g. `90 DAY`)",
+				Optional:            true,
+			},
+			"to_disk": schema.StringAttribute{
+				MarkdownDescription: "Destination disk of a `move`",
+				Optional:            true,
+			},
+			"to_volume": schema.StringAttribute{
+				MarkdownDescription: "Destination volume of a `move`",
+				Optional:            true,
+			},
+			"to_table": schema.StringAttribute{
+				MarkdownDescription: "Destination table (`database.table`) of a `move`",
+				Optional:            true,
+			},
+			"pending_partitions": schema.ListAttribute{
+				MarkdownDescription: "Partitions currently matching the policy that have not been processed yet",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"applied_partitions": schema.ListAttribute{
+				MarkdownDescription: "Partitions processed by the last apply",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"execution_settings": schema.MapAttribute{
+				MarkdownDescription: "ClickHouse settings applied to the partition operations (e.g. `max_execution_time` " +
+					"for large moves), overriding the provider defaults",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *PartitionPolicyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data PartitionPolicyResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	action := data.Action.ValueString()
+	switch action {
+	case partitionActionDrop, partitionActionDetach, partitionActionAttach, partitionActionMove:
+	default:
+		if !data.Action.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("action"),
+				"Invalid partition action",
+				fmt.Sprintf("Expected one of drop, detach, attach or move, got: %s", action),
+			)
+		}
+	}
+
+	if data.Partitions.IsNull() && data.OlderThan.IsNull() {
+		resp.Diagnostics.AddError(
+			"Missing partition selection",
+			"One of partitions or older_than must be set.",
+		)
+	}
+	if !data.Partitions.IsNull() && !data.OlderThan.IsNull() {
+		resp.Diagnostics.AddError(
+			"Conflicting partition selection",
+			"Only one of partitions or older_than can be set.",
+		)
+	}
+
+	if !data.OlderThan.IsNull() && !data.OlderThan.IsUnknown() {
+		if !intervalPattern.MatchString(data.OlderThan.ValueString()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("older_than"),
+				"Invalid interval",
+				fmt.Sprintf("Expected an interval such as '90 DAY', got: %s", data.OlderThan.ValueString()),
+			)
+		}
+		if action == partitionActionAttach {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("older_than"),
+				"Invalid partition selection",
+				"Detached partitions carry no age information; use partitions with the attach action.",
+			)
+		}
+	}
+
+	destinations := 0
+	for _, destination := range []types.String{data.ToDisk, data.ToVolume, data.ToTable} {
+		if !destination.IsNull() {
+			destinations++
+		}
+	}
+	if action == partitionActionMove && destinations != 1 {
+		resp.Diagnostics.AddError(
+			"Invalid move destination",
+			"Exactly one of to_disk, to_volume or to_table must be set when action is move.",
+		)
+	}
+	if action != partitionActionMove && !data.Action.IsUnknown() && destinations > 0 {
+		resp.Diagnostics.AddError(
+			"Invalid move destination",
+			"to_disk, to_volume and to_table can only be set when action is move.",
+		)
+	}
+}
+
+func (r *PartitionPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to do on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var state PartitionPolicyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Partitions matching the policy since the last apply trigger an update
+	if len(state.PendingPartitions.Elements()) > 0 {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("pending_partitions"), types.ListUnknown(types.StringType))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("applied_partitions"), types.ListUnknown(types.StringType))...)
+	}
+}
+
+func (r *PartitionPolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *PartitionPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PartitionPolicyResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	data.ID = types.StringValue(fmt.Sprintf("%s.%s:%s", data.Database.ValueString(), data.Table.ValueString(), data.Action.ValueString()))
+
+	if err := r.apply(ctx, &data); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error applying partition policy",
+			fmt.Sprintf("Could not apply partition policy on %s.%s", data.Database.ValueString(), data.Table.ValueString()),
+			err,
+		))
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PartitionPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PartitionPolicyResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var exists uint64
+	err := r.client.QueryRowContext(ctx, "SELECT count() FROM system.tables WHERE database = ? AND name = ?",
+		data.Database.ValueString(), data.Table.ValueString()).Scan(&exists)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error checking table existence",
+			fmt.Sprintf("Could not check if table %s.%s exists: %s", data.Database.ValueString(), data.Table.ValueString(), err.Error()),
+		)
+		return
+	}
+	if exists == 0 {
+		tflog.Info(ctx, "Table no longer exists, removing partition policy from state", map[string]interface{}{
+			"id": data.ID.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	targets, err := r.pendingPartitions(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading partitions",
+			fmt.Sprintf("Could not read partitions of %s.%s: %s", data.Database.ValueString(), data.Table.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	data.PendingPartitions = partitionLabels(targets)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PartitionPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data PartitionPolicyResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	data.ID = types.StringValue(fmt.Sprintf("%s.%s:%s", data.Database.ValueString(), data.Table.ValueString(), data.Action.ValueString()))
+
+	if err := r.apply(ctx, &data); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error applying partition policy",
+			fmt.Sprintf("Could not apply partition policy on %s.%s", data.Database.ValueString(), data.Table.ValueString()),
+			err,
+		))
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PartitionPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PartitionPolicyResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Partition operations cannot be reverted, the policy is only removed from state
+	tflog.Info(ctx, "Removing partition policy from state", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+}
+
+// apply runs the partition operation on every pending partition
+func (r *PartitionPolicyResource) apply(ctx context.Context, data *PartitionPolicyResourceModel) error {
+	targets, err := r.pendingPartitions(ctx, *data)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		statement := r.partitionStatement(*data, target)
+
+		tflog.Info(ctx, "Applying partition policy", map[string]interface{}{
+			"id":  data.ID.ValueString(),
+			"sql": statement,
+		})
+
+		if _, err := r.client.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("partition %s: %w", target.Label, withStatement(statement, err))
+		}
+	}
+
+	data.AppliedPartitions = partitionLabels(targets)
+
+	remaining, err := r.pendingPartitions(ctx, *data)
+	if err != nil {
+		return err
+	}
+	data.PendingPartitions = partitionLabels(remaining)
+
+	return nil
+}
+
+// pendingPartitions lists the partitions matching the policy that still need to be processed
+func (r *PartitionPolicyResource) pendingPartitions(ctx context.Context, data PartitionPolicyResourceModel) ([]partitionTarget, error) {
+	database := data.Database.ValueString()
+	table := data.Table.ValueString()
+	action := data.Action.ValueString()
+
+	if action == partitionActionAttach {
+		var detached uint64
+		err := r.client.QueryRowContext(ctx, "SELECT count() FROM system.detached_parts WHERE database = ? AND table = ?",
+			database, table).Scan(&detached)
+		if err != nil || detached == 0 {
+			return nil, err
+		}
+
+		active, err := r.activePartitions(ctx, database, table, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var targets []partitionTarget
+		for _, partition := range partitionExpressions(data) {
+			if _, ok := active[partition]; !ok {
+				targets = append(targets, partitionTarget{Label: partition, Clause: partition})
+			}
+		}
+		return targets, nil
+	}
+
+	// Partitions already on the destination disk are done
+	condition := ""
+	if action == partitionActionMove && !data.ToDisk.IsNull() {
+		condition = " AND disk_name != " + quoteString(data.ToDisk.ValueString())
+	}
+
+	if !data.OlderThan.IsNull() {
+		return r.expiredPartitions(ctx, database, table, data.OlderThan.ValueString(), condition)
+	}
+
+	active, err := r.activePartitions(ctx, database, table, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []partitionTarget
+	for _, partition := range partitionExpressions(data) {
+		if _, ok := active[partition]; ok {
+			targets = append(targets, partitionTarget{Label: partition, Clause: partition})
+		}
+	}
+	return targets, nil
+}
+
+// activePartitions returns the partitions of a table holding active parts, keyed by partition expression
+func (r *PartitionPolicyResource) activePartitions(ctx context.Context, database, table, condition string) (map[string]string, error) {
+	query := `
+        SELECT DISTINCT partition, partition_id
+        FROM system.parts
+        WHERE database = ? AND table = ? AND active` + condition
+
+	rows, err := r.client.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := make(map[string]string)
+	for rows.Next() {
+		var partition, partitionID string
+		if err := rows.Scan(&partition, &partitionID); err != nil {
+			return nil, err
+		}
+		partitions[partition] = partitionID
+	}
+
+	return partitions, rows.Err()
+}
+
+// expiredPartitions returns the partitions whose newest row is older than the interval.
+// Partitions without min/max time information are never selected.
+func (r *PartitionPolicyResource) expiredPartitions(ctx context.Context, database, table, interval, condition string) ([]partitionTarget, error) {
+	query := fmt.Sprintf(`
+        SELECT partition, partition_id
+        FROM system.parts
+        WHERE database = ? AND table = ? AND active%s
+        GROUP BY partition, partition_id
+        HAVING max(greatest(max_time, toDateTime(max_date))) > toDateTime(0)
+            AND max(greatest(max_time, toDateTime(max_date))) < now() - INTERVAL %s
+        ORDER BY partition_id
+    `, condition, strings.ToUpper(interval))
+
+	rows, err := r.client.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []partitionTarget
+	for rows.Next() {
+		var partition, partitionID string
+		if err := rows.Scan(&partition, &partitionID); err != nil {
+			return nil, err
+		}
+		targets = append(targets, partitionTarget{Label: partition, Clause: "ID " + quoteString(partitionID)})
+	}
+
+	return targets, rows.Err()
+}
+
+// partitionStatement renders the ALTER TABLE statement applying the action to a partition
+func (r *PartitionPolicyResource) partitionStatement(data PartitionPolicyResourceModel, target partitionTarget) string {
+	table := qualifiedName(data.Database.ValueString(), data.Table.ValueString())
+
+	switch data.Action.ValueString() {
+	case partitionActionDetach:
+		return fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", table, target.Clause)
+	case partitionActionAttach:
+		return fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s", table, target.Clause)
+	case partitionActionMove:
+		switch {
+		case !data.ToDisk.IsNull():
+			return fmt.Sprintf("ALTER TABLE %s MOVE PARTITION %s TO DISK %s", table, target.Clause, quoteString(data.ToDisk.ValueString()))
+		case !data.ToVolume.IsNull():
+			return fmt.Sprintf("ALTER TABLE %s MOVE PARTITION %s TO VOLUME %s", table, target.Clause, quoteString(data.ToVolume.ValueString()))
+		default:
+			return fmt.Sprintf("ALTER TABLE %s MOVE PARTITION %s TO TABLE %s", table, target.Clause, quoteTableReference(data.ToTable.ValueString()))
+		}
+	default:
+		return fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s", table, target.Clause)
+	}
+}
+
+// partitionExpressions returns the explicitly selected partitions
+func partitionExpressions(data PartitionPolicyResourceModel) []string {
+	var partitions []string
+	for _, element := range data.Partitions.Elements() {
+		if partition, ok := element.(types.String); ok {
+			partitions = append(partitions, partition.ValueString())
+		}
+	}
+	return partitions
+}
+
+func partitionLabels(targets []partitionTarget) types.List {
+	labels := make([]attr.Value, len(targets))
+	for i, target := range targets {
+		labels[i] = types.StringValue(target.Label)
+	}
+	return types.ListValueMust(types.StringType, labels)
+}
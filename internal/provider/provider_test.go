@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestUnknownConnectionAttributes(t *testing.T) {
+	tlsTypes := map[string]attr.Type{"server_name": types.StringType, "insecure_skip_verify": types.BoolType}
+	retryTypes := map[string]attr.Type{"max_attempts": types.Int64Type}
+
+	tests := []struct {
+		name     string
+		config   clickhouseSchemaProviderModel
+		expected []string
+	}{
+		{"known", clickhouseSchemaProviderModel{
+			Host:     types.StringValue("localhost"),
+			Port:     types.Int64Value(9000),
+			Settings: types.MapValueMust(types.StringType, map[string]attr.Value{"max_execution_time": types.StringValue("60")}),
+		}, nil},
+		{"unknown host", clickhouseSchemaProviderModel{Host: types.StringUnknown()}, []string{"host"}},
+		{"unknown tls", clickhouseSchemaProviderModel{TLS: types.ObjectUnknown(tlsTypes)}, []string{"tls"}},
+		{"unknown tls option", clickhouseSchemaProviderModel{
+			TLS: types.ObjectValueMust(tlsTypes, map[string]attr.Value{
+				"server_name":          types.StringUnknown(),
+				"insecure_skip_verify": types.BoolValue(false),
+			}),
+		}, []string{"tls.server_name"}},
+		{"unknown retry and replica health check", clickhouseSchemaProviderModel{
+			Retry:              types.ObjectValueMust(retryTypes, map[string]attr.Value{"max_attempts": types.Int64Unknown()}),
+			ReplicaHealthCheck: types.ObjectUnknown(map[string]attr.Type{}),
+		}, []string{"replica_health_check", "retry.max_attempts"}},
+		{"unknown failover address and setting", clickhouseSchemaProviderModel{
+			FailoverAddresses: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("replica-1:9000"), types.StringUnknown()}),
+			Settings:          types.MapValueMust(types.StringType, map[string]attr.Value{"max_execution_time": types.StringUnknown()}),
+		}, []string{"failover_addresses[1]", `settings["max_execution_time"]`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual []string
+			for _, unknown := range unknownConnectionAttributes(test.config) {
+				actual = append(actual, unknown.String())
+			}
+			if !equalStrings(actual, test.expected) {
+				t.Errorf("expected unknown attributes %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,503 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &QuotaResource{}
+var _ resource.ResourceWithImportState = &QuotaResource{}
+
+func NewQuotaResource() resource.Resource {
+	return &QuotaResource{}
+}
+
+// QuotaResource manages a ClickHouse quota.
+type QuotaResource struct {
+	client *clickhouseClient
+}
+
+// QuotaResourceModel describes the resource data model.
+type QuotaResourceModel struct {
+	ID        types.String         `tfsdk:"id"`
+	Name      types.String         `tfsdk:"name"`
+	Cluster   types.String         `tfsdk:"cluster"`
+	KeyedBy   types.String         `tfsdk:"keyed_by"`
+	To        []types.String       `tfsdk:"to"`
+	Intervals []QuotaIntervalModel `tfsdk:"interval"`
+}
+
+type QuotaIntervalModel struct {
+	Duration         types.Int64   `tfsdk:"duration"`
+	Randomized       types.Bool    `tfsdk:"randomized"`
+	MaxQueries       types.Int64   `tfsdk:"max_queries"`
+	MaxQuerySelects  types.Int64   `tfsdk:"max_query_selects"`
+	MaxQueryInserts  types.Int64   `tfsdk:"max_query_inserts"`
+	MaxErrors        types.Int64   `tfsdk:"max_errors"`
+	MaxResultRows    types.Int64   `tfsdk:"max_result_rows"`
+	MaxResultBytes   types.Int64   `tfsdk:"max_result_bytes"`
+	MaxReadRows      types.Int64   `tfsdk:"max_read_rows"`
+	MaxReadBytes     types.Int64   `tfsdk:"max_read_bytes"`
+	MaxExecutionTime types.Float64 `tfsdk:"max_execution_time"`
+}
+
+func (r *QuotaResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_quota"
+}
+
+func (r *QuotaResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	limit := func(description string) schema.Int64Attribute {
+		return schema.Int64Attribute{
+			MarkdownDescription: description,
+			Optional:            true,
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a ClickHouse quota created with `CREATE QUOTA`, limiting the resources consumed " +
+			"over one or more intervals by the users and roles it is assigned to.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Quota identifier",
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Quota name",
+				Required:            true,
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the quota is created with `ON CLUSTER`, defaulting to the provider `cluster`. " +
+					"An empty string creates it on the connected server only",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"keyed_by": schema.StringAttribute{
+				MarkdownDescription: "Key the consumption is tracked by: `user_name`, `ip_address`, `client_key`, " +
+					"`client_key,user_name` or `client_key,ip_address`. Shared by every user when unset.",
+				Optional: true,
+			},
+			"to": schema.ListAttribute{
+				MarkdownDescription: "Users and roles the quota applies to, or `ALL`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"interval": schema.ListNestedBlock{
+				MarkdownDescription: "Limits enforced over an interval. An interval without limits only tracks consumption.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"duration": schema.Int64Attribute{
+							MarkdownDescription: "Interval length in seconds",
+							Required:            true,
+						},
+						"randomized": schema.BoolAttribute{
+							MarkdownDescription: "Start the interval at a random offset instead of at the epoch",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+						"max_queries":       limit("Maximum number of queries"),
+						"max_query_selects": limit("Maximum number of SELECT queries"),
+						"max_query_inserts": limit("Maximum number of INSERT queries"),
+						"max_errors":        limit("Maximum number of queries throwing an exception"),
+						"max_result_rows":   limit("Maximum number of rows returned"),
+						"max_result_bytes":  limit("Maximum number of bytes returned"),
+						"max_read_rows":     limit("Maximum number of source rows read"),
+						"max_read_bytes":    limit("Maximum number of source bytes read"),
+						"max_execution_time": schema.Float64Attribute{
+							MarkdownDescription: "Maximum total query execution time, in seconds",
+							Optional:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *QuotaResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *QuotaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data QuotaResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createSQL := fmt.Sprintf("CREATE QUOTA %s%s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)), quotaClauses(data, nil, false))
+
+	tflog.Info(ctx, "Creating ClickHouse quota", map[string]interface{}{
+		"sql": createSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, createSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error creating quota",
+			fmt.Sprintf("Could not create quota %s", data.Name.ValueString()),
+			withStatement(createSQL, err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *QuotaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data QuotaResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.readQuota(ctx, data.ID.ValueString(), data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			tflog.Info(ctx, "Quota no longer exists, removing from state", map[string]interface{}{
+				"id": data.ID.ValueString(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading quota",
+			fmt.Sprintf("Could not read quota %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &current)...)
+}
+
+func (r *QuotaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state QuotaResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alterSQL := fmt.Sprintf("ALTER QUOTA %s%s", quoteIdentifier(state.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+	if data.Name.ValueString() != state.Name.ValueString() {
+		alterSQL += fmt.Sprintf(" RENAME TO %s", quoteIdentifier(data.Name.ValueString()))
+	}
+	alterSQL += quotaClauses(data, state.Intervals, true)
+
+	tflog.Info(ctx, "Updating ClickHouse quota", map[string]interface{}{
+		"sql": alterSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, alterSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error updating quota",
+			fmt.Sprintf("Could not alter quota %s", state.Name.ValueString()),
+			withStatement(alterSQL, err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *QuotaResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data QuotaResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dropSQL := fmt.Sprintf("DROP QUOTA IF EXISTS %s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+
+	tflog.Info(ctx, "Dropping ClickHouse quota", map[string]interface{}{
+		"sql": dropSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, dropSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error dropping quota",
+			fmt.Sprintf("Could not drop quota %s", data.Name.ValueString()),
+			withStatement(dropSQL, err),
+		))
+	}
+}
+
+func (r *QuotaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	data, err := r.readQuota(ctx, req.ID, QuotaResourceModel{Cluster: types.StringNull()})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error importing quota",
+			fmt.Sprintf("Could not read quota %s from system.quotas: %s", req.ID, err.Error()),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readQuota reads a quota from system.quotas and its limits from system.quota_limits
+func (r *QuotaResource) readQuota(ctx context.Context, name string, prior QuotaResourceModel) (QuotaResourceModel, error) {
+	var keys, applyTo []string
+	var applyToAll uint8
+	err := r.client.QueryRowContext(ctx, "SELECT arrayMap(k -> toString(k), keys), apply_to_all, apply_to_list FROM system.quotas WHERE name = ?",
+		name).Scan(&keys, &applyToAll, &applyTo)
+	if err != nil {
+		return QuotaResourceModel{}, err
+	}
+
+	intervals, err := r.readIntervals(ctx, name)
+	if err != nil {
+		return QuotaResourceModel{}, err
+	}
+
+	data := QuotaResourceModel{
+		ID:      types.StringValue(name),
+		Name:    types.StringValue(name),
+		Cluster: prior.Cluster,
+		KeyedBy: types.StringNull(),
+		To:      stringValues(applyTo, prior.To),
+	}
+	if len(keys) > 0 {
+		data.KeyedBy = types.StringValue(strings.Join(keys, ","))
+		if normalizeKeyedBy(prior.KeyedBy.ValueString()) == data.KeyedBy.ValueString() {
+			data.KeyedBy = prior.KeyedBy
+		}
+	}
+	if applyToAll != 0 {
+		data.To = []types.String{types.StringValue("ALL")}
+	}
+
+	// Keep the configured order of the intervals when they are unchanged
+	data.Intervals = intervals
+	if prior.Intervals != nil && equalStrings(intervalKeys(prior.Intervals), intervalKeys(intervals)) {
+		data.Intervals = prior.Intervals
+	}
+
+	return data, nil
+}
+
+// readIntervals reads the limits of every interval of a quota
+func (r *QuotaResource) readIntervals(ctx context.Context, name string) ([]QuotaIntervalModel, error) {
+	query := `
+        SELECT duration, is_randomized_interval, max_queries, max_query_selects, max_query_inserts, max_errors,
+               max_result_rows, max_result_bytes, max_read_rows, max_read_bytes, max_execution_time
+        FROM system.quota_limits
+        WHERE quota_name = ?
+        ORDER BY duration
+    `
+
+	rows, err := r.client.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var intervals []QuotaIntervalModel
+	for rows.Next() {
+		var duration uint32
+		var randomized uint8
+		var queries, selects, inserts, errorCount, resultRows, resultBytes, readRows, readBytes sql.NullInt64
+		var executionTime sql.NullFloat64
+		if err := rows.Scan(&duration, &randomized, &queries, &selects, &inserts, &errorCount,
+			&resultRows, &resultBytes, &readRows, &readBytes, &executionTime); err != nil {
+			return nil, err
+		}
+
+		interval := QuotaIntervalModel{
+			Duration:         types.Int64Value(int64(duration)),
+			Randomized:       types.BoolValue(randomized != 0),
+			MaxQueries:       nullableInt64(queries),
+			MaxQuerySelects:  nullableInt64(selects),
+			MaxQueryInserts:  nullableInt64(inserts),
+			MaxErrors:        nullableInt64(errorCount),
+			MaxResultRows:    nullableInt64(resultRows),
+			MaxResultBytes:   nullableInt64(resultBytes),
+			MaxReadRows:      nullableInt64(readRows),
+			MaxReadBytes:     nullableInt64(readBytes),
+			MaxExecutionTime: types.Float64Null(),
+		}
+		if executionTime.Valid {
+			interval.MaxExecutionTime = types.Float64Value(executionTime.Float64)
+		}
+		intervals = append(intervals, interval)
+	}
+
+	return intervals, rows.Err()
+}
+
+// limits returns the configured limits of an interval keyed by quota type. When
+// all is set, unset limits are included with 0, which removes them.
+func (m QuotaIntervalModel) limits(all bool) map[string]string {
+	values := map[string]types.Int64{
+		"queries":       m.MaxQueries,
+		"query_selects": m.MaxQuerySelects,
+		"query_inserts": m.MaxQueryInserts,
+		"errors":        m.MaxErrors,
+		"result_rows":   m.MaxResultRows,
+		"result_bytes":  m.MaxResultBytes,
+		"read_rows":     m.MaxReadRows,
+		"read_bytes":    m.MaxReadBytes,
+	}
+
+	limits := map[string]string{}
+	for name, value := range values {
+		if !value.IsNull() {
+			limits[name] = strconv.FormatInt(value.ValueInt64(), 10)
+		} else if all {
+			limits[name] = "0"
+		}
+	}
+	if !m.MaxExecutionTime.IsNull() {
+		limits["execution_time"] = strconv.FormatFloat(m.MaxExecutionTime.ValueFloat64(), 'f', -1, 64)
+	} else if all {
+		limits["execution_time"] = "0"
+	}
+	return limits
+}
+
+// interval renders the FOR INTERVAL prefix of an interval
+func (m QuotaIntervalModel) interval() string {
+	randomized := ""
+	if m.Randomized.ValueBool() {
+		randomized = "RANDOMIZED "
+	}
+	return fmt.Sprintf("FOR %sINTERVAL %d second", randomized, m.Duration.ValueInt64())
+}
+
+// quotaClauses builds the clauses shared by CREATE QUOTA and ALTER QUOTA. When
+// altering, the intervals of the prior state missing from the configuration are
+// removed and unset options are reset explicitly.
+func quotaClauses(data QuotaResourceModel, prior []QuotaIntervalModel, alter bool) string {
+	var clauses []string
+
+	switch {
+	case !data.KeyedBy.IsNull():
+		clauses = append(clauses, "KEYED BY "+normalizeKeyedBy(data.KeyedBy.ValueString()))
+	case alter:
+		clauses = append(clauses, "NOT KEYED")
+	}
+
+	var intervals []string
+	for _, interval := range data.Intervals {
+		limits := interval.limits(alter)
+		if len(interval.limits(false)) == 0 {
+			intervals = append(intervals, interval.interval()+" TRACKING ONLY")
+			continue
+		}
+
+		var values []string
+		for _, name := range sortedKeys(limits) {
+			values = append(values, fmt.Sprintf("%s = %s", name, limits[name]))
+		}
+		intervals = append(intervals, interval.interval()+" MAX "+strings.Join(values, ", "))
+	}
+
+	desired := map[string]bool{}
+	for _, key := range intervalPeriods(data.Intervals) {
+		desired[key] = true
+	}
+	for _, interval := range prior {
+		if !desired[interval.interval()] {
+			intervals = append(intervals, interval.interval()+" NO LIMITS")
+		}
+	}
+	if len(intervals) > 0 {
+		clauses = append(clauses, strings.Join(intervals, ", "))
+	}
+
+	switch {
+	case len(data.To) > 0:
+		clauses = append(clauses, "TO "+joinIdentifiers(data.To))
+	case alter:
+		clauses = append(clauses, "TO NONE")
+	}
+
+	if len(clauses) == 0 {
+		return ""
+	}
+	return " " + strings.Join(clauses, " ")
+}
+
+// intervalPeriods returns the FOR INTERVAL prefix of every interval
+func intervalPeriods(intervals []QuotaIntervalModel) []string {
+	periods := make([]string, len(intervals))
+	for i, interval := range intervals {
+		periods[i] = interval.interval()
+	}
+	return periods
+}
+
+// intervalKeys returns a sorted canonical representation of intervals, to compare them regardless of order
+func intervalKeys(intervals []QuotaIntervalModel) []string {
+	keys := make([]string, len(intervals))
+	for i, interval := range intervals {
+		limits := interval.limits(false)
+		var values []string
+		for _, name := range sortedKeys(limits) {
+			values = append(values, name+"="+limits[name])
+		}
+		keys[i] = interval.interval() + " " + strings.Join(values, ",")
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeKeyedBy removes the spaces of a KEYED BY key list
+func normalizeKeyedBy(keyedBy string) string {
+	return strings.ReplaceAll(keyedBy, " ", "")
+}
+
+// nullableInt64 converts a nullable integer read from the server
+func nullableInt64(value sql.NullInt64) types.Int64 {
+	if !value.Valid {
+		return types.Int64Null()
+	}
+	return types.Int64Value(value.Int64)
+}
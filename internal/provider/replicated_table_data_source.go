@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ReplicatedTableDataSource{}
+
+func NewReplicatedTableDataSource() datasource.DataSource {
+	return &ReplicatedTableDataSource{}
+}
+
+// ReplicatedTableDataSource exposes the Keeper metadata of a Replicated table.
+type ReplicatedTableDataSource struct {
+	client *clickhouseClient
+}
+
+// ReplicatedTableDataSourceModel describes the data source data model.
+type ReplicatedTableDataSourceModel struct {
+	ID             types.String   `tfsdk:"id"`
+	Database       types.String   `tfsdk:"database"`
+	Table          types.String   `tfsdk:"table"`
+	ZookeeperPath  types.String   `tfsdk:"zookeeper_path"`
+	ReplicaName    types.String   `tfsdk:"replica_name"`
+	ReplicaPath    types.String   `tfsdk:"replica_path"`
+	IsLeader       types.Bool     `tfsdk:"is_leader"`
+	TotalReplicas  types.Int64    `tfsdk:"total_replicas"`
+	ActiveReplicas types.Int64    `tfsdk:"active_replicas"`
+	Replicas       []ReplicaModel `tfsdk:"replicas"`
+}
+
+type ReplicaModel struct {
+	Name   types.String `tfsdk:"name"`
+	Active types.Bool   `tfsdk:"active"`
+}
+
+func (d *ReplicatedTableDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_replicated_table"
+}
+
+func (d *ReplicatedTableDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the Keeper/ZooKeeper metadata of a Replicated table from `system.replicas` and " +
+			"`system.zookeeper`: its Keeper path and every replica registered under it, with whether the replica " +
+			"currently holds an active session. Useful to validate the replication layout or to build alerts.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Replicated table identifier",
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Database of the table",
+				Required:            true,
+			},
+			"table": schema.StringAttribute{
+				MarkdownDescription: "Table name",
+				Required:            true,
+			},
+			"zookeeper_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Keeper path of the table",
+			},
+			"replica_name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the replica on the connected server",
+			},
+			"replica_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Keeper path of the replica on the connected server",
+			},
+			"is_leader": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the replica on the connected server can assign merges",
+			},
+			"total_replicas": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Number of replicas registered in Keeper",
+			},
+			"active_replicas": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Number of replicas with an active Keeper session",
+			},
+			"replicas": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Replicas registered under the Keeper path of the table",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Replica name",
+						},
+						"active": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the replica has an active Keeper session",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ReplicatedTableDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ReplicatedTableDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ReplicatedTableDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := fmt.Sprintf("%s.%s", data.Database.ValueString(), data.Table.ValueString())
+
+	query := `
+        SELECT zookeeper_path, replica_name, replica_path, is_leader, total_replicas, active_replicas
+        FROM system.replicas
+        WHERE database = ? AND table = ?
+    `
+
+	var zookeeperPath, replicaName, replicaPath string
+	var isLeader, totalReplicas, activeReplicas uint8
+	err := d.client.QueryRowContext(ctx, query, data.Database.ValueString(), data.Table.ValueString()).
+		Scan(&zookeeperPath, &replicaName, &replicaPath, &isLeader, &totalReplicas, &activeReplicas)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Table is not replicated",
+			fmt.Sprintf("Table %s does not exist or does not use a Replicated engine", id),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading replication status",
+			fmt.Sprintf("Could not read system.replicas for %s: %s", id, err.Error()),
+		)
+		return
+	}
+
+	replicas, err := d.readReplicas(ctx, zookeeperPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading Keeper metadata",
+			fmt.Sprintf("Could not read replicas of %s from system.zookeeper: %s", id, err.Error()),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(id)
+	data.ZookeeperPath = types.StringValue(zookeeperPath)
+	data.ReplicaName = types.StringValue(replicaName)
+	data.ReplicaPath = types.StringValue(replicaPath)
+	data.IsLeader = types.BoolValue(isLeader != 0)
+	data.TotalReplicas = types.Int64Value(int64(totalReplicas))
+	data.ActiveReplicas = types.Int64Value(int64(activeReplicas))
+	data.Replicas = replicas
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readReplicas lists the replicas registered under a table Keeper path. A replica
+// is active while its ephemeral is_active node exists.
+func (d *ReplicatedTableDataSource) readReplicas(ctx context.Context, zookeeperPath string) ([]ReplicaModel, error) {
+	rows, err := d.client.QueryContext(ctx, "SELECT name FROM system.zookeeper WHERE path = ? ORDER BY name",
+		zookeeperPath+"/replicas")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	replicas := []ReplicaModel{}
+	for _, name := range names {
+		var active uint64
+		err := d.client.QueryRowContext(ctx, "SELECT count() FROM system.zookeeper WHERE path = ? AND name = 'is_active'",
+			zookeeperPath+"/replicas/"+name).Scan(&active)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, ReplicaModel{
+			Name:   types.StringValue(name),
+			Active: types.BoolValue(active > 0),
+		})
+	}
+
+	return replicas, nil
+}
@@ -0,0 +1,445 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &RoleResource{}
+var _ resource.ResourceWithImportState = &RoleResource{}
+
+func NewRoleResource() resource.Resource {
+	return &RoleResource{}
+}
+
+// RoleResource manages a ClickHouse role.
+type RoleResource struct {
+	client *clickhouseClient
+}
+
+// RoleResourceModel describes the resource data model.
+type RoleResourceModel struct {
+	ID              types.String            `tfsdk:"id"`
+	Name            types.String            `tfsdk:"name"`
+	Cluster         types.String            `tfsdk:"cluster"`
+	Settings        map[string]types.String `tfsdk:"settings"`
+	SettingsProfile types.String            `tfsdk:"settings_profile"`
+	Grants          types.List              `tfsdk:"grants"`
+	GrantedRoles    types.List              `tfsdk:"granted_roles"`
+}
+
+var numericLiteral = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+func (r *RoleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role"
+}
+
+func (r *RoleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a ClickHouse role created with `CREATE ROLE`, with optional settings overrides " +
+			"applied to the users the role is granted to. Renaming the role runs `ALTER ROLE ... RENAME TO`. " +
+			"The privileges and roles granted to the role are reported by `grants` and `granted_roles`, so that " +
+			"they can be adopted with `clickhouse_grant` resources after importing the role.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Role identifier",
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Role name",
+				Required:            true,
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the role is created with `ON CLUSTER`, defaulting to the provider `cluster`. " +
+					"An empty string creates it on the connected server only",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"settings": schema.MapAttribute{
+				MarkdownDescription: "Settings overridden for the users holding the role (e.g. `max_memory_usage`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"settings_profile": schema.StringAttribute{
+				MarkdownDescription: "Settings profile applied to the users holding the role",
+				Optional:            true,
+			},
+			"grants":        grantsAttribute("role"),
+			"granted_roles": grantedRolesAttribute("role"),
+		},
+	}
+}
+
+func (r *RoleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *RoleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data RoleResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createSQL := fmt.Sprintf("CREATE ROLE %s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+	if settings := roleSettingsClause(data); settings != "" {
+		createSQL += " SETTINGS " + settings
+	}
+
+	tflog.Info(ctx, "Creating ClickHouse role", map[string]interface{}{
+		"sql": createSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, createSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error creating role",
+			fmt.Sprintf("Could not create role %s", data.Name.ValueString()),
+			withStatement(createSQL, err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+	data.Grants, data.GrantedRoles = grantList(nil), roleGrantList(nil)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RoleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data RoleResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.readRole(ctx, data.ID.ValueString(), data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			tflog.Info(ctx, "Role no longer exists, removing from state", map[string]interface{}{
+				"id": data.ID.ValueString(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading role",
+			fmt.Sprintf("Could not read role %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &current)...)
+}
+
+func (r *RoleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state RoleResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alterSQL := fmt.Sprintf("ALTER ROLE %s%s", quoteIdentifier(state.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+	if data.Name.ValueString() != state.Name.ValueString() {
+		alterSQL += fmt.Sprintf(" RENAME TO %s", quoteIdentifier(data.Name.ValueString()))
+	}
+	if settings := roleSettingsClause(data); settings != "" {
+		alterSQL += " SETTINGS " + settings
+	} else {
+		alterSQL += " SETTINGS NONE"
+	}
+
+	tflog.Info(ctx, "Updating ClickHouse role", map[string]interface{}{
+		"sql": alterSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, alterSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error updating role",
+			fmt.Sprintf("Could not alter role %s", state.Name.ValueString()),
+			withStatement(alterSQL, err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+
+	// State saved without the granted privileges, e.g. not refreshed before the plan, has nothing to keep
+	if data.Grants.IsUnknown() || data.GrantedRoles.IsUnknown() {
+		var err error
+		data.Grants, data.GrantedRoles, err = readAccessRights(ctx, r.client, data.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading role grants",
+				fmt.Sprintf("Could not read the privileges of role %s: %s", data.Name.ValueString(), err.Error()),
+			)
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RoleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data RoleResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dropSQL := fmt.Sprintf("DROP ROLE IF EXISTS %s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+
+	tflog.Info(ctx, "Dropping ClickHouse role", map[string]interface{}{
+		"sql": dropSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, dropSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error dropping role",
+			fmt.Sprintf("Could not drop role %s", data.Name.ValueString()),
+			withStatement(dropSQL, err),
+		))
+	}
+}
+
+func (r *RoleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	data, err := r.readRole(ctx, req.ID, RoleResourceModel{Cluster: types.StringNull()})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error importing role",
+			fmt.Sprintf("Could not read role %s from system.roles: %s", req.ID, err.Error()),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readRole reads a role and its settings from system.roles and system.settings_profile_elements
+func (r *RoleResource) readRole(ctx context.Context, name string, prior RoleResourceModel) (RoleResourceModel, error) {
+	var exists string
+	if err := r.client.QueryRowContext(ctx, "SELECT name FROM system.roles WHERE name = ?", name).Scan(&exists); err != nil {
+		return RoleResourceModel{}, err
+	}
+
+	settings, profile, err := readSettingsProfileElements(ctx, r.client, "role_name", name)
+	if err != nil {
+		return RoleResourceModel{}, err
+	}
+
+	grants, grantedRoles, err := readAccessRights(ctx, r.client, name)
+	if err != nil {
+		return RoleResourceModel{}, err
+	}
+
+	data := RoleResourceModel{
+		ID:              types.StringValue(name),
+		Name:            types.StringValue(name),
+		Cluster:         prior.Cluster,
+		SettingsProfile: types.StringNull(),
+		Grants:          grants,
+		GrantedRoles:    grantedRoles,
+	}
+	if len(settings) > 0 || prior.Settings != nil {
+		data.Settings = map[string]types.String{}
+		for setting, value := range settings {
+			data.Settings[setting] = types.StringValue(value)
+		}
+	}
+	if profile != "" {
+		data.SettingsProfile = types.StringValue(profile)
+	}
+
+	return data, nil
+}
+
+// grantsAttribute describes the privileges granted to a user or role
+func grantsAttribute(owner string) schema.ListAttribute {
+	return schema.ListAttribute{
+		MarkdownDescription: fmt.Sprintf("Privileges granted to the %s, as reported by `system.grants`: `access_type`, "+
+			"`database`, `table` and `column` (null for all of them), `grant_option` and `partial_revoke`", owner),
+		Computed:    true,
+		ElementType: grantObjectType,
+		PlanModifiers: []planmodifier.List{
+			listplanmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+// grantedRolesAttribute describes the roles granted to a user or role
+func grantedRolesAttribute(owner string) schema.ListAttribute {
+	return schema.ListAttribute{
+		MarkdownDescription: fmt.Sprintf("Roles granted to the %s, as reported by `system.role_grants`: `role`, "+
+			"whether it is a `default` role and whether it is granted with `admin_option`", owner),
+		Computed:    true,
+		ElementType: roleGrantObjectType,
+		PlanModifiers: []planmodifier.List{
+			listplanmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+// roleGrantRow is a role granted to a user or role, as reported by system.role_grants
+type roleGrantRow struct {
+	Role        string
+	Default     bool
+	AdminOption bool
+}
+
+// roleGrantObjectType is the element type of the granted_roles attribute of users and roles
+var roleGrantObjectType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"role":         types.StringType,
+	"default":      types.BoolType,
+	"admin_option": types.BoolType,
+}}
+
+// readAccessRights reads the privileges and roles granted to a user or role
+func readAccessRights(ctx context.Context, client *clickhouseClient, grantee string) (types.List, types.List, error) {
+	grants, err := readGrants(ctx, client, grantee)
+	if err != nil {
+		return types.List{}, types.List{}, err
+	}
+
+	query := `
+        SELECT granted_role_name, granted_role_is_default, with_admin_option
+        FROM system.role_grants
+        WHERE user_name = ? OR role_name = ?
+        ORDER BY granted_role_name
+    `
+
+	rows, err := client.QueryContext(ctx, query, grantee, grantee)
+	if err != nil {
+		return types.List{}, types.List{}, err
+	}
+	defer rows.Close()
+
+	var roles []roleGrantRow
+	for rows.Next() {
+		var role roleGrantRow
+		var isDefault, adminOption uint8
+		if err := rows.Scan(&role.Role, &isDefault, &adminOption); err != nil {
+			return types.List{}, types.List{}, err
+		}
+		role.Default, role.AdminOption = isDefault != 0, adminOption != 0
+		roles = append(roles, role)
+	}
+
+	return grantList(grants), roleGrantList(roles), rows.Err()
+}
+
+// roleGrantList converts the rows of system.role_grants into the granted_roles attribute of a user or role
+func roleGrantList(roles []roleGrantRow) types.List {
+	values := make([]attr.Value, 0, len(roles))
+	for _, role := range roles {
+		values = append(values, types.ObjectValueMust(roleGrantObjectType.AttrTypes, map[string]attr.Value{
+			"role":         types.StringValue(role.Role),
+			"default":      types.BoolValue(role.Default),
+			"admin_option": types.BoolValue(role.AdminOption),
+		}))
+	}
+	return types.ListValueMust(roleGrantObjectType, values)
+}
+
+// readSettingsProfileElements reads the setting values and inherited profile
+// assigned to a user, role or profile, identified by the given owner column
+func readSettingsProfileElements(ctx context.Context, client *clickhouseClient, ownerColumn, owner string) (map[string]string, string, error) {
+	query := fmt.Sprintf(`
+        SELECT setting_name, value, inherit_profile
+        FROM system.settings_profile_elements
+        WHERE %s = ?
+        ORDER BY index
+    `, ownerColumn)
+
+	rows, err := client.QueryContext(ctx, query, owner)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	settings := map[string]string{}
+	profile := ""
+	for rows.Next() {
+		var setting, value, inherit sql.NullString
+		if err := rows.Scan(&setting, &value, &inherit); err != nil {
+			return nil, "", err
+		}
+		if inherit.Valid {
+			profile = inherit.String
+		}
+		if setting.Valid && value.Valid {
+			settings[setting.String] = value.String
+		}
+	}
+
+	return settings, profile, rows.Err()
+}
+
+// roleSettingsClause renders the settings profile and setting values of a role
+func roleSettingsClause(data RoleResourceModel) string {
+	var elements []string
+	if !data.SettingsProfile.IsNull() {
+		elements = append(elements, "PROFILE "+quoteString(data.SettingsProfile.ValueString()))
+	}
+	for _, name := range sortedKeys(data.Settings) {
+		elements = append(elements, fmt.Sprintf("%s = %s", name, settingValueSQL(data.Settings[name].ValueString())))
+	}
+	return strings.Join(elements, ", ")
+}
+
+// settingValueSQL renders a setting value, quoting anything that is not a number
+func settingValueSQL(value string) string {
+	if numericLiteral.MatchString(value) {
+		return value
+	}
+	return quoteString(value)
+}
+
+// onCluster renders the ON CLUSTER clause of a statement when a cluster is set
+func onCluster(cluster string) string {
+	if cluster == "" {
+		return ""
+	}
+	return " ON CLUSTER " + quoteIdentifier(cluster)
+}
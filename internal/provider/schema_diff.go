@@ -0,0 +1,1080 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// tableDefinition is the normalized description of a table used when
+// comparing schemas and generating DDL.
+type tableDefinition struct {
+	Name    string       `json:"name"`
+	Engine  string       `json:"engine"`
+	Columns []ColumnInfo `json:"columns"`
+	OrderBy []string     `json:"order_by,omitempty"`
+
+	PrimaryKey []string `json:"primary_key,omitempty"`
+	SampleBy   string   `json:"sample_by,omitempty"`
+
+	Settings map[string]string `json:"settings,omitempty"`
+	Comment  string            `json:"comment,omitempty"`
+	Indexes  []IndexInfo       `json:"indexes,omitempty"`
+
+	Projections []ProjectionInfo `json:"projections,omitempty"`
+	Constraints []ConstraintInfo `json:"constraints,omitempty"`
+
+	// EngineSettings are the settings of engines configured in the SETTINGS clause, such as Kafka.
+	// SecretSettings are rendered too but left out of the fingerprint.
+	EngineSettings map[string]string `json:"engine_settings,omitempty"`
+	SecretSettings map[string]string `json:"-"`
+
+	// SecretEngine is the engine clause with its credentials, rendered instead of Engine when set
+	SecretEngine string `json:"-"`
+
+	// AsSelect is the query the table is created from, inferring the columns not listed in Columns
+	AsSelect string `json:"as_select,omitempty"`
+
+	// AsTable is the table whose structure is cloned instead of listing Columns
+	AsTable string `json:"as_table,omitempty"`
+
+	// IfNotExists keeps an existing table of the same name instead of failing on creation
+	IfNotExists bool   `json:"-"`
+	Cluster     string `json:"-"`
+}
+
+// viewDefinition is the normalized description of a (materialized or window) view.
+type viewDefinition struct {
+	Name         string `json:"name"`
+	Query        string `json:"query"`
+	Materialized bool   `json:"materialized,omitempty"`
+	To           string `json:"to,omitempty"`
+
+	// Window views aggregate their source by time window, closing windows as the watermark passes them
+	Window          bool   `json:"window,omitempty"`
+	Watermark       string `json:"watermark,omitempty"`
+	AllowedLateness string `json:"allowed_lateness,omitempty"`
+
+	// Refreshable materialized views run their query on a schedule instead of on insert
+	Refresh   string   `json:"refresh,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	Append    bool     `json:"append,omitempty"`
+
+	// Populate backfills the view with the existing rows of its source when it is created
+	Populate bool `json:"-"`
+
+	// SQLSecurity chooses whose privileges the query of the view runs with, the definer's or the caller's
+	SQLSecurity string `json:"sql_security,omitempty"`
+	Definer     string `json:"definer,omitempty"`
+}
+
+// databaseDefinition holds every table and view of a single database.
+type databaseDefinition struct {
+	Tables map[string]tableDefinition `json:"tables"`
+	Views  map[string]viewDefinition  `json:"views"`
+
+	// Cluster is the cluster the statements reaching this definition run on with ON CLUSTER
+	Cluster string `json:"-"`
+}
+
+// schemaChange describes the statements needed to bring a single object
+// from its current definition to the desired one.
+type schemaChange struct {
+	Object     string
+	Kind       string
+	Action     string
+	Statements []string
+
+	// Hint explains how to proceed when the statements fail
+	Hint string
+}
+
+const (
+	schemaActionCreate  = "create"
+	schemaActionAlter   = "alter"
+	schemaActionReplace = "replace"
+	schemaActionDrop    = "drop"
+
+	schemaKindTable = "table"
+	schemaKindView  = "view"
+)
+
+var materializedViewToPattern = regexp.MustCompile(`(?i)^CREATE MATERIALIZED VIEW \S+(?: UUID '[^']*')?(?: REFRESH .*?)? TO (\S+)`)
+
+// refreshPattern matches the REFRESH clause of a refreshable materialized view, up to the clause following it
+var refreshPattern = regexp.MustCompile(`(?is) REFRESH (.+?)(?: DEPENDS ON (.+?))?(?: SETTINGS .+?)?( APPEND)?` +
+	`(?: TO | ENGINE | EMPTY| DEFINER | SQL SECURITY | \(|$)`)
+
+// windowViewPattern matches the clauses of a CREATE WINDOW VIEW statement preceding its query
+var windowViewPattern = regexp.MustCompile(`(?is)^CREATE WINDOW VIEW \S+(?: UUID '[^']*')?(?: TO (\S+))?.*?` +
+	`(?: WATERMARK = (.+?))?(?: ALLOWED_LATENESS = (.+?))?(?: POPULATE)?$`)
+
+// viewDefinerPattern and viewSQLSecurityPattern match the security clauses of a CREATE VIEW statement
+var (
+	viewDefinerPattern     = regexp.MustCompile("(?i) DEFINER = (`[^`]+`|\\S+)")
+	viewSQLSecurityPattern = regexp.MustCompile(`(?i) SQL SECURITY (DEFINER|INVOKER|NONE)\b`)
+)
+
+// timeWindowPattern matches the time window functions a window view groups its rows by
+var timeWindowPattern = regexp.MustCompile(`(?i)\b(tumble|hop)\s*\(`)
+
+// fingerprint returns a stable hash of the table definition, ignoring the table name
+func (t tableDefinition) fingerprint() string {
+	t.Name = ""
+	encoded, _ := json.Marshal(t)
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:])
+}
+
+// readDatabaseDefinition reads every table and view of a database from ClickHouse
+func readDatabaseDefinition(ctx context.Context, client *clickhouseClient, database string) (databaseDefinition, error) {
+	def := databaseDefinition{
+		Tables: map[string]tableDefinition{},
+		Views:  map[string]viewDefinition{},
+	}
+	ttls := map[string]map[string]string{}
+
+	query := `
+        SELECT name, engine, engine_full, sorting_key, as_select, create_table_query
+        FROM system.tables
+        WHERE database = ? AND is_temporary = 0 AND NOT startsWith(name, '.inner')
+    `
+
+	rows, err := client.QueryContext(ctx, query, database)
+	if err != nil {
+		return def, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, engine, engineFull, sortingKey, asSelect, createQuery string
+		if err := rows.Scan(&name, &engine, &engineFull, &sortingKey, &asSelect, &createQuery); err != nil {
+			return def, err
+		}
+
+		switch engine {
+		case "View":
+			view := viewDefinition{Name: name, Query: asSelect}
+			view.SQLSecurity, view.Definer = viewSecurity(createQuery)
+			def.Views[name] = view
+		case "MaterializedView":
+			view := viewDefinition{Name: name, Query: asSelect, Materialized: true}
+			if match := materializedViewToPattern.FindStringSubmatch(createQuery); match != nil {
+				view.To = match[1]
+			}
+			view.Refresh, view.DependsOn, view.Append = refreshClause(createQuery)
+			view.SQLSecurity, view.Definer = viewSecurity(createQuery)
+			def.Views[name] = view
+		case "WindowView":
+			def.Views[name] = windowViewDefinition(name, asSelect, createQuery)
+		default:
+			// The engine keeps its parameters, e.g. the version column of a ReplacingMergeTree
+			if engineFull != "" {
+				engine = engineClause(engineFull)
+			}
+			def.Tables[name] = tableDefinition{
+				Name:    name,
+				Engine:  engine,
+				OrderBy: parseSortingKey(sortingKey),
+			}
+			ttls[name] = columnTTLs(createQuery)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return def, err
+	}
+
+	columnsQuery := `
+        SELECT table, name, type, comment, default_kind, default_expression, compression_codec
+        FROM system.columns
+        WHERE database = ?
+        ORDER BY table, position
+    `
+
+	columnRows, err := client.QueryContext(ctx, columnsQuery, database)
+	if err != nil {
+		return def, err
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var table, name, colType string
+		var comment, defaultKind, defaultExpr, codec sql.NullString
+		if err := columnRows.Scan(&table, &name, &colType, &comment, &defaultKind, &defaultExpr, &codec); err != nil {
+			return def, err
+		}
+
+		t, ok := def.Tables[table]
+		if !ok {
+			continue
+		}
+		t.Columns = append(t.Columns, ColumnInfo{
+			Name:    name,
+			Type:    colType,
+			Comment: comment.String,
+			Codec:   codec.String,
+			TTL:     ttls[table][name],
+
+			DefaultKind:       defaultKind.String,
+			DefaultExpression: defaultExpression(defaultKind.String, defaultExpr.String),
+		})
+		def.Tables[table] = t
+	}
+
+	return def, columnRows.Err()
+}
+
+// readColumnTypes reads the types of the columns of a table, keyed by column name.
+// A table that does not exist has no columns.
+func readColumnTypes(ctx context.Context, client *clickhouseClient, database, table string) (map[string]string, error) {
+	rows, err := client.QueryContext(ctx, "SELECT name, type FROM system.columns WHERE database = ? AND table = ?", database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := map[string]string{}
+	for rows.Next() {
+		var name, columnType string
+		if err := rows.Scan(&name, &columnType); err != nil {
+			return nil, err
+		}
+		columns[name] = columnType
+	}
+
+	return columns, rows.Err()
+}
+
+// queryOutputColumns returns the columns returned by a SELECT query, without reading any row
+func queryOutputColumns(ctx context.Context, client *clickhouseClient, query string) ([]ColumnInfo, error) {
+	rows, err := client.QueryContext(ctx, fmt.Sprintf("SELECT * FROM (%s) LIMIT 0", query))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]ColumnInfo, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = ColumnInfo{Name: columnType.Name(), Type: columnType.DatabaseTypeName()}
+	}
+	return columns, rows.Err()
+}
+
+// diffDatabaseDefinitions computes the changes needed to turn current into desired.
+// Changes are ordered so that tables are created before the views reading
+// from them and views are dropped before the tables they depend on.
+func diffDatabaseDefinitions(ctx context.Context, client *clickhouseClient, database string, current, desired databaseDefinition) []schemaChange {
+	var viewDrops, tableChanges, viewChanges, tableDrops []schemaChange
+	cluster := desired.Cluster
+
+	for _, name := range sortedKeys(current.Views) {
+		if _, ok := desired.Views[name]; !ok {
+			viewDrops = append(viewDrops, schemaChange{
+				Object:     name,
+				Kind:       schemaKindView,
+				Action:     schemaActionDrop,
+				Statements: []string{viewDropStatement(database, current.Views[name], cluster)},
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(desired.Tables) {
+		want := desired.Tables[name]
+		want.Cluster = cluster
+		have, exists := current.Tables[name]
+		if !exists {
+			tableChanges = append(tableChanges, schemaChange{
+				Object:     name,
+				Kind:       schemaKindTable,
+				Action:     schemaActionCreate,
+				Statements: []string{tableCreateStatement(database, want)},
+			})
+			continue
+		}
+
+		if !enginesEquivalent(ctx, client, database, name, want.Engine, have.Engine) || !sortingKeysEquivalent(have.OrderBy, want.OrderBy) {
+			tableChanges = append(tableChanges, schemaChange{
+				Object: name,
+				Kind:   schemaKindTable,
+				Action: schemaActionReplace,
+				Statements: []string{
+					"DROP TABLE IF EXISTS " + qualifiedName(database, name) + onCluster(cluster),
+					tableCreateStatement(database, want),
+				},
+			})
+			continue
+		}
+
+		columns := columnsWithDesiredSpelling(ctx, client, have.Columns, want.Columns)
+		if statements := columnAlterStatements(qualifiedName(database, name)+onCluster(cluster), columns, want.Columns); len(statements) > 0 {
+			tableChanges = append(tableChanges, schemaChange{
+				Object:     name,
+				Kind:       schemaKindTable,
+				Action:     schemaActionAlter,
+				Statements: statements,
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(desired.Views) {
+		want := desired.Views[name]
+		have, exists := current.Views[name]
+		// The server reports the target qualified with its database, whatever the configured spelling
+		if want.To != "" {
+			want.To = qualifiedReference(database, want.To)
+		}
+		if have.To != "" {
+			have.To = qualifiedReference(database, have.To)
+		}
+		want.DependsOn = qualifiedReferences(database, want.DependsOn)
+		have.DependsOn = qualifiedReferences(database, have.DependsOn)
+		// The server reports view queries reformatted
+		if exists && queriesEquivalent(ctx, client, have.Query, want.Query) {
+			have.Query = want.Query
+		}
+		// Security left to the server defaults is not compared
+		if want.SQLSecurity == "" {
+			have.SQLSecurity, have.Definer = "", ""
+		}
+		if exists && viewsEqual(have, want) {
+			continue
+		}
+
+		// The SQL security of a view can be changed in place
+		if exists && viewsEqual(have.withSecurity(want.SQLSecurity, want.Definer), want) {
+			viewChanges = append(viewChanges, schemaChange{
+				Object:     name,
+				Kind:       schemaKindView,
+				Action:     schemaActionAlter,
+				Statements: []string{fmt.Sprintf("ALTER TABLE %s%s MODIFY %s", qualifiedName(database, name), onCluster(cluster), want.securitySQL())},
+			})
+			continue
+		}
+
+		// The query of a materialized view is changed in place, keeping the rows it holds
+		if exists && have.Materialized && want.Materialized && viewsEqual(have.withQuery(want.Query), want) {
+			viewChanges = append(viewChanges, schemaChange{
+				Object:     name,
+				Kind:       schemaKindView,
+				Action:     schemaActionAlter,
+				Statements: []string{fmt.Sprintf("ALTER TABLE %s%s MODIFY QUERY %s", qualifiedName(database, name), onCluster(cluster), want.Query)},
+				Hint: "The materialized view is not recreated automatically, as the rows it holds would be lost. " +
+					"Remove it from the configuration and add it back to recreate it.",
+			})
+			continue
+		}
+
+		// The schedule of a refreshable materialized view can be changed in place
+		if exists && have.Refresh != "" && want.Refresh != "" && viewsEqual(have.withoutSchedule(), want.withoutSchedule()) {
+			viewChanges = append(viewChanges, schemaChange{
+				Object: name,
+				Kind:   schemaKindView,
+				Action: schemaActionAlter,
+				Statements: []string{
+					fmt.Sprintf("ALTER TABLE %s%s MODIFY REFRESH %s", qualifiedName(database, name), onCluster(cluster), want.scheduleSQL()),
+				},
+			})
+			continue
+		}
+
+		// Plain views are replaced atomically, so queries reading them never find them missing
+		if exists && have.plain() && want.plain() {
+			viewChanges = append(viewChanges, schemaChange{
+				Object:     name,
+				Kind:       schemaKindView,
+				Action:     schemaActionReplace,
+				Statements: []string{viewReplaceStatement(database, want, cluster)},
+			})
+			continue
+		}
+
+		change := schemaChange{
+			Object: name,
+			Kind:   schemaKindView,
+			Action: schemaActionCreate,
+		}
+		if exists {
+			change.Action = schemaActionReplace
+			change.Statements = append(change.Statements, viewDropStatement(database, have, cluster))
+		}
+		change.Statements = append(change.Statements, viewCreateStatement(database, want, cluster))
+		viewChanges = append(viewChanges, change)
+	}
+
+	for _, name := range sortedKeys(current.Tables) {
+		if _, ok := desired.Tables[name]; !ok {
+			tableDrops = append(tableDrops, schemaChange{
+				Object:     name,
+				Kind:       schemaKindTable,
+				Action:     schemaActionDrop,
+				Statements: []string{"DROP TABLE IF EXISTS " + qualifiedName(database, name) + onCluster(cluster)},
+			})
+		}
+	}
+
+	changes := append(viewDrops, tableChanges...)
+	changes = append(changes, viewChanges...)
+	return append(changes, tableDrops...)
+}
+
+// columnsWithDesiredSpelling returns the current columns with the expressions the server reformatted, such as
+// ts + INTERVAL 30 DAY reported as ts + toIntervalDay(30), in the spelling of the equivalent desired ones
+func columnsWithDesiredSpelling(ctx context.Context, client *clickhouseClient, current, desired []ColumnInfo) []ColumnInfo {
+	wanted := make(map[string]ColumnInfo, len(desired))
+	for _, col := range desired {
+		wanted[col.Name] = col
+	}
+	// Renamed columns are compared with the column they are renamed from
+	for _, col := range desired {
+		if _, ok := wanted[col.PreviousName]; col.PreviousName != "" && !ok {
+			wanted[col.PreviousName] = col
+		}
+	}
+
+	columns := make([]ColumnInfo, len(current))
+	for i, col := range current {
+		if want, ok := wanted[col.Name]; ok {
+			if col.DefaultKind == want.DefaultKind && expressionsEquivalent(ctx, client, want.DefaultExpression, col.DefaultExpression) {
+				col.DefaultExpression = want.DefaultExpression
+			}
+			if expressionsEquivalent(ctx, client, want.TTL, col.TTL) {
+				col.TTL = want.TTL
+			}
+		}
+		columns[i] = col
+	}
+	return columns
+}
+
+// columnAlterStatements generates the ALTER TABLE statements turning the current column list into the desired one
+func columnAlterStatements(target string, current, desired []ColumnInfo) []string {
+	existing := make(map[string]ColumnInfo, len(current))
+	for _, col := range current {
+		existing[col.Name] = col
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	for _, col := range desired {
+		wanted[col.Name] = true
+	}
+
+	// Columns renamed from a column that still exists keep their data; the rest of the
+	// definition is then compared with the renamed column
+	var statements []string
+	renamed := map[string]bool{}
+	for _, col := range desired {
+		have, ok := existing[col.PreviousName]
+		if col.PreviousName == "" || !ok || wanted[col.PreviousName] {
+			continue
+		}
+		if _, exists := existing[col.Name]; exists {
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", target, quoteIdentifier(col.PreviousName), quoteIdentifier(col.Name)))
+		renamed[col.PreviousName] = true
+		delete(existing, col.PreviousName)
+		have.Name = col.Name
+		existing[col.Name] = have
+	}
+
+	for _, col := range desired {
+		have, ok := existing[col.Name]
+		if !ok {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", target, columnDefinitionSQL(col)))
+			continue
+		}
+
+		if !typesEquivalent(have.Type, col.Type) {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", target, columnDefinitionSQL(col)))
+			// MODIFY COLUMN keeps the existing comment when the new definition has none
+			if col.Comment == "" && have.Comment != "" {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s COMMENT COLUMN %s ''", target, quoteIdentifier(col.Name)))
+			}
+			continue
+		}
+
+		if have.DefaultKind != col.DefaultKind || normalizeQuery(have.DefaultExpression) != normalizeQuery(col.DefaultExpression) {
+			if col.DefaultKind == "" {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s REMOVE %s", target, quoteIdentifier(col.Name), have.DefaultKind))
+			} else {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", target, quoteIdentifier(col.Name), defaultClauseSQL(col)))
+			}
+		}
+
+		if !codecsEquivalent(col.Codec, have.Codec) {
+			if col.Codec == "" {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s REMOVE CODEC", target, quoteIdentifier(col.Name)))
+			} else {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", target, quoteIdentifier(col.Name), codecSQL(col.Codec)))
+			}
+		}
+
+		if normalizeQuery(have.TTL) != normalizeQuery(col.TTL) {
+			if col.TTL == "" {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s REMOVE TTL", target, quoteIdentifier(col.Name)))
+			} else {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s TTL %s", target, quoteIdentifier(col.Name), col.TTL))
+			}
+		}
+
+		if have.Comment != col.Comment {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s COMMENT COLUMN %s %s", target, quoteIdentifier(col.Name), quoteString(col.Comment)))
+		}
+	}
+
+	for _, col := range current {
+		if !wanted[col.Name] && !renamed[col.Name] {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", target, quoteIdentifier(col.Name)))
+		}
+	}
+
+	return statements
+}
+
+// columnDefinitionSQL renders a single column definition
+func columnDefinitionSQL(col ColumnInfo) string {
+	definition := fmt.Sprintf("%s %s", quoteIdentifier(col.Name), col.Type)
+	if col.DefaultKind != "" {
+		definition += " " + defaultClauseSQL(col)
+	}
+	if col.Comment != "" {
+		definition += " COMMENT " + quoteString(col.Comment)
+	}
+	if col.Codec != "" {
+		definition += " " + codecSQL(col.Codec)
+	}
+	if col.TTL != "" {
+		definition += fmt.Sprintf(" TTL %s", col.TTL)
+	}
+	return definition
+}
+
+// tableCreateStatement generates the CREATE TABLE statement for a table definition
+func tableCreateStatement(database string, table tableDefinition) string {
+	columns := make([]string, 0, len(table.Columns)+len(table.Indexes)+len(table.Projections)+len(table.Constraints))
+	for _, col := range table.Columns {
+		columns = append(columns, "    "+columnDefinitionSQL(col))
+	}
+	for _, index := range table.Indexes {
+		columns = append(columns, "    "+indexDefinitionSQL(index))
+	}
+	for _, projection := range table.Projections {
+		columns = append(columns, "    "+projectionDefinitionSQL(projection))
+	}
+	for _, constraint := range table.Constraints {
+		columns = append(columns, "    "+constraintDefinitionSQL(constraint))
+	}
+
+	engine := table.Engine
+	if table.SecretEngine != "" {
+		engine = table.SecretEngine
+	}
+	statement := "CREATE TABLE " + qualifiedName(database, table.Name)
+	if table.IfNotExists {
+		statement = "CREATE TABLE IF NOT EXISTS " + qualifiedName(database, table.Name)
+	}
+	statement += onCluster(table.Cluster)
+	if table.AsTable != "" {
+		statement += " AS " + quoteTableReference(table.AsTable)
+	} else if len(columns) > 0 {
+		statement += fmt.Sprintf(" (\n%s\n)", strings.Join(columns, ",\n"))
+	}
+	statement += " ENGINE = " + engine
+
+	engineName, _, _ := strings.Cut(table.Engine, "(")
+	switch {
+	case len(table.OrderBy) > 0:
+		statement += fmt.Sprintf("\nORDER BY (%s)", strings.Join(table.OrderBy, ", "))
+	case len(table.PrimaryKey) == 0 && table.AsTable == "" && isMergeTreeFamily(strings.TrimSpace(engineName)):
+		// An order_by of tuple() leaves the rows unsorted, which MergeTree engines still require to be explicit
+		statement += "\nORDER BY tuple()"
+	}
+	if len(table.PrimaryKey) > 0 {
+		statement += fmt.Sprintf("\nPRIMARY KEY (%s)", strings.Join(table.PrimaryKey, ", "))
+	}
+	if table.SampleBy != "" {
+		statement += fmt.Sprintf("\nSAMPLE BY %s", table.SampleBy)
+	}
+	settings := map[string]string{}
+	for _, group := range []map[string]string{table.EngineSettings, table.SecretSettings, table.Settings} {
+		for name, value := range group {
+			settings[name] = value
+		}
+	}
+	if len(settings) > 0 {
+		statement += "\nSETTINGS " + settingAssignments(settings)
+	}
+	if table.AsSelect != "" {
+		statement += "\nAS " + table.AsSelect
+	}
+	if table.Comment != "" {
+		statement += "\nCOMMENT " + quoteString(table.Comment)
+	}
+
+	return statement
+}
+
+// engineClause returns the engine of an engine_full value with its parameters, without the clauses following it
+func engineClause(engineFull string) string {
+	name, parameters := parseEngine(engineFull)
+	if len(parameters) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(parameters, ", "))
+}
+
+// enginesEquivalent reports whether a table created with the configured engine clause reports the actual one.
+// Parameters are compared by meaning, as the server rewrites them and substitutes the macros of Keeper paths.
+func enginesEquivalent(ctx context.Context, client *clickhouseClient, database, table, configured, actual string) bool {
+	configuredName, _ := parseEngine(configured)
+	actualName, actualParameters := parseEngine(actual)
+	return sameEngine(configuredName, actualName) &&
+		engineParametersMatch(ctx, client, engineModel(configured), actualName, database, table, actualParameters)
+}
+
+// windowViewDefinition builds the definition of a window view from its CREATE statement
+func windowViewDefinition(name, asSelect, createQuery string) viewDefinition {
+	view := viewDefinition{Name: name, Query: asSelect, Window: true}
+
+	header := createQuery
+	if end := topLevelIndex(createQuery, " AS "); end >= 0 {
+		header = createQuery[:end]
+		if view.Query == "" {
+			view.Query = strings.TrimSpace(createQuery[end+len(" AS "):])
+		}
+	}
+	if match := windowViewPattern.FindStringSubmatch(header); match != nil {
+		view.To, view.Watermark, view.AllowedLateness = match[1], match[2], match[3]
+	}
+
+	return view
+}
+
+// qualifiedReference returns a table reference written as table or database.table, possibly quoted,
+// as an unquoted database.table name. References without a database refer to a table of database.
+func qualifiedReference(database, reference string) string {
+	var parts []string
+	var part strings.Builder
+	var quote byte
+	for i := 0; i < len(reference); i++ {
+		c := reference[i]
+		switch {
+		case quote != 0 && c == '\\' && i+1 < len(reference):
+			i++
+			part.WriteByte(reference[i])
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			part.WriteByte(c)
+		case c == '`' || c == '"':
+			quote = c
+		case c == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	parts = append(parts, part.String())
+
+	if len(parts) == 1 {
+		return database + "." + parts[0]
+	}
+	return strings.Join(parts, ".")
+}
+
+// qualifiedReferences qualifies each of the references with the database when they do not name one
+func qualifiedReferences(database string, references []string) []string {
+	if references == nil {
+		return nil
+	}
+	qualified := make([]string, len(references))
+	for i, reference := range references {
+		qualified[i] = qualifiedReference(database, reference)
+	}
+	return qualified
+}
+
+// retargetDefinition points the views of a definition read from one database at
+// another, so objects of the source database referenced by view queries, TO
+// targets and refresh dependencies resolve to their copies in the target database
+func retargetDefinition(def databaseDefinition, from, to string) databaseDefinition {
+	views := make(map[string]viewDefinition, len(def.Views))
+	for name, view := range def.Views {
+		view.Query = retargetQuery(view.Query, from, to)
+		if view.To != "" {
+			view.To = retargetReference(view.To, from, to)
+		}
+		if view.DependsOn != nil {
+			dependencies := make([]string, len(view.DependsOn))
+			for i, dependency := range view.DependsOn {
+				dependencies[i] = retargetReference(dependency, from, to)
+			}
+			view.DependsOn = dependencies
+		}
+		views[name] = view
+	}
+	def.Views = views
+	return def
+}
+
+// retargetReference rewrites a table reference naming the database from to name the database to
+func retargetReference(reference, from, to string) string {
+	if name, ok := strings.CutPrefix(qualifiedReference(from, reference), from+"."); ok {
+		return qualifiedName(to, name)
+	}
+	return reference
+}
+
+// retargetQuery rewrites the database qualifiers naming the database from in a query,
+// leaving string literals, column references and other databases untouched
+func retargetQuery(query, from, to string) string {
+	var out strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			end := closingQuote(query, i)
+			out.WriteString(query[i:end])
+			i = end
+		case c == '`' || c == '"' || c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			if c == '`' || c == '"' {
+				end = closingQuote(query, i)
+			} else {
+				for end < len(query) && (query[end] == '_' || unicode.IsLetter(rune(query[end])) || unicode.IsDigit(rune(query[end]))) {
+					end++
+				}
+			}
+			qualifier := i == 0 || query[i-1] != '.'
+			switch {
+			case !qualifier || end >= len(query) || query[end] != '.' || strings.Trim(query[i:end], "`\"") != from:
+				out.WriteString(query[i:end])
+			case c != '`' && c != '"' && plainIdentifierPattern.MatchString(to):
+				out.WriteString(to)
+			default:
+				out.WriteString(quoteIdentifier(to))
+			}
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// closingQuote returns the offset just past the quoted string or identifier starting at start
+func closingQuote(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// viewsEqual reports whether two view definitions only differ by formatting
+func viewsEqual(a, b viewDefinition) bool {
+	return a.Materialized == b.Materialized && a.To == b.To && a.Window == b.Window &&
+		normalizeQuery(a.Watermark) == normalizeQuery(b.Watermark) &&
+		normalizeQuery(a.AllowedLateness) == normalizeQuery(b.AllowedLateness) &&
+		strings.EqualFold(normalizeQuery(a.Refresh), normalizeQuery(b.Refresh)) &&
+		strings.EqualFold(a.SQLSecurity, b.SQLSecurity) && strings.Trim(a.Definer, "`") == strings.Trim(b.Definer, "`") &&
+		equalStrings(a.DependsOn, b.DependsOn) && a.Append == b.Append &&
+		normalizeQuery(a.Query) == normalizeQuery(b.Query)
+}
+
+// plain reports whether the view is a plain view, neither materialized nor a window view
+func (v viewDefinition) plain() bool {
+	return !v.Materialized && !v.Window
+}
+
+// withSecurity returns the view definition with another SQL security and definer
+func (v viewDefinition) withSecurity(sqlSecurity, definer string) viewDefinition {
+	v.SQLSecurity, v.Definer = sqlSecurity, definer
+	return v
+}
+
+// securitySQL renders the SQL SECURITY clause of a view, followed by its definer
+func (v viewDefinition) securitySQL() string {
+	clause := "SQL SECURITY " + v.SQLSecurity
+	if v.Definer != "" {
+		clause += " DEFINER = " + definerSQL(v.Definer)
+	}
+	return clause
+}
+
+// definerSQL renders the definer of a view, a user name or CURRENT_USER
+func definerSQL(definer string) string {
+	if strings.EqualFold(definer, "CURRENT_USER") {
+		return definer
+	}
+	return quoteIdentifier(definer)
+}
+
+// viewSecurity extracts the SQL security and definer from the CREATE statement of a view
+func viewSecurity(createQuery string) (string, string) {
+	header := createQuery
+	if end := topLevelIndex(createQuery, " AS "); end >= 0 {
+		header = createQuery[:end]
+	}
+
+	var sqlSecurity, definer string
+	if match := viewSQLSecurityPattern.FindStringSubmatch(header); match != nil {
+		sqlSecurity = strings.ToUpper(match[1])
+	}
+	if match := viewDefinerPattern.FindStringSubmatch(header); match != nil {
+		definer = match[1]
+	}
+	return sqlSecurity, definer
+}
+
+// withQuery returns the view definition with another query
+func (v viewDefinition) withQuery(query string) viewDefinition {
+	v.Query = query
+	return v
+}
+
+// withoutSchedule returns the view definition without the refresh schedule and dependencies, which can be altered
+func (v viewDefinition) withoutSchedule() viewDefinition {
+	v.Refresh = ""
+	v.DependsOn = nil
+	return v
+}
+
+// scheduleSQL renders the refresh schedule of a refreshable materialized view with its dependencies
+func (v viewDefinition) scheduleSQL() string {
+	schedule := v.Refresh
+	if len(v.DependsOn) > 0 {
+		dependencies := make([]string, len(v.DependsOn))
+		for i, dependency := range v.DependsOn {
+			dependencies[i] = quoteTableReference(dependency)
+		}
+		schedule += " DEPENDS ON " + strings.Join(dependencies, ", ")
+	}
+	return schedule
+}
+
+// refreshClause extracts the refresh schedule, dependencies and APPEND mode from the CREATE statement
+// of a materialized view. Views refreshed on insert have no schedule.
+func refreshClause(createQuery string) (string, []string, bool) {
+	header := createQuery
+	if end := topLevelIndex(createQuery, " AS "); end >= 0 {
+		header = createQuery[:end]
+	}
+
+	match := refreshPattern.FindStringSubmatch(header)
+	if match == nil {
+		return "", nil, false
+	}
+
+	var dependsOn []string
+	if match[2] != "" {
+		for _, dependency := range strings.Split(match[2], ",") {
+			dependsOn = append(dependsOn, strings.TrimSpace(dependency))
+		}
+	}
+	return strings.TrimSpace(match[1]), dependsOn, match[3] != ""
+}
+
+// viewDropStatement generates the statement dropping a view. Window views are dropped as tables.
+func viewDropStatement(database string, view viewDefinition, cluster string) string {
+	if view.Window {
+		return "DROP TABLE IF EXISTS " + qualifiedName(database, view.Name) + onCluster(cluster)
+	}
+	return "DROP VIEW IF EXISTS " + qualifiedName(database, view.Name) + onCluster(cluster)
+}
+
+// viewReplaceStatement generates the CREATE OR REPLACE VIEW statement replacing a plain view atomically
+func viewReplaceStatement(database string, view viewDefinition, cluster string) string {
+	return "CREATE OR REPLACE" + strings.TrimPrefix(viewCreateStatement(database, view, cluster), "CREATE")
+}
+
+// viewCreateStatement generates the CREATE VIEW statement for a view definition
+func viewCreateStatement(database string, view viewDefinition, cluster string) string {
+	name := qualifiedName(database, view.Name) + onCluster(cluster)
+	if view.Window {
+		statement := "CREATE WINDOW VIEW " + name
+		if view.To != "" {
+			statement += " TO " + quoteTableReference(view.To)
+		}
+		if view.Watermark != "" {
+			statement += fmt.Sprintf(" WATERMARK = %s", view.Watermark)
+		}
+		if view.AllowedLateness != "" {
+			statement += fmt.Sprintf(" ALLOWED_LATENESS = %s", view.AllowedLateness)
+		}
+		if view.Populate {
+			statement += " POPULATE"
+		}
+		return statement + fmt.Sprintf(" AS %s", view.Query)
+	}
+
+	security := ""
+	if view.Definer != "" {
+		security += " DEFINER = " + definerSQL(view.Definer)
+	}
+	if view.SQLSecurity != "" {
+		security += " SQL SECURITY " + view.SQLSecurity
+	}
+
+	if !view.Materialized {
+		return fmt.Sprintf("CREATE VIEW %s%s AS %s", name, security, view.Query)
+	}
+
+	statement := "CREATE MATERIALIZED VIEW " + name
+	if view.Refresh != "" {
+		statement += " REFRESH " + view.scheduleSQL()
+		if view.Append {
+			statement += " APPEND"
+		}
+	}
+	if view.To != "" {
+		statement += " TO " + quoteTableReference(view.To)
+	}
+	if view.Populate {
+		statement += " POPULATE"
+	}
+	return statement + fmt.Sprintf("%s AS %s", security, view.Query)
+}
+
+// queriesEquivalent checks whether two SELECT queries are the same once formatted by the server.
+// Servers without formatQuery support are assumed equivalent to avoid perpetual diffs.
+func queriesEquivalent(ctx context.Context, client *clickhouseClient, a, b string) bool {
+	if normalizeQuery(a) == normalizeQuery(b) {
+		return true
+	}
+	// Without a connection only formatting differences are ignored
+	if client == nil {
+		return false
+	}
+
+	var equal bool
+	err := client.QueryRowContext(ctx, "SELECT formatQuerySingleLine(?) = formatQuerySingleLine(?)", a, b).Scan(&equal)
+	if err != nil {
+		tflog.Warn(ctx, "Could not compare queries using formatQuerySingleLine", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return true
+	}
+
+	return equal
+}
+
+// normalizeQuery collapses whitespace so that formatting differences are ignored
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// parseSortingKey splits a sorting key as reported by system.tables, or written as a tuple, into its
+// expressions. Commas inside function calls and string literals do not split the key.
+func parseSortingKey(sortingKey string) []string {
+	orderBy := strings.TrimSpace(sortingKey)
+	if enclosedInParentheses(orderBy) {
+		orderBy = strings.TrimSpace(orderBy[1 : len(orderBy)-1])
+	}
+	if orderBy == "" || orderBy == "tuple()" {
+		return []string{}
+	}
+
+	expressions := splitTopLevel(orderBy + ")")
+	for i, expression := range expressions {
+		expressions[i] = strings.TrimSpace(expression)
+	}
+
+	return expressions
+}
+
+// enclosedInParentheses reports whether the parenthesis opening an expression closes at its end
+func enclosedInParentheses(expression string) bool {
+	if !strings.HasPrefix(expression, "(") || !strings.HasSuffix(expression, ")") {
+		return false
+	}
+	closing, depth := -1, 0
+	scanSQL(expression, func(i int, c byte) bool {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				closing = i
+				return false
+			}
+		}
+		return true
+	})
+	return closing == len(expression)-1
+}
+
+// sortingKeysEquivalent compares sorting key expressions, ignoring the whitespace the server adds
+// or removes when it formats them
+func sortingKeysEquivalent(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if expressionWithoutSpaces(a[i]) != expressionWithoutSpaces(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// expressionWithoutSpaces removes the whitespace outside of the quoted parts of an expression
+func expressionWithoutSpaces(expression string) string {
+	spaces := map[int]bool{}
+	scanSQL(expression, func(i int, c byte) bool {
+		if strings.IndexByte(" \t\n\r", c) >= 0 {
+			spaces[i] = true
+		}
+		return true
+	})
+
+	var out strings.Builder
+	for i := 0; i < len(expression); i++ {
+		if !spaces[i] {
+			out.WriteByte(expression[i])
+		}
+	}
+	return out.String()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &SchemaDiffDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &SchemaDiffDataSource{}
+
+func NewSchemaDiffDataSource() datasource.DataSource {
+	return &SchemaDiffDataSource{}
+}
+
+// SchemaDiffDataSource compares two database schemas.
+type SchemaDiffDataSource struct {
+	client *clickhouseClient
+}
+
+// SchemaDiffDataSourceModel describes the data source data model.
+type SchemaDiffDataSourceModel struct {
+	ID               types.String        `tfsdk:"id"`
+	Database         types.String        `tfsdk:"database"`
+	SourceDatabase   types.String        `tfsdk:"source_database"`
+	SourceSchemaJSON types.String        `tfsdk:"source_schema_json"`
+	HasDifferences   types.Bool          `tfsdk:"has_differences"`
+	Differences      []SchemaChangeModel `tfsdk:"differences"`
+	Statements       []types.String      `tfsdk:"statements"`
+}
+
+type SchemaChangeModel struct {
+	Object     types.String   `tfsdk:"object"`
+	Kind       types.String   `tfsdk:"kind"`
+	Action     types.String   `tfsdk:"action"`
+	Statements []types.String `tfsdk:"statements"`
+}
+
+func (d *SchemaDiffDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_schema_diff"
+}
+
+func (d *SchemaDiffDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Compares the schema of a database against a source schema and returns the " +
+			"differing objects together with the DDL that reconciles the database with the source. The source " +
+			"is either another database on the same server or the `schema_json` of a `database_schema` data " +
+			"source read through another provider alias.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Schema diff identifier",
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Name of the database to reconcile",
+				Required:            true,
+			},
+			"source_database": schema.StringAttribute{
+				MarkdownDescription: "Name of the source database on the same server. References to the source database in view queries, `TO` targets and refresh dependencies are compared as references to `database`",
+				Optional:            true,
+			},
+			"source_schema_json": schema.StringAttribute{
+				MarkdownDescription: "JSON encoded source schema, as returned by the `database_schema` data source",
+				Optional:            true,
+			},
+			"has_differences": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the database differs from the source",
+			},
+			"differences": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Objects that differ between the database and the source",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"object": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Object name",
+						},
+						"kind": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Object kind (`table` or `view`)",
+						},
+						"action": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Action needed to reconcile the object (`create`, `alter`, `replace` or `drop`)",
+						},
+						"statements": schema.ListAttribute{
+							Computed:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "DDL statements reconciling the object",
+						},
+					},
+				},
+			},
+			"statements": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "All DDL statements needed to reconcile the database, in execution order",
+			},
+		},
+	}
+}
+
+func (d *SchemaDiffDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data SchemaDiffDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SourceDatabase.IsUnknown() || data.SourceSchemaJSON.IsUnknown() {
+		return
+	}
+
+	if data.SourceDatabase.IsNull() == data.SourceSchemaJSON.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("source_database"),
+			"Invalid schema diff source",
+			"Exactly one of source_database or source_schema_json must be set.",
+		)
+	}
+}
+
+func (d *SchemaDiffDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *SchemaDiffDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SchemaDiffDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database := data.Database.ValueString()
+	current, err := readDatabaseDefinition(ctx, d.client, database)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading database schema",
+			fmt.Sprintf("Could not read schema of database %s: %s", database, err.Error()),
+		)
+		return
+	}
+
+	var source databaseDefinition
+	if !data.SourceDatabase.IsNull() {
+		source, err = readDatabaseDefinition(ctx, d.client, data.SourceDatabase.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading database schema",
+				fmt.Sprintf("Could not read schema of database %s: %s", data.SourceDatabase.ValueString(), err.Error()),
+			)
+			return
+		}
+	} else if err := json.Unmarshal([]byte(data.SourceSchemaJSON.ValueString()), &source); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("source_schema_json"),
+			"Invalid source schema",
+			fmt.Sprintf("Could not decode source schema: %s", err.Error()),
+		)
+		return
+	}
+
+	// Object names are taken from the map keys of the encoded schema
+	for name, table := range source.Tables {
+		table.Name = name
+		source.Tables[name] = table
+	}
+	for name, view := range source.Views {
+		view.Name = name
+		source.Views[name] = view
+	}
+
+	// Views of the source database refer to its own objects, which the target database holds under its name
+	if !data.SourceDatabase.IsNull() && data.SourceDatabase.ValueString() != database {
+		source = retargetDefinition(source, data.SourceDatabase.ValueString(), database)
+	}
+
+	changes := diffDatabaseDefinitions(ctx, d.client, database, current, source)
+
+	data.ID = types.StringValue(database)
+	data.HasDifferences = types.BoolValue(len(changes) > 0)
+	data.Differences = []SchemaChangeModel{}
+	data.Statements = []types.String{}
+	for _, change := range changes {
+		model := SchemaChangeModel{
+			Object: types.StringValue(change.Object),
+			Kind:   types.StringValue(change.Kind),
+			Action: types.StringValue(change.Action),
+		}
+		for _, statement := range change.Statements {
+			model.Statements = append(model.Statements, types.StringValue(statement))
+			data.Statements = append(data.Statements, types.StringValue(statement))
+		}
+		data.Differences = append(data.Differences, model)
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
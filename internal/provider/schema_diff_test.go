@@ -0,0 +1,390 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEngineClause(t *testing.T) {
+	tests := map[string]string{
+		"MergeTree ORDER BY id SETTINGS index_granularity = 8192":                      "MergeTree",
+		"ReplacingMergeTree(ver) ORDER BY id SETTINGS index_granularity = 8192":        "ReplacingMergeTree(ver)",
+		"ReplicatedMergeTree('/clickhouse/tables/db/events', '{replica}') ORDER BY id": "ReplicatedMergeTree('/clickhouse/tables/db/events', '{replica}')",
+		"Memory": "Memory",
+	}
+
+	for engineFull, expected := range tests {
+		if actual := engineClause(engineFull); actual != expected {
+			t.Errorf("engineClause(%q) = %q, expected %q", engineFull, actual, expected)
+		}
+	}
+}
+
+func TestDiffDatabaseDefinitionsParameterizedEngines(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		actual     string
+		replace    bool
+	}{
+		{"plain engine", "MergeTree", "MergeTree", false},
+		{"version column", "ReplacingMergeTree(ver)", "ReplacingMergeTree(ver)", false},
+		{"Keeper path macros", "ReplicatedMergeTree('/clickhouse/tables/{database}/{table}', '{replica}')",
+			"ReplicatedMergeTree('/clickhouse/tables/analytics/events', '{replica}')", false},
+		{"server default Keeper path", "ReplicatedReplacingMergeTree(ver)",
+			"ReplicatedReplacingMergeTree('/clickhouse/tables/{uuid}/{shard}', '{replica}', ver)", false},
+		{"ClickHouse Cloud", "ReplacingMergeTree(ver)",
+			"SharedReplacingMergeTree('/clickhouse/tables/{uuid}/{shard}', '{replica}', ver)", false},
+		{"changed version column", "ReplacingMergeTree(ver)", "ReplacingMergeTree(updated_at)", true},
+		{"changed engine", "ReplacingMergeTree(ver)", "MergeTree", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns := []ColumnInfo{{Name: "id", Type: "UInt64"}, {Name: "ver", Type: "UInt64"}}
+			current := databaseDefinition{Tables: map[string]tableDefinition{
+				"events": {Name: "events", Engine: test.actual, Columns: columns, OrderBy: []string{"id"}},
+			}}
+			desired := databaseDefinition{Tables: map[string]tableDefinition{
+				"events": {Name: "events", Engine: test.configured, Columns: columns, OrderBy: []string{"id"}},
+			}}
+
+			changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired)
+			if !test.replace && len(changes) > 0 {
+				t.Fatalf("expected no changes, got %+v", changes)
+			}
+			if test.replace && (len(changes) != 1 || changes[0].Action != schemaActionReplace) {
+				t.Fatalf("expected the table to be replaced, got %+v", changes)
+			}
+		})
+	}
+}
+
+func TestQualifiedReference(t *testing.T) {
+	tests := map[string]string{
+		"events":                   "analytics.events",
+		"analytics.events":         "analytics.events",
+		"`analytics`.`events`":     "analytics.events",
+		"`events`":                 "analytics.events",
+		"other.events":             "other.events",
+		"`my db`.`daily events`":   "my db.daily events",
+		"\"analytics\".\"events\"": "analytics.events",
+	}
+
+	for reference, expected := range tests {
+		if actual := qualifiedReference("analytics", reference); actual != expected {
+			t.Errorf("qualifiedReference(%q) = %q, expected %q", reference, actual, expected)
+		}
+	}
+}
+
+func TestDiffDatabaseDefinitionsViewTarget(t *testing.T) {
+	current := databaseDefinition{Views: map[string]viewDefinition{
+		"events_mv": {Name: "events_mv", Query: "SELECT id FROM analytics.raw", Materialized: true, To: "analytics.events"},
+	}}
+
+	for _, to := range []string{"events", "analytics.events", "`analytics`.`events`"} {
+		desired := databaseDefinition{Views: map[string]viewDefinition{
+			"events_mv": {Name: "events_mv", Query: "SELECT id FROM analytics.raw", Materialized: true, To: to},
+		}}
+		if changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired); len(changes) > 0 {
+			t.Errorf("to = %q: expected no changes, got %+v", to, changes)
+		}
+	}
+
+	desired := databaseDefinition{Views: map[string]viewDefinition{
+		"events_mv": {Name: "events_mv", Query: "SELECT id FROM analytics.raw", Materialized: true, To: "archive.events"},
+	}}
+	if changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired); len(changes) != 1 {
+		t.Errorf("expected the view to be replaced, got %+v", changes)
+	}
+}
+
+func TestDiffDatabaseDefinitionsOnCluster(t *testing.T) {
+	columns := []ColumnInfo{{Name: "id", Type: "UInt64"}}
+	current := databaseDefinition{
+		Tables: map[string]tableDefinition{
+			"events": {Name: "events", Engine: "MergeTree", Columns: columns, OrderBy: []string{"id"}},
+			"legacy": {Name: "legacy", Engine: "MergeTree", Columns: columns, OrderBy: []string{"id"}},
+		},
+		Views: map[string]viewDefinition{
+			"old_view": {Name: "old_view", Query: "SELECT id FROM analytics.events"},
+		},
+	}
+	desired := databaseDefinition{
+		Tables: map[string]tableDefinition{
+			"events":  {Name: "events", Engine: "MergeTree", Columns: append(columns, ColumnInfo{Name: "ts", Type: "DateTime"}), OrderBy: []string{"id"}},
+			"metrics": {Name: "metrics", Engine: "MergeTree", Columns: columns, OrderBy: []string{"id"}},
+		},
+		Views: map[string]viewDefinition{
+			"events_mv": {Name: "events_mv", Query: "SELECT id FROM analytics.events", Materialized: true, To: "analytics.metrics"},
+		},
+		Cluster: "main",
+	}
+
+	changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired)
+	statements := 0
+	for _, change := range changes {
+		for _, statement := range change.Statements {
+			statements++
+			if !strings.Contains(statement, " ON CLUSTER `main`") {
+				t.Errorf("%s %s: statement runs on the connected server only: %s", change.Action, change.Object, statement)
+			}
+		}
+	}
+	if statements != 5 {
+		t.Errorf("expected 5 statements, got %+v", changes)
+	}
+}
+
+func TestDiffDatabaseDefinitions(t *testing.T) {
+	columns := []ColumnInfo{{Name: "id", Type: "UInt64"}}
+	table := tableDefinition{Name: "events", Engine: "MergeTree", Columns: columns, OrderBy: []string{"id"}}
+	view := viewDefinition{Name: "events_view", Query: "SELECT id FROM analytics.events"}
+	mv := viewDefinition{Name: "events_mv", Query: "SELECT id FROM analytics.raw", Materialized: true, To: "analytics.events"}
+
+	withColumn := table
+	withColumn.Columns = append([]ColumnInfo{}, columns...)
+	withColumn.Columns = append(withColumn.Columns, ColumnInfo{Name: "ts", Type: "DateTime"})
+	reordered := table
+	reordered.OrderBy = []string{"id", "ts"}
+	otherQuery := view
+	otherQuery.Query = "SELECT id FROM analytics.raw"
+	otherMVQuery := mv
+	otherMVQuery.Query = "SELECT id FROM analytics.raw WHERE id > 0"
+	invoker := view
+	invoker.SQLSecurity = "INVOKER"
+	toOtherTable := mv
+	toOtherTable.To = "analytics.archive"
+
+	tables := func(definitions ...tableDefinition) databaseDefinition {
+		def := databaseDefinition{Tables: map[string]tableDefinition{}}
+		for _, definition := range definitions {
+			def.Tables[definition.Name] = definition
+		}
+		return def
+	}
+	views := func(definitions ...viewDefinition) databaseDefinition {
+		def := tables(table)
+		def.Views = map[string]viewDefinition{}
+		for _, definition := range definitions {
+			def.Views[definition.Name] = definition
+		}
+		return def
+	}
+
+	tests := []struct {
+		name       string
+		current    databaseDefinition
+		desired    databaseDefinition
+		action     string
+		statements []string
+	}{
+		{"unchanged", views(view, mv), views(view, mv), "", nil},
+		{"created table", tables(), tables(table), schemaActionCreate,
+			[]string{"CREATE TABLE `analytics`.`events`"}},
+		{"dropped table", tables(table), tables(), schemaActionDrop,
+			[]string{"DROP TABLE IF EXISTS `analytics`.`events`"}},
+		{"added column", tables(table), tables(withColumn), schemaActionAlter,
+			[]string{"ALTER TABLE `analytics`.`events` ADD COLUMN `ts` DateTime"}},
+		{"changed sorting key", tables(table), tables(reordered), schemaActionReplace,
+			[]string{"DROP TABLE IF EXISTS `analytics`.`events`", "CREATE TABLE `analytics`.`events`"}},
+		{"changed view query", views(view), views(otherQuery), schemaActionReplace,
+			[]string{"CREATE OR REPLACE VIEW `analytics`.`events_view`"}},
+		{"changed view security", views(view), views(invoker), schemaActionAlter,
+			[]string{"ALTER TABLE `analytics`.`events_view` MODIFY SQL SECURITY INVOKER"}},
+		{"changed materialized view query", views(mv), views(otherMVQuery), schemaActionAlter,
+			[]string{"ALTER TABLE `analytics`.`events_mv` MODIFY QUERY SELECT id FROM analytics.raw WHERE id > 0"}},
+		{"changed materialized view target", views(mv), views(toOtherTable), schemaActionReplace,
+			[]string{"DROP VIEW IF EXISTS `analytics`.`events_mv`", "CREATE MATERIALIZED VIEW `analytics`.`events_mv` TO `analytics`.`archive`"}},
+		{"dropped view", views(view), views(), schemaActionDrop,
+			[]string{"DROP VIEW IF EXISTS `analytics`.`events_view`"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", test.current, test.desired)
+			if test.action == "" {
+				if len(changes) > 0 {
+					t.Fatalf("expected no changes, got %+v", changes)
+				}
+				return
+			}
+			if len(changes) != 1 || changes[0].Action != test.action || len(changes[0].Statements) != len(test.statements) {
+				t.Fatalf("expected a single %s change with %d statements, got %+v", test.action, len(test.statements), changes)
+			}
+			for i, prefix := range test.statements {
+				if !strings.HasPrefix(changes[0].Statements[i], prefix) {
+					t.Errorf("statement %d: expected %q to start with %q", i, changes[0].Statements[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffDatabaseDefinitionsOrder(t *testing.T) {
+	columns := []ColumnInfo{{Name: "id", Type: "UInt64"}}
+	current := databaseDefinition{
+		Tables: map[string]tableDefinition{"raw": {Name: "raw", Engine: "MergeTree", Columns: columns, OrderBy: []string{"id"}}},
+		Views:  map[string]viewDefinition{"raw_view": {Name: "raw_view", Query: "SELECT id FROM analytics.raw"}},
+	}
+	desired := databaseDefinition{
+		Tables: map[string]tableDefinition{"events": {Name: "events", Engine: "MergeTree", Columns: columns, OrderBy: []string{"id"}}},
+		Views:  map[string]viewDefinition{"events_view": {Name: "events_view", Query: "SELECT id FROM analytics.events"}},
+	}
+
+	// Views are dropped before the tables they read and created after them
+	var order []string
+	for _, change := range diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired) {
+		order = append(order, change.Action+" "+change.Object)
+	}
+	expected := []string{"drop raw_view", "create events", "create events_view", "drop raw"}
+	if !equalStrings(order, expected) {
+		t.Errorf("expected changes %q, got %q", expected, order)
+	}
+}
+
+func TestParseSortingKey(t *testing.T) {
+	tests := map[string][]string{
+		"id":                        {"id"},
+		"(id, timestamp)":           {"id", "timestamp"},
+		"tuple()":                   {},
+		"":                          {},
+		"(toDate(ts), id)":          {"toDate(ts)", "id"},
+		"cityHash64(id, 'a,b'), ts": {"cityHash64(id, 'a,b')", "ts"},
+		"(a + b) * 2":               {"(a + b) * 2"},
+		"((a, b))":                  {"(a, b)"},
+	}
+
+	for sortingKey, expected := range tests {
+		if actual := parseSortingKey(sortingKey); !equalStrings(actual, expected) {
+			t.Errorf("parseSortingKey(%q) = %q, expected %q", sortingKey, actual, expected)
+		}
+	}
+}
+
+func TestColumnsWithDesiredSpelling(t *testing.T) {
+	current := []ColumnInfo{
+		{Name: "ts", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()"},
+		{Name: "message", Type: "String", TTL: "ts  +  toIntervalDay(30)"},
+		{Name: "day", Type: "Date", DefaultKind: "MATERIALIZED", DefaultExpression: "toDate(ts)"},
+	}
+	desired := []ColumnInfo{
+		{Name: "ts", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now( )"},
+		{Name: "body", Type: "String", TTL: "ts + toIntervalDay(30)", PreviousName: "message"},
+		{Name: "day", Type: "Date", DefaultKind: "ALIAS", DefaultExpression: "toDate(ts)"},
+	}
+
+	columns := columnsWithDesiredSpelling(context.Background(), nil, current, desired)
+	if columns[0].DefaultExpression != "now()" {
+		t.Errorf("expected a different expression to keep its server spelling, got %q", columns[0].DefaultExpression)
+	}
+	if columns[1].TTL != "ts + toIntervalDay(30)" {
+		t.Errorf("expected the TTL of the renamed column in its desired spelling, got %q", columns[1].TTL)
+	}
+	if columns[2].DefaultKind != "MATERIALIZED" {
+		t.Errorf("expected the default kind to be left alone, got %q", columns[2].DefaultKind)
+	}
+	if statements := columnAlterStatements("t", columns, desired); len(statements) != 3 {
+		t.Errorf("expected the rename, the default change and the alias change, got %q", statements)
+	}
+}
+
+func TestDiffDatabaseDefinitionsMaterializedViewQuery(t *testing.T) {
+	current := databaseDefinition{Views: map[string]viewDefinition{
+		"events_mv": {Name: "events_mv", Query: "SELECT id FROM analytics.raw", Materialized: true, To: "analytics.events"},
+	}}
+	desired := databaseDefinition{Views: map[string]viewDefinition{
+		"events_mv": {Name: "events_mv", Query: "SELECT id\n  FROM analytics.raw", Materialized: true, To: "analytics.events"},
+	}}
+	if changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired); len(changes) > 0 {
+		t.Fatalf("expected no changes for a reformatted query, got %+v", changes)
+	}
+
+	desired.Views["events_mv"] = viewDefinition{Name: "events_mv", Query: "SELECT id FROM analytics.raw WHERE id > 0", Materialized: true, To: "analytics.events"}
+	changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired)
+	if len(changes) != 1 || len(changes[0].Statements) != 1 || !strings.Contains(changes[0].Statements[0], "MODIFY QUERY") {
+		t.Fatalf("expected the query to be modified in place, got %+v", changes)
+	}
+	if changes[0].Hint == "" {
+		t.Error("expected a hint explaining that the view is not recreated when MODIFY QUERY fails")
+	}
+}
+
+func TestDiffDatabaseDefinitionsPlainViewQuery(t *testing.T) {
+	current := databaseDefinition{Views: map[string]viewDefinition{
+		"events_view": {Name: "events_view", Query: "SELECT id FROM analytics.events"},
+	}}
+	desired := databaseDefinition{Views: map[string]viewDefinition{
+		"events_view": {Name: "events_view", Query: "SELECT id\nFROM   analytics.events"},
+	}}
+	if changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired); len(changes) > 0 {
+		t.Fatalf("expected no changes for a reformatted query, got %+v", changes)
+	}
+
+	desired.Views["events_view"] = viewDefinition{Name: "events_view", Query: "SELECT id, ts FROM analytics.events"}
+	changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired)
+	if len(changes) != 1 || changes[0].Action != schemaActionReplace || !strings.HasPrefix(changes[0].Statements[0], "CREATE OR REPLACE VIEW") {
+		t.Fatalf("expected the view to be replaced atomically, got %+v", changes)
+	}
+}
+
+func TestDiffDatabaseDefinitionsRefreshDependencies(t *testing.T) {
+	current := databaseDefinition{Views: map[string]viewDefinition{
+		"daily": {Name: "daily", Query: "SELECT 1", Materialized: true, To: "analytics.daily_totals",
+			Refresh: "EVERY 1 DAY", DependsOn: []string{"analytics.hourly"}},
+	}}
+
+	for _, dependsOn := range []string{"hourly", "analytics.hourly", "`analytics`.`hourly`"} {
+		desired := databaseDefinition{Views: map[string]viewDefinition{
+			"daily": {Name: "daily", Query: "SELECT 1", Materialized: true, To: "daily_totals",
+				Refresh: "EVERY 1 DAY", DependsOn: []string{dependsOn}},
+		}}
+		if changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired); len(changes) > 0 {
+			t.Errorf("depends_on = %q: expected no changes, got %+v", dependsOn, changes)
+		}
+	}
+
+	desired := databaseDefinition{Views: map[string]viewDefinition{
+		"daily": {Name: "daily", Query: "SELECT 1", Materialized: true, To: "daily_totals",
+			Refresh: "EVERY 1 DAY", DependsOn: []string{"archive.hourly"}},
+	}}
+	changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, desired)
+	if len(changes) != 1 || !strings.Contains(changes[0].Statements[0], "MODIFY REFRESH EVERY 1 DAY DEPENDS ON") {
+		t.Errorf("expected the schedule to be altered, got %+v", changes)
+	}
+}
+
+func TestRetargetQuery(t *testing.T) {
+	tests := map[string]string{
+		"SELECT id FROM staging.raw":                                 "SELECT id FROM analytics.raw",
+		"SELECT id FROM `staging`.`raw`":                             "SELECT id FROM `analytics`.`raw`",
+		"SELECT id FROM \"staging\".raw JOIN staging.users USING id": "SELECT id FROM `analytics`.raw JOIN analytics.users USING id",
+		"SELECT id FROM other.raw":                                   "SELECT id FROM other.raw",
+		"SELECT 'staging.raw' AS name FROM raw":                      "SELECT 'staging.raw' AS name FROM raw",
+		"SELECT t.staging.x FROM staging_raw AS t":                   "SELECT t.staging.x FROM staging_raw AS t",
+	}
+
+	for query, expected := range tests {
+		if actual := retargetQuery(query, "staging", "analytics"); actual != expected {
+			t.Errorf("retargetQuery(%q) = %q, expected %q", query, actual, expected)
+		}
+	}
+}
+
+func TestDiffDatabaseDefinitionsRetargetedSource(t *testing.T) {
+	current := databaseDefinition{Views: map[string]viewDefinition{
+		"events_mv": {Name: "events_mv", Query: "SELECT id FROM analytics.raw", Materialized: true, To: "analytics.events"},
+	}}
+	source := databaseDefinition{Views: map[string]viewDefinition{
+		"events_mv": {Name: "events_mv", Query: "SELECT id FROM staging.raw", Materialized: true, To: "staging.events"},
+	}}
+
+	if changes := diffDatabaseDefinitions(context.Background(), nil, "analytics", current, retargetDefinition(source, "staging", "analytics")); len(changes) > 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+	if source.Views["events_mv"].To != "staging.events" {
+		t.Errorf("expected the source definition to be left untouched, got %+v", source.Views["events_mv"])
+	}
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ServerCapabilitiesDataSource{}
+
+func NewServerCapabilitiesDataSource() datasource.DataSource {
+	return &ServerCapabilitiesDataSource{}
+}
+
+// ServerCapabilitiesDataSource exposes the table engines and formats supported by the server.
+type ServerCapabilitiesDataSource struct {
+	client *clickhouseClient
+}
+
+// ServerCapabilitiesDataSourceModel describes the data source data model.
+type ServerCapabilitiesDataSourceModel struct {
+	ID            types.String   `tfsdk:"id"`
+	Version       types.String   `tfsdk:"version"`
+	TableEngines  []types.String `tfsdk:"table_engines"`
+	InputFormats  []types.String `tfsdk:"input_formats"`
+	OutputFormats []types.String `tfsdk:"output_formats"`
+}
+
+func (d *ServerCapabilitiesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_server_capabilities"
+}
+
+func (d *ServerCapabilitiesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the table engines (`system.table_engines`) and formats (`system.formats`) " +
+			"available on the connected server, e.g. to precondition that `Kafka` or `S3Queue` is compiled in " +
+			"before creating a table using it.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Server capabilities identifier",
+			},
+			"version": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Server version",
+			},
+			"table_engines": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of the table engines supported by the server",
+			},
+			"input_formats": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of the formats usable for input",
+			},
+			"output_formats": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of the formats usable for output",
+			},
+		},
+	}
+}
+
+func (d *ServerCapabilitiesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ServerCapabilitiesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ServerCapabilitiesDataSourceModel
+
+	var version string
+	if err := d.client.QueryRowContext(ctx, "SELECT version()").Scan(&version); err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading server version",
+			fmt.Sprintf("Could not read server version: %s", err.Error()),
+		)
+		return
+	}
+
+	engines, err := d.readNames(ctx, "SELECT name FROM system.table_engines ORDER BY name")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading table engines",
+			fmt.Sprintf("Could not read system.table_engines: %s", err.Error()),
+		)
+		return
+	}
+
+	inputFormats, err := d.readNames(ctx, "SELECT name FROM system.formats WHERE is_input ORDER BY name")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading formats",
+			fmt.Sprintf("Could not read system.formats: %s", err.Error()),
+		)
+		return
+	}
+
+	outputFormats, err := d.readNames(ctx, "SELECT name FROM system.formats WHERE is_output ORDER BY name")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading formats",
+			fmt.Sprintf("Could not read system.formats: %s", err.Error()),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(version)
+	data.Version = types.StringValue(version)
+	data.TableEngines = engines
+	data.InputFormats = inputFormats
+	data.OutputFormats = outputFormats
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readNames reads a single string column
+func (d *ServerCapabilitiesDataSource) readNames(ctx context.Context, query string) ([]types.String, error) {
+	rows, err := d.client.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []types.String{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, types.StringValue(name))
+	}
+
+	return names, rows.Err()
+}
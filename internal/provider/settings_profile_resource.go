@@ -0,0 +1,383 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SettingsProfileResource{}
+var _ resource.ResourceWithImportState = &SettingsProfileResource{}
+
+func NewSettingsProfileResource() resource.Resource {
+	return &SettingsProfileResource{}
+}
+
+// SettingsProfileResource manages a ClickHouse settings profile.
+type SettingsProfileResource struct {
+	client *clickhouseClient
+}
+
+// SettingsProfileResourceModel describes the resource data model.
+type SettingsProfileResourceModel struct {
+	ID       types.String                  `tfsdk:"id"`
+	Name     types.String                  `tfsdk:"name"`
+	Cluster  types.String                  `tfsdk:"cluster"`
+	Inherit  []types.String                `tfsdk:"inherit"`
+	To       []types.String                `tfsdk:"to"`
+	Settings []SettingsProfileSettingModel `tfsdk:"setting"`
+}
+
+type SettingsProfileSettingModel struct {
+	Name     types.String `tfsdk:"name"`
+	Value    types.String `tfsdk:"value"`
+	Min      types.String `tfsdk:"min"`
+	Max      types.String `tfsdk:"max"`
+	Readonly types.Bool   `tfsdk:"readonly"`
+}
+
+func (r *SettingsProfileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_settings_profile"
+}
+
+func (r *SettingsProfileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a ClickHouse settings profile created with `CREATE SETTINGS PROFILE`: setting values, " +
+			"their allowed range and whether users may change them, optionally inherited from other profiles.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Settings profile identifier",
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Settings profile name",
+				Required:            true,
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the profile is created with `ON CLUSTER`, defaulting to the provider `cluster`. " +
+					"An empty string creates it on the connected server only",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"inherit": schema.ListAttribute{
+				MarkdownDescription: "Profiles whose settings are inherited, in order",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"to": schema.ListAttribute{
+				MarkdownDescription: "Users and roles the profile is assigned to, or `ALL`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"setting": schema.ListNestedBlock{
+				MarkdownDescription: "Setting value and constraints",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Setting name (e.g. `max_memory_usage`)",
+							Required:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Setting value",
+							Optional:            true,
+						},
+						"min": schema.StringAttribute{
+							MarkdownDescription: "Minimum value users may set",
+							Optional:            true,
+						},
+						"max": schema.StringAttribute{
+							MarkdownDescription: "Maximum value users may set",
+							Optional:            true,
+						},
+						"readonly": schema.BoolAttribute{
+							MarkdownDescription: "Prevent users from changing the setting (`CONST`)",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *SettingsProfileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *SettingsProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SettingsProfileResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createSQL := fmt.Sprintf("CREATE SETTINGS PROFILE %s%s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)), settingsProfileClauses(data, false))
+
+	tflog.Info(ctx, "Creating ClickHouse settings profile", map[string]interface{}{
+		"sql": createSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, createSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error creating settings profile",
+			fmt.Sprintf("Could not create settings profile %s", data.Name.ValueString()),
+			withStatement(createSQL, err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SettingsProfileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SettingsProfileResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.readProfile(ctx, data.ID.ValueString(), data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			tflog.Info(ctx, "Settings profile no longer exists, removing from state", map[string]interface{}{
+				"id": data.ID.ValueString(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading settings profile",
+			fmt.Sprintf("Could not read settings profile %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &current)...)
+}
+
+func (r *SettingsProfileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state SettingsProfileResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alterSQL := fmt.Sprintf("ALTER SETTINGS PROFILE %s%s", quoteIdentifier(state.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+	if data.Name.ValueString() != state.Name.ValueString() {
+		alterSQL += fmt.Sprintf(" RENAME TO %s", quoteIdentifier(data.Name.ValueString()))
+	}
+	alterSQL += settingsProfileClauses(data, true)
+
+	tflog.Info(ctx, "Updating ClickHouse settings profile", map[string]interface{}{
+		"sql": alterSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, alterSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error updating settings profile",
+			fmt.Sprintf("Could not alter settings profile %s", state.Name.ValueString()),
+			withStatement(alterSQL, err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SettingsProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data SettingsProfileResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dropSQL := fmt.Sprintf("DROP SETTINGS PROFILE IF EXISTS %s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+
+	tflog.Info(ctx, "Dropping ClickHouse settings profile", map[string]interface{}{
+		"sql": dropSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, dropSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error dropping settings profile",
+			fmt.Sprintf("Could not drop settings profile %s", data.Name.ValueString()),
+			withStatement(dropSQL, err),
+		))
+	}
+}
+
+func (r *SettingsProfileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	data, err := r.readProfile(ctx, req.ID, SettingsProfileResourceModel{Cluster: types.StringNull()})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error importing settings profile",
+			fmt.Sprintf("Could not read settings profile %s from system.settings_profiles: %s", req.ID, err.Error()),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readProfile reads a settings profile from system.settings_profiles and its
+// elements from system.settings_profile_elements
+func (r *SettingsProfileResource) readProfile(ctx context.Context, name string, prior SettingsProfileResourceModel) (SettingsProfileResourceModel, error) {
+	var applyTo []string
+	var applyToAll uint8
+	err := r.client.QueryRowContext(ctx, "SELECT apply_to_all, apply_to_list FROM system.settings_profiles WHERE name = ?",
+		name).Scan(&applyToAll, &applyTo)
+	if err != nil {
+		return SettingsProfileResourceModel{}, err
+	}
+
+	rows, err := r.client.QueryContext(ctx, `
+        SELECT setting_name, value, min, max, toString(writability), inherit_profile
+        FROM system.settings_profile_elements
+        WHERE profile_name = ?
+        ORDER BY index
+    `, name)
+	if err != nil {
+		return SettingsProfileResourceModel{}, err
+	}
+	defer rows.Close()
+
+	var inherit []string
+	var settings []SettingsProfileSettingModel
+	for rows.Next() {
+		var setting, value, minValue, maxValue, writability, inheritProfile sql.NullString
+		if err := rows.Scan(&setting, &value, &minValue, &maxValue, &writability, &inheritProfile); err != nil {
+			return SettingsProfileResourceModel{}, err
+		}
+
+		if inheritProfile.Valid {
+			inherit = append(inherit, inheritProfile.String)
+		}
+		if setting.Valid {
+			settings = append(settings, SettingsProfileSettingModel{
+				Name:     types.StringValue(setting.String),
+				Value:    nullableString(value),
+				Min:      nullableString(minValue),
+				Max:      nullableString(maxValue),
+				Readonly: types.BoolValue(writability.String == "CONST"),
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return SettingsProfileResourceModel{}, err
+	}
+
+	data := SettingsProfileResourceModel{
+		ID:       types.StringValue(name),
+		Name:     types.StringValue(name),
+		Cluster:  prior.Cluster,
+		Inherit:  stringValues(inherit, prior.Inherit),
+		To:       stringValues(applyTo, prior.To),
+		Settings: settings,
+	}
+	if applyToAll != 0 {
+		data.To = []types.String{types.StringValue("ALL")}
+	}
+
+	return data, nil
+}
+
+// settingsProfileClauses builds the clauses shared by CREATE and ALTER SETTINGS
+// PROFILE. When altering, the settings and assignees are reset when unset.
+func settingsProfileClauses(data SettingsProfileResourceModel, alter bool) string {
+	var elements []string
+	for _, profile := range data.Inherit {
+		elements = append(elements, "INHERIT "+quoteString(profile.ValueString()))
+	}
+	for _, setting := range data.Settings {
+		element := setting.Name.ValueString()
+		if !setting.Value.IsNull() {
+			element += " = " + settingValueSQL(setting.Value.ValueString())
+		}
+		if !setting.Min.IsNull() {
+			element += " MIN " + settingValueSQL(setting.Min.ValueString())
+		}
+		if !setting.Max.IsNull() {
+			element += " MAX " + settingValueSQL(setting.Max.ValueString())
+		}
+		if setting.Readonly.ValueBool() {
+			element += " CONST"
+		}
+		elements = append(elements, element)
+	}
+
+	var clauses []string
+	switch {
+	case len(elements) > 0:
+		clauses = append(clauses, "SETTINGS "+strings.Join(elements, ", "))
+	case alter:
+		clauses = append(clauses, "SETTINGS NONE")
+	}
+
+	switch {
+	case len(data.To) > 0:
+		clauses = append(clauses, "TO "+joinIdentifiers(data.To))
+	case alter:
+		clauses = append(clauses, "TO NONE")
+	}
+
+	if len(clauses) == 0 {
+		return ""
+	}
+	return " " + strings.Join(clauses, " ")
+}
+
+// nullableString converts a nullable string read from the server
+func nullableString(value sql.NullString) types.String {
+	if !value.Valid {
+		return types.StringNull()
+	}
+	return types.StringValue(value.String)
+}
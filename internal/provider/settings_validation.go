@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+)
+
+// validateMergeTreeSettings checks table level settings against the settings known
+// by the connected server. Unknown settings are reported with the closest known
+// name, and settings that only exist at query level or cannot be changed are rejected.
+func validateMergeTreeSettings(ctx context.Context, client *clickhouseClient, settings map[string]string) ([]string, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+
+	known, err := readSettingNames(ctx, client, "SELECT name, readonly FROM system.merge_tree_settings")
+	if err != nil {
+		return nil, fmt.Errorf("could not read system.merge_tree_settings: %w", err)
+	}
+
+	var sessionSettings map[string]bool
+	var problems []string
+	for _, name := range sortedKeys(settings) {
+		readonly, ok := known[name]
+		if ok {
+			if readonly {
+				problems = append(problems, fmt.Sprintf("setting '%s' is read-only and cannot be set on a table", name))
+			}
+			continue
+		}
+
+		if sessionSettings == nil {
+			sessionSettings, err = readSettingNames(ctx, client, "SELECT name, toUInt8(readonly != 0) FROM system.settings")
+			if err != nil {
+				return nil, fmt.Errorf("could not read system.settings: %w", err)
+			}
+		}
+
+		if _, isSession := sessionSettings[name]; isSession {
+			problems = append(problems, fmt.Sprintf("setting '%s' is a query level setting, not a MergeTree table setting", name))
+			continue
+		}
+
+		problem := fmt.Sprintf("unknown MergeTree setting '%s'", name)
+		if suggestion := closestName(name, known); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean '%s'?", suggestion)
+		}
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+// readSettingNames reads setting names with their read-only flag
+func readSettingNames(ctx context.Context, client *clickhouseClient, query string) (map[string]bool, error) {
+	rows, err := client.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var readonly uint8
+		if err := rows.Scan(&name, &readonly); err != nil {
+			return nil, err
+		}
+		names[name] = readonly != 0
+	}
+
+	return names, rows.Err()
+}
+
+// closestName returns the candidate with the smallest edit distance to name,
+// or an empty string when nothing is reasonably close.
+func closestName[V any](name string, candidates map[string]V) string {
+	best := ""
+	bestDistance := len(name)/2 + 1
+	for _, candidate := range sortedKeys(candidates) {
+		if distance := levenshtein(name, candidate); distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,329 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SystemLogRetentionResource{}
+var _ resource.ResourceWithImportState = &SystemLogRetentionResource{}
+var _ resource.ResourceWithValidateConfig = &SystemLogRetentionResource{}
+
+func NewSystemLogRetentionResource() resource.Resource {
+	return &SystemLogRetentionResource{}
+}
+
+// SystemLogRetentionResource manages the TTL of a system log table.
+type SystemLogRetentionResource struct {
+	client *clickhouseClient
+}
+
+// SystemLogRetentionResourceModel describes the resource data model.
+type SystemLogRetentionResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Table             types.String `tfsdk:"table"`
+	TTL               types.String `tfsdk:"ttl"`
+	PartitionKey      types.String `tfsdk:"partition_key"`
+	ExecutionSettings types.Map    `tfsdk:"execution_settings"`
+}
+
+// systemLogTables lists the system tables written by the server log flushers
+var systemLogTables = []string{
+	"asynchronous_insert_log", "asynchronous_metric_log", "backup_log", "blob_storage_log",
+	"crash_log", "error_log", "filesystem_cache_log", "metric_log", "opentelemetry_span_log",
+	"part_log", "processors_profile_log", "query_log", "query_metric_log", "query_thread_log",
+	"query_views_log", "s3queue_log", "session_log", "text_log", "trace_log", "zookeeper_log",
+}
+
+var (
+	engineTTLPattern = regexp.MustCompile(`\sTTL\s(.+?)(?:\sSETTINGS\s|$)`)
+	intervalLiteral  = regexp.MustCompile(`(?i)INTERVAL\s+(\d+)\s+([A-Za-z]+?)S?\b`)
+)
+
+func (r *SystemLogRetentionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_system_log_retention"
+}
+
+func (r *SystemLogRetentionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the retention of a system log table (e.g. `query_log`, `part_log`, `trace_log`) " +
+			"through `ALTER TABLE ... MODIFY TTL`. The partition key of system log tables can only be changed in the " +
+			"server configuration and is exposed read-only. Destroying the resource removes the TTL.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "System log retention identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"table": schema.StringAttribute{
+				MarkdownDescription: "System log table name (e.g. `query_log`)",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.StringAttribute{
+				MarkdownDescription: "TTL expression (e.g. `event_date + INTERVAL 30 DAY`)",
+				Required:            true,
+			},
+			"partition_key": schema.StringAttribute{
+				MarkdownDescription: "Partition key of the table, as configured on the server",
+				Computed:            true,
+			},
+			"execution_settings": schema.MapAttribute{
+				MarkdownDescription: "ClickHouse settings applied to the `ALTER TABLE` statements (e.g. `alter_sync`), " +
+					"overriding the provider defaults",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *SystemLogRetentionResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SystemLogRetentionResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Table.IsNull() || data.Table.IsUnknown() {
+		return
+	}
+
+	for _, table := range systemLogTables {
+		if table == data.Table.ValueString() {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeError(
+		path.Root("table"),
+		"Unsupported system log table",
+		fmt.Sprintf("Expected one of %s, got: %s", strings.Join(systemLogTables, ", "), data.Table.ValueString()),
+	)
+}
+
+func (r *SystemLogRetentionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *SystemLogRetentionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SystemLogRetentionResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	data.ID = types.StringValue("system." + data.Table.ValueString())
+	if err := r.modifyTTL(ctx, &data); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error setting system log retention",
+			fmt.Sprintf("Could not set TTL on %s", data.ID.ValueString()),
+			err,
+		))
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SystemLogRetentionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SystemLogRetentionResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ttl, partitionKey, err := r.readRetention(ctx, data.Table.ValueString())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The log table is only created once the first entries are flushed
+			tflog.Info(ctx, "System log table does not exist, removing from state", map[string]interface{}{
+				"id": data.ID.ValueString(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading system log retention",
+			fmt.Sprintf("Could not read TTL of %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	data.PartitionKey = types.StringValue(partitionKey)
+	switch {
+	case ttl == "":
+		data.TTL = types.StringNull()
+	case normalizeTTL(ttl) != normalizeTTL(data.TTL.ValueString()):
+		data.TTL = types.StringValue(ttl)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SystemLogRetentionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data SystemLogRetentionResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	if err := r.modifyTTL(ctx, &data); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error setting system log retention",
+			fmt.Sprintf("Could not set TTL on %s", data.ID.ValueString()),
+			err,
+		))
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SystemLogRetentionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data SystemLogRetentionResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+
+	removeSQL := fmt.Sprintf("ALTER TABLE %s REMOVE TTL", qualifiedName("system", data.Table.ValueString()))
+
+	tflog.Info(ctx, "Removing system log retention", map[string]interface{}{
+		"sql": removeSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, removeSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error removing system log retention",
+			fmt.Sprintf("Could not remove TTL from %s", data.ID.ValueString()),
+			withStatement(removeSQL, err),
+		))
+	}
+}
+
+func (r *SystemLogRetentionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	table := strings.TrimPrefix(req.ID, "system.")
+
+	ttl, partitionKey, err := r.readRetention(ctx, table)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading system log retention",
+			fmt.Sprintf("Could not read TTL of system.%s: %s", table, err.Error()),
+		)
+		return
+	}
+
+	data := SystemLogRetentionResourceModel{
+		ID:                types.StringValue("system." + table),
+		Table:             types.StringValue(table),
+		TTL:               types.StringNull(),
+		PartitionKey:      types.StringValue(partitionKey),
+		ExecutionSettings: types.MapNull(types.StringType),
+	}
+	if ttl != "" {
+		data.TTL = types.StringValue(ttl)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// modifyTTL applies the configured TTL and refreshes the partition key
+func (r *SystemLogRetentionResource) modifyTTL(ctx context.Context, data *SystemLogRetentionResourceModel) error {
+	modifySQL := fmt.Sprintf("ALTER TABLE %s MODIFY TTL %s", qualifiedName("system", data.Table.ValueString()), data.TTL.ValueString())
+
+	tflog.Info(ctx, "Setting system log retention", map[string]interface{}{
+		"sql": modifySQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, modifySQL); err != nil {
+		return withStatement(modifySQL, err)
+	}
+
+	_, partitionKey, err := r.readRetention(ctx, data.Table.ValueString())
+	if err != nil {
+		return err
+	}
+	data.PartitionKey = types.StringValue(partitionKey)
+
+	return nil
+}
+
+// readRetention returns the TTL and partition key of a system log table
+func (r *SystemLogRetentionResource) readRetention(ctx context.Context, table string) (string, string, error) {
+	query := `
+        SELECT engine_full, partition_key
+        FROM system.tables
+        WHERE database = 'system' AND name = ?
+    `
+
+	var engineFull, partitionKey string
+	if err := r.client.QueryRowContext(ctx, query, table).Scan(&engineFull, &partitionKey); err != nil {
+		return "", "", err
+	}
+
+	ttl := ""
+	if match := engineTTLPattern.FindStringSubmatch(engineFull); match != nil {
+		ttl = strings.TrimSpace(match[1])
+	}
+
+	return ttl, partitionKey, nil
+}
+
+// normalizeTTL rewrites INTERVAL literals the way ClickHouse reports them and
+// collapses whitespace, so equivalent TTL expressions compare equal.
+func normalizeTTL(ttl string) string {
+	ttl = intervalLiteral.ReplaceAllStringFunc(ttl, func(literal string) string {
+		match := intervalLiteral.FindStringSubmatch(literal)
+		unit := strings.ToLower(match[2])
+		return fmt.Sprintf("toInterval%c%s(%s)", unicode.ToUpper(rune(unit[0])), unit[1:], match[1])
+	})
+	return strings.Join(strings.Fields(ttl), " ")
+}
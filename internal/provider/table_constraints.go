@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ConstraintModel describes a CHECK constraint of a table.
+type ConstraintModel struct {
+	Name       types.String `tfsdk:"name"`
+	Expression types.String `tfsdk:"expression"`
+}
+
+// ConstraintInfo represents a CHECK constraint as defined in the CREATE statement of a table
+type ConstraintInfo struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// constraintsBlock returns the schema of the constraints block of the table resource
+func constraintsBlock() schema.Block {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "CHECK constraints validated on insert, added and dropped in place with `ALTER TABLE ... ADD/DROP CONSTRAINT`",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					MarkdownDescription: "Constraint name",
+					Required:            true,
+				},
+				"expression": schema.StringAttribute{
+					MarkdownDescription: "Boolean expression every inserted row must satisfy (e.g. `length(message) < 4096`)",
+					Required:            true,
+				},
+			},
+		},
+	}
+}
+
+// info converts the constraint model into a constraint definition
+func (m ConstraintModel) info() ConstraintInfo {
+	return ConstraintInfo{
+		Name:       m.Name.ValueString(),
+		Expression: m.Expression.ValueString(),
+	}
+}
+
+// constraintModel converts a constraint read from ClickHouse into its Terraform model
+func constraintModel(constraint ConstraintInfo) ConstraintModel {
+	return ConstraintModel{
+		Name:       types.StringValue(constraint.Name),
+		Expression: types.StringValue(constraint.Expression),
+	}
+}
+
+// constraintDefinitionSQL renders the CONSTRAINT clause of a constraint
+func constraintDefinitionSQL(constraint ConstraintInfo) string {
+	return fmt.Sprintf("CONSTRAINT %s CHECK %s", quoteIdentifier(constraint.Name), constraint.Expression)
+}
+
+// tableConstraints extracts the CHECK constraints from a CREATE TABLE statement.
+// ASSUME constraints are only optimizer hints and are ignored.
+func tableConstraints(createQuery string) []ConstraintInfo {
+	var constraints []ConstraintInfo
+	for _, element := range tableElements(createQuery, "CONSTRAINT") {
+		if len(element.Definition) < len("CHECK ") || !strings.EqualFold(element.Definition[:len("CHECK ")], "CHECK ") {
+			continue
+		}
+		constraints = append(constraints, ConstraintInfo{
+			Name:       element.Name,
+			Expression: strings.TrimSpace(element.Definition[len("CHECK "):]),
+		})
+	}
+
+	return constraints
+}
+
+// readTableConstraints reads the CHECK constraints of a table from its CREATE statement
+func readTableConstraints(ctx context.Context, client *clickhouseClient, database, table string) ([]ConstraintInfo, error) {
+	var createQuery string
+	err := client.QueryRowContext(ctx, "SELECT create_table_query FROM system.tables WHERE database = ? AND name = ?",
+		database, table).Scan(&createQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return tableConstraints(createQuery), nil
+}
+
+// reconcileConstraints builds the constraints to store in state from the ones found on the server,
+// keeping the configured spelling of equivalent constraints and the configured order
+func reconcileConstraints(ctx context.Context, client *clickhouseClient, configured []ConstraintModel, actual []ConstraintInfo) []ConstraintModel {
+	found := make(map[string]ConstraintInfo, len(actual))
+	for _, constraint := range actual {
+		found[constraint.Name] = constraint
+	}
+
+	var constraints []ConstraintModel
+	if configured != nil {
+		constraints = []ConstraintModel{}
+	}
+	seen := map[string]bool{}
+	for _, model := range configured {
+		constraint, ok := found[model.Name.ValueString()]
+		if !ok {
+			continue
+		}
+		seen[constraint.Name] = true
+
+		if !expressionsEquivalent(ctx, client, model.Expression.ValueString(), constraint.Expression) {
+			model.Expression = types.StringValue(constraint.Expression)
+		}
+		constraints = append(constraints, model)
+	}
+
+	// Constraints added outside of Terraform are kept so the next apply drops them
+	for _, constraint := range actual {
+		if !seen[constraint.Name] {
+			constraints = append(constraints, constraintModel(constraint))
+		}
+	}
+
+	return constraints
+}
+
+// constraintAlterStatements generates the drops and the additions turning the current constraints into the
+// desired ones. Changed constraints are dropped and added again.
+func constraintAlterStatements(table string, current, desired []ConstraintInfo) ([]string, []string) {
+	existing := make(map[string]ConstraintInfo, len(current))
+	for _, constraint := range current {
+		existing[constraint.Name] = constraint
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	var drops, adds []string
+	for _, constraint := range desired {
+		wanted[constraint.Name] = true
+
+		have, ok := existing[constraint.Name]
+		if ok && have == constraint {
+			continue
+		}
+		if ok {
+			drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, quoteIdentifier(constraint.Name)))
+		}
+		adds = append(adds, fmt.Sprintf("ALTER TABLE %s ADD %s", table, constraintDefinitionSQL(constraint)))
+	}
+
+	for _, constraint := range current {
+		if !wanted[constraint.Name] {
+			drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, quoteIdentifier(constraint.Name)))
+		}
+	}
+
+	return drops, adds
+}
@@ -0,0 +1,793 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// EngineModel describes the engine of a table and its parameters.
+type EngineModel struct {
+	Name       types.String   `tfsdk:"name"`
+	Parameters []types.String `tfsdk:"parameters"`
+
+	Ver       types.String `tfsdk:"ver"`
+	IsDeleted types.String `tfsdk:"is_deleted"`
+
+	SignColumn    types.String `tfsdk:"sign_column"`
+	VersionColumn types.String `tfsdk:"version_column"`
+
+	SumColumns []types.String `tfsdk:"sum_columns"`
+
+	ZooKeeperPath types.String `tfsdk:"zk_path"`
+	ReplicaName   types.String `tfsdk:"replica_name"`
+
+	Cluster        types.String `tfsdk:"cluster"`
+	RemoteDatabase types.String `tfsdk:"remote_database"`
+	RemoteTable    types.String `tfsdk:"remote_table"`
+	ShardingKey    types.String `tfsdk:"sharding_key"`
+
+	Buffer     *BufferModel     `tfsdk:"buffer"`
+	Kafka      *KafkaModel      `tfsdk:"kafka"`
+	Nats       *NatsModel       `tfsdk:"nats"`
+	S3         *S3Model         `tfsdk:"s3"`
+	URL        *URLModel        `tfsdk:"url"`
+	File       *FileModel       `tfsdk:"file"`
+	MySQL      *MySQLModel      `tfsdk:"mysql"`
+	PostgreSQL *PostgreSQLModel `tfsdk:"postgresql"`
+	Join       *JoinModel       `tfsdk:"join"`
+}
+
+// BufferModel describes the flush thresholds of a Buffer engine.
+type BufferModel struct {
+	NumLayers types.Int64 `tfsdk:"num_layers"`
+	MinTime   types.Int64 `tfsdk:"min_time"`
+	MaxTime   types.Int64 `tfsdk:"max_time"`
+	MinRows   types.Int64 `tfsdk:"min_rows"`
+	MaxRows   types.Int64 `tfsdk:"max_rows"`
+	MinBytes  types.Int64 `tfsdk:"min_bytes"`
+	MaxBytes  types.Int64 `tfsdk:"max_bytes"`
+
+	FlushTime  types.Int64 `tfsdk:"flush_time"`
+	FlushRows  types.Int64 `tfsdk:"flush_rows"`
+	FlushBytes types.Int64 `tfsdk:"flush_bytes"`
+}
+
+// engineStructuredAttributes lists the engines supporting each structured parameter attribute, in the order they are passed
+var engineStructuredAttributes = []struct {
+	Attribute string
+	Engines   []string
+}{
+	{"cluster", []string{"Distributed"}},
+	{"remote_database", []string{"Distributed", "Buffer"}},
+	{"remote_table", []string{"Distributed", "Buffer"}},
+	{"sharding_key", []string{"Distributed"}},
+	{"buffer", []string{"Buffer"}},
+	{"kafka", []string{"Kafka"}},
+	{"nats", []string{"NATS"}},
+	{"s3", []string{"S3"}},
+	{"url", []string{"URL"}},
+	{"file", []string{"File"}},
+	{"mysql", []string{"MySQL"}},
+	{"postgresql", []string{"PostgreSQL"}},
+	{"join", []string{"Join"}},
+}
+
+// engineRequiredAttributes lists the structured attributes an engine needs once one of them is set
+var engineRequiredAttributes = map[string][]string{
+	"Distributed": {"cluster", "remote_database", "remote_table"},
+	"Buffer":      {"remote_database", "remote_table", "buffer"},
+}
+
+// uuidPattern matches the table UUID the server substitutes for the {uuid} macro
+const uuidPattern = `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+
+// engineParameterCounts bounds the number of parameters of the MergeTree engines,
+// not counting the Keeper path and replica name of their Replicated variants
+var engineParameterCounts = map[string][2]int{
+	"MergeTree":                    {0, 0},
+	"ReplacingMergeTree":           {0, 2},
+	"SummingMergeTree":             {0, 1},
+	"AggregatingMergeTree":         {0, 0},
+	"CollapsingMergeTree":          {1, 1},
+	"VersionedCollapsingMergeTree": {2, 2},
+	"GraphiteMergeTree":            {1, 1},
+}
+
+// engineColumn is an engine parameter naming a column, declared with its own attribute of the engine block
+type engineColumn struct {
+	Attribute string
+	Families  []string
+	Types     *regexp.Regexp
+	TypeNames string
+}
+
+// numericTypePattern matches the types SummingMergeTree can sum
+var numericTypePattern = regexp.MustCompile(`^(U?Int(8|16|32|64|128|256)|Float(32|64)|BFloat16|Decimal(32|64|128|256)?)(\(.*\))?$`)
+
+// versionTypePattern matches the types accepted for version columns
+var versionTypePattern = regexp.MustCompile(`^(UInt(8|16|32|64|128|256)|Date|Date32|DateTime|DateTime64)(\(.*\))?$`)
+
+// engineColumns lists the column parameters in the order the engines take them
+var engineColumns = []engineColumn{
+	{
+		Attribute: "ver",
+		Families:  []string{"ReplacingMergeTree"},
+		Types:     versionTypePattern,
+		TypeNames: "UInt*, Date, DateTime or DateTime64",
+	},
+	{
+		Attribute: "is_deleted",
+		Families:  []string{"ReplacingMergeTree"},
+		Types:     regexp.MustCompile(`^UInt8$`),
+		TypeNames: "UInt8",
+	},
+	{
+		Attribute: "sign_column",
+		Families:  []string{"CollapsingMergeTree", "VersionedCollapsingMergeTree"},
+		Types:     regexp.MustCompile(`^Int8$`),
+		TypeNames: "Int8",
+	},
+	{
+		Attribute: "version_column",
+		Families:  []string{"VersionedCollapsingMergeTree"},
+		Types:     versionTypePattern,
+		TypeNames: "UInt*, Date, DateTime or DateTime64",
+	},
+}
+
+// engineBlock returns the schema of the engine block of the table resource
+func engineBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Table engine. ClickHouse cannot change the engine of a table, so changing it replaces " +
+			"the table and loses its data",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Engine name (e.g. `MergeTree`, `ReplacingMergeTree`, `Log`, `Memory`)",
+				Required:            true,
+			},
+			"parameters": schema.ListAttribute{
+				MarkdownDescription: "Engine parameters, rendered as SQL expressions in order " +
+					"(e.g. the Keeper path and replica name of a Replicated engine)",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"ver": schema.StringAttribute{
+				MarkdownDescription: "ReplacingMergeTree version column: of the rows sharing a sorting key, the one with the " +
+					"highest version is kept. Without it, the last inserted row is kept",
+				Optional: true,
+			},
+			"is_deleted": schema.StringAttribute{
+				MarkdownDescription: "ReplacingMergeTree `UInt8` column marking deleted rows (`1`), which are removed with " +
+					"`FINAL` or `OPTIMIZE ... CLEANUP`. Requires `ver`",
+				Optional: true,
+			},
+			"sign_column": schema.StringAttribute{
+				MarkdownDescription: "CollapsingMergeTree and VersionedCollapsingMergeTree `Int8` column holding `1` for state " +
+					"rows and `-1` for the rows cancelling them",
+				Optional: true,
+			},
+			"version_column": schema.StringAttribute{
+				MarkdownDescription: "VersionedCollapsingMergeTree version column, so rows collapse correctly when inserted " +
+					"out of order. Requires `sign_column`",
+				Optional: true,
+			},
+			"sum_columns": schema.ListAttribute{
+				MarkdownDescription: "SummingMergeTree numeric columns summed when rows are merged. Without it, every numeric " +
+					"column outside of the sorting key is summed",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"zk_path": schema.StringAttribute{
+				MarkdownDescription: "Keeper path of a Replicated engine (e.g. `/clickhouse/tables/{shard}/{database}/{table}`). " +
+					"Macros are substituted by the server; without it, the server default path is used",
+				Optional: true,
+			},
+			"replica_name": schema.StringAttribute{
+				MarkdownDescription: "Replica name of a Replicated engine, defaults to `{replica}`. Requires `zk_path`",
+				Optional:            true,
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster of the servers a Distributed table reads from and writes to",
+				Optional:            true,
+			},
+			"remote_database": schema.StringAttribute{
+				MarkdownDescription: "Database of the local table of a Distributed table, or of the destination table of a Buffer table",
+				Optional:            true,
+			},
+			"remote_table": schema.StringAttribute{
+				MarkdownDescription: "Local table a Distributed table reads from and writes to on each shard, or table a Buffer " +
+					"table flushes into. A warning is reported when it does not exist yet",
+				Optional: true,
+			},
+			"sharding_key": schema.StringAttribute{
+				MarkdownDescription: "Expression choosing the shard of the rows inserted into a Distributed table (e.g. `rand()`)",
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"buffer":     bufferBlock(),
+			"kafka":      kafkaBlock(),
+			"nats":       natsBlock(),
+			"s3":         s3Block(),
+			"url":        urlBlock(),
+			"file":       fileBlock(),
+			"mysql":      mysqlBlock(),
+			"postgresql": postgresqlBlock(),
+			"join":       joinBlock(),
+		},
+		PlanModifiers: []planmodifier.Object{
+			objectplanmodifier.RequiresReplace(),
+		},
+	}
+}
+
+// bufferBlock returns the schema of the flush thresholds of a Buffer engine
+func bufferBlock() schema.Block {
+	threshold := func(description string, required bool) schema.Int64Attribute {
+		return schema.Int64Attribute{
+			MarkdownDescription: description,
+			Required:            required,
+			Optional:            !required,
+		}
+	}
+
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Flush thresholds of a Buffer engine. A layer is flushed when all the minimums or one of " +
+			"the maximums are reached",
+		Attributes: map[string]schema.Attribute{
+			"num_layers":  threshold("Number of independent buffers (e.g. `16`)", true),
+			"min_time":    threshold("Minimum time in seconds since the first write to the layer", true),
+			"max_time":    threshold("Maximum time in seconds since the first write to the layer", true),
+			"min_rows":    threshold("Minimum number of rows in the layer", true),
+			"max_rows":    threshold("Maximum number of rows in the layer", true),
+			"min_bytes":   threshold("Minimum number of bytes in the layer", true),
+			"max_bytes":   threshold("Maximum number of bytes in the layer", true),
+			"flush_time":  threshold("Time in seconds after which the layer is flushed in the background", false),
+			"flush_rows":  threshold("Number of rows after which the layer is flushed in the background. Requires `flush_time`", false),
+			"flush_bytes": threshold("Number of bytes after which the layer is flushed in the background. Requires `flush_rows`", false),
+		},
+	}
+}
+
+// arguments returns the thresholds in the order of the Buffer engine parameters
+func (m *BufferModel) arguments() []types.String {
+	var arguments []types.String
+	for _, threshold := range []types.Int64{m.NumLayers, m.MinTime, m.MaxTime, m.MinRows, m.MaxRows, m.MinBytes, m.MaxBytes,
+		m.FlushTime, m.FlushRows, m.FlushBytes} {
+		if !threshold.IsNull() {
+			arguments = append(arguments, types.StringValue(strconv.FormatInt(threshold.ValueInt64(), 10)))
+		}
+	}
+	return arguments
+}
+
+// bufferModel converts the thresholds of a Buffer engine read from ClickHouse into their Terraform model
+func bufferModel(parameters []string) (*BufferModel, bool) {
+	values := make([]types.Int64, 10)
+	for i := range values {
+		values[i] = types.Int64Null()
+	}
+	if len(parameters) < 7 || len(parameters) > len(values) {
+		return nil, false
+	}
+	for i, parameter := range parameters {
+		value, err := strconv.ParseInt(parameter, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = types.Int64Value(value)
+	}
+
+	return &BufferModel{
+		NumLayers: values[0],
+		MinTime:   values[1],
+		MaxTime:   values[2],
+		MinRows:   values[3],
+		MaxRows:   values[4],
+		MinBytes:  values[5],
+		MaxBytes:  values[6],
+
+		FlushTime:  values[7],
+		FlushRows:  values[8],
+		FlushBytes: values[9],
+	}, true
+}
+
+// columnParameters returns the values of the column parameter attributes, in the order of engineColumns
+func (m *EngineModel) columnParameters() []types.String {
+	return []types.String{m.Ver, m.IsDeleted, m.SignColumn, m.VersionColumn}
+}
+
+// arguments returns the parameters passed to the engine, starting with the Keeper path and replica name
+func (m *EngineModel) arguments() []types.String {
+	return append(m.replicationArguments(), m.engineArguments()...)
+}
+
+// replicationArguments returns the Keeper path and replica name of a Replicated engine, when configured
+func (m *EngineModel) replicationArguments() []types.String {
+	if m.ZooKeeperPath.IsNull() {
+		return nil
+	}
+	replica := "{replica}"
+	if !m.ReplicaName.IsNull() {
+		replica = m.ReplicaName.ValueString()
+	}
+	return []types.String{types.StringValue(quoteString(m.ZooKeeperPath.ValueString())), types.StringValue(quoteString(replica))}
+}
+
+// engineArguments returns the structured parameters, the parameters and the configured column parameters
+func (m *EngineModel) engineArguments() []types.String {
+	var arguments []types.String
+	if !m.Cluster.IsNull() {
+		arguments = append(arguments, types.StringValue(quoteString(m.Cluster.ValueString())))
+	}
+	if !m.RemoteDatabase.IsNull() {
+		arguments = append(arguments,
+			types.StringValue(quoteString(m.RemoteDatabase.ValueString())),
+			types.StringValue(quoteString(m.RemoteTable.ValueString())))
+	}
+	if !m.ShardingKey.IsNull() {
+		arguments = append(arguments, m.ShardingKey)
+	}
+	if m.Buffer != nil {
+		arguments = append(arguments, m.Buffer.arguments()...)
+	}
+	if m.S3 != nil {
+		arguments = append(arguments, m.S3.arguments()...)
+	}
+	if m.URL != nil {
+		arguments = append(arguments, m.URL.arguments()...)
+	}
+	if m.File != nil {
+		arguments = append(arguments, m.File.arguments()...)
+	}
+	if m.MySQL != nil {
+		arguments = append(arguments, m.MySQL.arguments()...)
+	}
+	if m.PostgreSQL != nil {
+		arguments = append(arguments, m.PostgreSQL.arguments()...)
+	}
+	if m.Join != nil {
+		arguments = append(arguments, m.Join.arguments()...)
+	}
+	arguments = append(arguments, m.Parameters...)
+	for _, column := range m.columnParameters() {
+		if !column.IsNull() {
+			arguments = append(arguments, column)
+		}
+	}
+	if len(m.SumColumns) > 0 {
+		arguments = append(arguments, types.StringValue("("+joinValues(m.SumColumns)+")"))
+	}
+	return arguments
+}
+
+// sql renders the engine clause value, e.g. ReplacingMergeTree(ver)
+func (m *EngineModel) sql() string {
+	arguments := m.arguments()
+	if len(arguments) == 0 {
+		return m.Name.ValueString()
+	}
+	return fmt.Sprintf("%s(%s)", m.Name.ValueString(), joinValues(arguments))
+}
+
+// redactedSQL renders the engine clause value with the credentials hidden the way the server reports them
+func (m *EngineModel) redactedSQL() string {
+	redacted := *m
+	if m.MySQL != nil && !m.MySQL.Password.IsNull() {
+		mysql := *m.MySQL
+		mysql.Password = types.StringValue(hiddenSecret)
+		redacted.MySQL = &mysql
+	}
+	if m.PostgreSQL != nil && !m.PostgreSQL.Password.IsNull() {
+		postgresql := *m.PostgreSQL
+		postgresql.Password = types.StringValue(hiddenSecret)
+		redacted.PostgreSQL = &postgresql
+	}
+	return redacted.sql()
+}
+
+// engineModel converts the engine_full value of a table read from ClickHouse into its Terraform model.
+// The trailing parameters of engines taking column parameters fill their dedicated attributes.
+func engineModel(engineFull string) *EngineModel {
+	name, parameters := parseEngine(engineFull)
+	model := &EngineModel{
+		Name:      types.StringValue(name),
+		Ver:       types.StringNull(),
+		IsDeleted: types.StringNull(),
+
+		SignColumn:    types.StringNull(),
+		VersionColumn: types.StringNull(),
+
+		ZooKeeperPath: types.StringNull(),
+		ReplicaName:   types.StringNull(),
+
+		Cluster:        types.StringNull(),
+		RemoteDatabase: types.StringNull(),
+		RemoteTable:    types.StringNull(),
+		ShardingKey:    types.StringNull(),
+	}
+
+	if name == "Kafka" {
+		model.Kafka = kafkaModel(parseEngineSettings(engineFull))
+	}
+	if name == "NATS" {
+		model.Nats = natsModel(parseEngineSettings(engineFull))
+	}
+
+	if name == "Distributed" && len(parameters) >= 3 {
+		model.Cluster = types.StringValue(unquoteString(parameters[0]))
+		model.RemoteDatabase = types.StringValue(unquoteString(parameters[1]))
+		model.RemoteTable = types.StringValue(unquoteString(parameters[2]))
+		if len(parameters) > 3 {
+			model.ShardingKey = types.StringValue(parameters[3])
+		}
+		for _, parameter := range parameters[min(len(parameters), 4):] {
+			model.Parameters = append(model.Parameters, types.StringValue(parameter))
+		}
+		return model
+	}
+
+	if name == "Buffer" && len(parameters) >= 2 {
+		if buffer, ok := bufferModel(parameters[2:]); ok {
+			model.RemoteDatabase = types.StringValue(unquoteString(parameters[0]))
+			model.RemoteTable = types.StringValue(unquoteString(parameters[1]))
+			model.Buffer = buffer
+			return model
+		}
+	}
+
+	if name == "S3" {
+		if s3, ok := s3Model(parameters); ok {
+			model.S3 = s3
+			return model
+		}
+	}
+	if name == "URL" {
+		if url, ok := urlModel(parameters); ok {
+			model.URL = url
+			return model
+		}
+	}
+	if name == "File" {
+		if file, ok := fileModel(parameters); ok {
+			model.File = file
+			return model
+		}
+	}
+	if name == "MySQL" {
+		if mysql, ok := mysqlModel(parameters); ok {
+			model.MySQL = mysql
+			return model
+		}
+	}
+	if name == "PostgreSQL" {
+		if postgresql, ok := postgresqlModel(parameters); ok {
+			model.PostgreSQL = postgresql
+			return model
+		}
+	}
+	if name == "Join" {
+		if join, ok := joinModel(parameters); ok {
+			model.Join = join
+			return model
+		}
+	}
+
+	family := engineFamily(name)
+	if family != name && len(parameters) >= 2 {
+		model.ZooKeeperPath = types.StringValue(unquoteString(parameters[0]))
+		model.ReplicaName = types.StringValue(unquoteString(parameters[1]))
+		parameters = parameters[2:]
+	}
+
+	// The columns to sum are passed as a tuple, or as a single column
+	if family == "SummingMergeTree" && len(parameters) == 1 {
+		columns := parameters[0]
+		if strings.HasPrefix(columns, "(") && strings.HasSuffix(columns, ")") {
+			columns = columns[1 : len(columns)-1]
+		}
+		for _, column := range splitTopLevel(columns + ")") {
+			model.SumColumns = append(model.SumColumns, types.StringValue(strings.TrimSpace(column)))
+		}
+		return model
+	}
+
+	var columns []*types.String
+	switch family {
+	case "ReplacingMergeTree":
+		columns = []*types.String{&model.Ver, &model.IsDeleted}
+	case "CollapsingMergeTree":
+		columns = []*types.String{&model.SignColumn}
+	case "VersionedCollapsingMergeTree":
+		columns = []*types.String{&model.SignColumn, &model.VersionColumn}
+	}
+	for i, parameter := range parameters {
+		if i < len(columns) {
+			*columns[i] = types.StringValue(parameter)
+			continue
+		}
+		model.Parameters = append(model.Parameters, types.StringValue(parameter))
+	}
+
+	return model
+}
+
+// parseEngine splits the engine_full value of system.tables into the engine name and its parameters
+func parseEngine(engineFull string) (string, []string) {
+	end := strings.IndexAny(engineFull, "( ")
+	if end < 0 {
+		return engineFull, nil
+	}
+	name := engineFull[:end]
+	if engineFull[end] != '(' {
+		return name, nil
+	}
+
+	var parameters []string
+	for _, parameter := range splitTopLevel(engineFull[end+1:]) {
+		if parameter = strings.TrimSpace(parameter); parameter != "" {
+			parameters = append(parameters, parameter)
+		}
+	}
+	return name, parameters
+}
+
+// validateEngine reports a missing engine block, parameter counts the server would reject and
+// column parameters that do not name a suitable column
+func validateEngine(engine types.Object, columns []ColumnModel, enginePath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if engine.IsNull() {
+		diags.AddAttributeError(enginePath, "Missing engine", "An engine block is required, e.g. engine { name = \"MergeTree\" }.")
+		return diags
+	}
+
+	attributes := engine.Attributes()
+	name, _ := attributes["name"].(types.String)
+	parameters, _ := attributes["parameters"].(types.List)
+	if name.IsUnknown() || name.IsNull() || parameters.IsUnknown() {
+		return diags
+	}
+	family := engineFamily(name.ValueString())
+
+	// Without configured columns, e.g. when they are inferred from a query, column parameters are not checked
+	var columnTypes map[string]types.String
+	if len(columns) > 0 {
+		columnTypes = make(map[string]types.String, len(columns))
+	}
+	for _, column := range columns {
+		columnTypes[column.Name.ValueString()] = column.Type.StringValue
+	}
+
+	count := len(parameters.Elements())
+	// Column parameters are positional, so one cannot be set without the preceding ones of its engine
+	missing := map[string]bool{}
+	for _, column := range engineColumns {
+		value, _ := attributes[column.Attribute].(types.String)
+		if value.IsNull() {
+			for _, columnFamily := range column.Families {
+				missing[columnFamily] = true
+			}
+			continue
+		}
+		attributePath := enginePath.AtName(column.Attribute)
+		count++
+
+		if !slices.Contains(column.Families, family) {
+			diags.AddAttributeError(attributePath, "Invalid engine parameter",
+				fmt.Sprintf("%s is only supported by the %s engines.", column.Attribute, strings.Join(column.Families, " and ")))
+			continue
+		}
+		if missing[family] {
+			diags.AddAttributeError(attributePath, "Invalid engine parameter",
+				fmt.Sprintf("%s is passed after the preceding column parameters of %s, which must be set too.", column.Attribute, family))
+		}
+		if !value.IsUnknown() {
+			diags.Append(engineColumnDiagnostics(attributePath, column.Attribute, value.ValueString(), columnTypes,
+				column.Types, column.TypeNames)...)
+		}
+	}
+
+	zooKeeperPath, _ := attributes["zk_path"].(types.String)
+	replicaName, _ := attributes["replica_name"].(types.String)
+	switch {
+	case !zooKeeperPath.IsNull() && family == name.ValueString():
+		diags.AddAttributeError(enginePath.AtName("zk_path"), "Invalid engine parameter",
+			"zk_path is only supported by the Replicated and Shared engines.")
+	case !zooKeeperPath.IsNull():
+		count += 2
+	case !replicaName.IsNull():
+		diags.AddAttributeError(enginePath.AtName("replica_name"), "Invalid engine parameter",
+			"replica_name requires zk_path.")
+	}
+
+	// Structured parameters are positional, so the ones an engine needs go together
+	var structured []string
+	for _, supported := range engineStructuredAttributes {
+		if value, ok := attributes[supported.Attribute]; !ok || value.IsNull() {
+			continue
+		}
+		structured = append(structured, supported.Attribute)
+		if !slices.Contains(supported.Engines, name.ValueString()) {
+			diags.AddAttributeError(enginePath.AtName(supported.Attribute), "Invalid engine parameter",
+				fmt.Sprintf("%s is only supported by the %s engines.", supported.Attribute, strings.Join(supported.Engines, " and ")))
+		}
+	}
+	if required := engineRequiredAttributes[name.ValueString()]; len(structured) > 0 {
+		for _, attribute := range required {
+			if !slices.Contains(structured, attribute) {
+				diags.AddAttributeError(enginePath.AtName(attribute), "Missing engine parameter",
+					fmt.Sprintf("%s tables need %s; %s is not set.", name.ValueString(), strings.Join(required, ", "), attribute))
+			}
+		}
+	}
+	if kafka, _ := attributes["kafka"].(types.Object); !kafka.IsNull() && !kafka.IsUnknown() {
+		settings, _ := kafka.Attributes()["settings"].(types.Map)
+		diags.Append(validateEngineSettings("kafka", "kafka_", kafkaAttributeSettings(), settings,
+			enginePath.AtName("kafka").AtName("settings"))...)
+	}
+	if nats, _ := attributes["nats"].(types.Object); !nats.IsNull() && !nats.IsUnknown() {
+		settings, _ := nats.Attributes()["settings"].(types.Map)
+		diags.Append(validateEngineSettings("nats", "nats_", natsAttributeSettings(), settings,
+			enginePath.AtName("nats").AtName("settings"))...)
+	}
+	if s3, _ := attributes["s3"].(types.Object); !s3.IsNull() && !s3.IsUnknown() {
+		s3Attributes := s3.Attributes()
+		if s3Attributes["path"].IsNull() && s3Attributes["named_collection"].IsNull() {
+			diags.AddAttributeError(enginePath.AtName("s3").AtName("path"), "Missing engine parameter",
+				"path is required unless named_collection is set.")
+		}
+	}
+	if join, _ := attributes["join"].(types.Object); !join.IsNull() && !join.IsUnknown() {
+		diags.Append(validateJoin(join, columnTypes, enginePath.AtName("join"))...)
+	}
+	if buffer, _ := attributes["buffer"].(types.Object); !buffer.IsNull() && !buffer.IsUnknown() {
+		thresholds := buffer.Attributes()
+		for i, attribute := range []string{"flush_rows", "flush_bytes"} {
+			previous := []string{"flush_time", "flush_rows"}[i]
+			if !thresholds[attribute].IsNull() && thresholds[previous].IsNull() {
+				diags.AddAttributeError(enginePath.AtName("buffer").AtName(attribute), "Missing engine parameter",
+					fmt.Sprintf("%s is passed after %s, which must be set too.", attribute, previous))
+			}
+		}
+	}
+
+	if sumColumns, _ := attributes["sum_columns"].(types.List); !sumColumns.IsNull() {
+		attributePath := enginePath.AtName("sum_columns")
+		count++
+
+		if family != "SummingMergeTree" {
+			diags.AddAttributeError(attributePath, "Invalid engine parameter",
+				"sum_columns is only supported by the SummingMergeTree engines.")
+		} else if !sumColumns.IsUnknown() {
+			for i, element := range sumColumns.Elements() {
+				value, _ := element.(types.String)
+				if value.IsUnknown() || value.IsNull() {
+					continue
+				}
+				diags.Append(engineColumnDiagnostics(attributePath.AtListIndex(i), "sum_columns", value.ValueString(), columnTypes,
+					numericTypePattern, "numeric")...)
+			}
+		}
+	}
+
+	bounds, ok := engineParameterCounts[family]
+	if !ok {
+		return diags
+	}
+
+	valid := count >= bounds[0] && count <= bounds[1]
+	// Replicated and Shared engines take the Keeper path and replica name first, unless the server defaults are used
+	if family != name.ValueString() && count >= 2 {
+		valid = valid || (count-2 >= bounds[0] && count-2 <= bounds[1])
+	}
+	if !valid {
+		expected := fmt.Sprintf("%d", bounds[0])
+		if bounds[1] != bounds[0] {
+			expected = fmt.Sprintf("%d to %d", bounds[0], bounds[1])
+		}
+		diags.AddAttributeError(enginePath.AtName("parameters"), "Invalid engine parameters",
+			fmt.Sprintf("%s takes %s parameters, %d given.", family, expected, count))
+	}
+
+	return diags
+}
+
+// engineColumnDiagnostics reports an engine parameter naming a column that is not defined or has an unsuitable type
+func engineColumnDiagnostics(attributePath path.Path, attribute, column string, columnTypes map[string]types.String,
+	pattern *regexp.Regexp, typeNames string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	columnType, ok := columnTypes[column]
+	switch {
+	case columnTypes == nil:
+	case !ok:
+		diags.AddAttributeError(attributePath, "Unknown engine column",
+			fmt.Sprintf("%s refers to column %s, which is not defined.", attribute, column))
+	case !columnType.IsUnknown() && !pattern.MatchString(columnType.ValueString()):
+		diags.AddAttributeError(attributePath, "Invalid engine column type",
+			fmt.Sprintf("%s column %s has type %s, but must be %s.", attribute, column, columnType.ValueString(), typeNames))
+	}
+
+	return diags
+}
+
+// engineFamily returns the engine name without the Replicated or Shared prefix of its replicated variants
+func engineFamily(name string) string {
+	for _, prefix := range []string{"Replicated", "Shared"} {
+		if family, ok := strings.CutPrefix(name, prefix); ok && strings.HasSuffix(family, "MergeTree") {
+			return family
+		}
+	}
+	return name
+}
+
+// sameEngine reports whether a table created with the configured engine reports the actual one.
+// ClickHouse Cloud converts the MergeTree engines into their Shared variants.
+func sameEngine(configured, actual string) bool {
+	return configured == actual || (engineFamily(actual) != actual && "Shared"+engineFamily(configured) == actual)
+}
+
+// engineParametersMatch compares the configured engine parameters with the ones reported in engine_full.
+// Replicated engines configured without a Keeper path and replica name are reported with the server defaults.
+func engineParametersMatch(ctx context.Context, client *clickhouseClient, engine *EngineModel, actualName, database, table string, actual []string) bool {
+	expected := engine.engineArguments()
+	if engineFamily(engine.Name.ValueString()) != engine.Name.ValueString() || engineFamily(actualName) != actualName {
+		switch {
+		case !engine.ZooKeeperPath.IsNull():
+			replication := engine.replicationArguments()
+			if len(actual) < 2 ||
+				!replicationArgumentMatches(unquoteString(replication[0].ValueString()), unquoteString(actual[0]), database, table) ||
+				!replicationArgumentMatches(unquoteString(replication[1].ValueString()), unquoteString(actual[1]), database, table) {
+				return false
+			}
+			actual = actual[2:]
+		case len(actual) == len(expected)+2:
+			actual = actual[2:]
+		}
+	}
+	if len(actual) != len(expected) {
+		return false
+	}
+
+	for i, parameter := range expected {
+		// Credentials are hidden by the server, so they cannot be compared
+		if unquoteString(actual[i]) == hiddenSecret {
+			continue
+		}
+		if !expressionsEquivalent(ctx, client, parameter.ValueString(), actual[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// replicationArgumentMatches compares a configured Keeper path or replica name with the one stored by the server,
+// which substitutes the {database}, {table} and {uuid} macros when the table is created
+func replicationArgumentMatches(expected, actual, database, table string) bool {
+	pattern := regexp.QuoteMeta(expected)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{database}"), "("+regexp.QuoteMeta(database)+`|\{database\})`)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{table}"), "("+regexp.QuoteMeta(table)+`|\{table\})`)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{uuid}"), "("+uuidPattern+`|\{uuid\})`)
+	return regexp.MustCompile("^" + pattern + "$").MatchString(actual)
+}
+
+// unquoteString removes the quotes of a string literal, leaving other values unchanged
+func unquoteString(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+	return strings.NewReplacer(`\\`, `\`, `\'`, `'`).Replace(value[1 : len(value)-1])
+}
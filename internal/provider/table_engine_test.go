@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSameEngine(t *testing.T) {
+	tests := []struct {
+		configured, actual string
+		expected           bool
+	}{
+		{"MergeTree", "MergeTree", true},
+		{"ReplacingMergeTree", "SharedReplacingMergeTree", true},
+		{"MergeTree", "SharedMergeTree", true},
+		{"ReplicatedMergeTree", "SharedMergeTree", true},
+		{"MergeTree", "ReplacingMergeTree", false},
+		{"MergeTree", "ReplicatedMergeTree", false},
+		{"Memory", "SharedMemory", false},
+	}
+
+	for _, test := range tests {
+		if actual := sameEngine(test.configured, test.actual); actual != test.expected {
+			t.Errorf("sameEngine(%q, %q) = %t, expected %t", test.configured, test.actual, actual, test.expected)
+		}
+	}
+}
+
+func TestEngineParametersMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		actual     string
+		expected   bool
+	}{
+		{"no parameters", "MergeTree", "MergeTree", true},
+		{"version column", "ReplacingMergeTree(ver)", "ReplacingMergeTree(ver)", true},
+		{"changed version column", "ReplacingMergeTree(ver)", "ReplacingMergeTree(updated_at)", false},
+		{"added parameter", "ReplacingMergeTree", "ReplacingMergeTree(ver)", false},
+		{"Keeper path macros", "ReplicatedMergeTree('/clickhouse/tables/{database}/{table}', '{replica}')",
+			"ReplicatedMergeTree('/clickhouse/tables/analytics/events', '{replica}')", true},
+		{"other Keeper path", "ReplicatedMergeTree('/clickhouse/tables/{database}/{table}', '{replica}')",
+			"ReplicatedMergeTree('/clickhouse/tables/archive/events', '{replica}')", false},
+		{"server default Keeper path", "ReplicatedReplacingMergeTree(ver)",
+			"ReplicatedReplacingMergeTree('/clickhouse/tables/{uuid}/{shard}', '{replica}', ver)", true},
+		{"ClickHouse Cloud", "ReplacingMergeTree(ver)",
+			"SharedReplacingMergeTree('/clickhouse/tables/{uuid}/{shard}', '{replica}', ver)", true},
+		{"sharding key", "Distributed('main', 'analytics', 'events', rand())",
+			"Distributed('main', 'analytics', 'events', rand())", true},
+		{"changed sharding key", "Distributed('main', 'analytics', 'events', rand())",
+			"Distributed('main', 'analytics', 'events', cityHash64(id))", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualName, actualParameters := parseEngine(test.actual)
+			actual := engineParametersMatch(context.Background(), nil, engineModel(test.configured), actualName, "analytics", "events", actualParameters)
+			if actual != test.expected {
+				t.Errorf("engineParametersMatch(%q, %q) = %t, expected %t", test.configured, test.actual, actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestReplicationArgumentMatches(t *testing.T) {
+	tests := []struct {
+		expected, actual string
+		matches          bool
+	}{
+		{"/clickhouse/tables/{database}/{table}", "/clickhouse/tables/analytics/events", true},
+		{"/clickhouse/tables/{database}/{table}", "/clickhouse/tables/{database}/{table}", true},
+		{"/clickhouse/tables/{uuid}/{shard}", "/clickhouse/tables/0e3c8a1c-5b4e-4f6a-9c1d-2b7e8f9a0b1c/{shard}", true},
+		{"/clickhouse/tables/{database}/{table}", "/clickhouse/tables/archive/events", false},
+		{"/clickhouse/tables/{uuid}/{shard}", "/clickhouse/tables/events/{shard}", false},
+		{"{replica}", "{replica}", true},
+		{"{replica}", "replica-1", false},
+	}
+
+	for _, test := range tests {
+		if actual := replicationArgumentMatches(test.expected, test.actual, "analytics", "events"); actual != test.matches {
+			t.Errorf("replicationArgumentMatches(%q, %q) = %t, expected %t", test.expected, test.actual, actual, test.matches)
+		}
+	}
+
+	// The table name is matched literally, not as a pattern
+	if replicationArgumentMatches("/tables/{table}", "/tables/evXnts", "analytics", "ev.nts") {
+		t.Error("expected the dot of the table name to match only itself")
+	}
+}
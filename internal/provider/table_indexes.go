@@ -0,0 +1,407 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// IndexModel describes a data skipping index of a table.
+type IndexModel struct {
+	Name        types.String `tfsdk:"name"`
+	Expression  types.String `tfsdk:"expression"`
+	Type        types.String `tfsdk:"type"`
+	Granularity types.Int64  `tfsdk:"granularity"`
+}
+
+// IndexInfo represents a data skipping index as reported by ClickHouse
+type IndexInfo struct {
+	Name        string `json:"name"`
+	Expression  string `json:"expression"`
+	Type        string `json:"type"`
+	Granularity int64  `json:"granularity"`
+}
+
+// indexesBlock returns the schema of the indexes block of the table resource
+func indexesBlock() schema.Block {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "Data skipping indexes, added and dropped in place with `ALTER TABLE ... ADD/DROP INDEX`",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					MarkdownDescription: "Index name",
+					Required:            true,
+				},
+				"expression": schema.StringAttribute{
+					MarkdownDescription: "Indexed expression (e.g. `lower(message)`)",
+					Required:            true,
+				},
+				"type": schema.StringAttribute{
+					MarkdownDescription: "Index type with its parameters (e.g. `minmax`, `set(100)`, `bloom_filter(0.01)`, `tokenbf_v1(10240, 3, 0)`). " +
+						"Full-text (`full_text(0)`, `inverted(3)`) and vector similarity (`vector_similarity('hnsw', 'L2Distance', 768)`, " +
+						"`usearch('cosineDistance')`, `annoy('L2Distance', 100)`) indexes are checked against the server version, and the " +
+						"experimental setting they need is enabled for the statements creating them",
+					Required: true,
+				},
+				"granularity": schema.Int64Attribute{
+					MarkdownDescription: "Number of granules summarized by each index block",
+					Optional:            true,
+					Computed:            true,
+					Default:             int64default.StaticInt64(1),
+				},
+			},
+		},
+	}
+}
+
+// info converts the index model into an index definition
+func (m IndexModel) info() IndexInfo {
+	return IndexInfo{
+		Name:        m.Name.ValueString(),
+		Expression:  m.Expression.ValueString(),
+		Type:        m.Type.ValueString(),
+		Granularity: m.Granularity.ValueInt64(),
+	}
+}
+
+// indexModel converts an index read from ClickHouse into its Terraform model
+func indexModel(index IndexInfo) IndexModel {
+	return IndexModel{
+		Name:        types.StringValue(index.Name),
+		Expression:  types.StringValue(index.Expression),
+		Type:        types.StringValue(index.Type),
+		Granularity: types.Int64Value(index.Granularity),
+	}
+}
+
+// indexDefinitionSQL renders the INDEX clause of a data skipping index
+func indexDefinitionSQL(index IndexInfo) string {
+	return fmt.Sprintf("INDEX %s %s TYPE %s GRANULARITY %d", quoteIdentifier(index.Name), index.Expression, index.Type, index.Granularity)
+}
+
+// readTableIndexes reads the data skipping indexes of a table from system.data_skipping_indices
+func readTableIndexes(ctx context.Context, client *clickhouseClient, database, table string) ([]IndexInfo, error) {
+	query := `
+        SELECT name, expr, type_full, granularity
+        FROM system.data_skipping_indices
+        WHERE database = ? AND table = ?
+    `
+
+	rows, err := client.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		var index IndexInfo
+		var granularity uint64
+		if err := rows.Scan(&index.Name, &index.Expression, &index.Type, &granularity); err != nil {
+			return nil, err
+		}
+		index.Granularity = int64(granularity)
+		indexes = append(indexes, index)
+	}
+
+	return indexes, rows.Err()
+}
+
+// reconcileIndexes builds the indexes to store in state from the ones found on the server,
+// keeping the configured spelling of equivalent indexes and the configured order
+func reconcileIndexes(ctx context.Context, client *clickhouseClient, configured []IndexModel, actual []IndexInfo) []IndexModel {
+	found := make(map[string]IndexInfo, len(actual))
+	for _, index := range actual {
+		found[index.Name] = index
+	}
+
+	var indexes []IndexModel
+	if configured != nil {
+		indexes = []IndexModel{}
+	}
+	seen := map[string]bool{}
+	for _, model := range configured {
+		index, ok := found[model.Name.ValueString()]
+		if !ok {
+			continue
+		}
+		seen[index.Name] = true
+
+		want := model.info()
+		if want.Granularity == index.Granularity && normalizeQuery(want.Type) == normalizeQuery(index.Type) &&
+			expressionsEquivalent(ctx, client, want.Expression, index.Expression) {
+			indexes = append(indexes, model)
+		} else {
+			indexes = append(indexes, indexModel(index))
+		}
+	}
+
+	// Indexes added outside of Terraform are kept so the next apply drops them
+	for _, index := range actual {
+		if !seen[index.Name] {
+			indexes = append(indexes, indexModel(index))
+		}
+	}
+
+	return indexes
+}
+
+// indexAlterStatements generates the ALTER TABLE statements turning the current indexes into the desired ones,
+// returning the drops and the additions separately. Changed indexes are dropped and added again.
+func indexAlterStatements(table string, current, desired []IndexInfo) ([]string, []string) {
+	existing := make(map[string]IndexInfo, len(current))
+	for _, index := range current {
+		existing[index.Name] = index
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	var drops, adds []string
+	for _, index := range desired {
+		wanted[index.Name] = true
+
+		have, ok := existing[index.Name]
+		if ok && have == index {
+			continue
+		}
+		if ok {
+			drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", table, quoteIdentifier(index.Name)))
+		}
+		adds = append(adds, fmt.Sprintf("ALTER TABLE %s ADD %s", table, indexDefinitionSQL(index)))
+	}
+
+	for _, index := range current {
+		if !wanted[index.Name] {
+			drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", table, quoteIdentifier(index.Name)))
+		}
+	}
+
+	return drops, adds
+}
+
+// specializedIndexType describes a full-text or vector similarity index type, only available on some
+// server versions and behind an experimental setting until it became generally available
+type specializedIndexType struct {
+	// Since and Until bound the versions supporting the type, Until being excluded and unbounded when empty
+	Since, Until string
+
+	// Setting enables the type on the versions before SettingUntil, or all of them when empty
+	Setting      string
+	SettingUntil string
+
+	// Validate checks the parameters of the type
+	Validate func(parameters []string) error
+}
+
+var specializedIndexTypes = map[string]specializedIndexType{
+	"inverted":          {Since: "23.1", Setting: "allow_experimental_inverted_index", Validate: validateFullTextIndexParameters},
+	"full_text":         {Since: "24.1", Setting: "allow_experimental_full_text_index", Validate: validateFullTextIndexParameters},
+	"annoy":             {Since: "23.1", Until: "24.8", Setting: "allow_experimental_annoy_index", Validate: validateAnnoyIndexParameters},
+	"usearch":           {Since: "23.8", Until: "24.8", Setting: "allow_experimental_usearch_index", Validate: validateUsearchIndexParameters},
+	"vector_similarity": {Since: "24.8", Setting: "allow_experimental_vector_similarity_index", SettingUntil: "25.8", Validate: validateVectorSimilarityIndexParameters},
+}
+
+var (
+	vectorDistanceFunctions = []string{"L2Distance", "cosineDistance"}
+	vectorScalarKinds       = []string{"f64", "f32", "f16", "bf16", "i8", "b1"}
+)
+
+// validateIndexType checks the parameters of full-text and vector similarity index types
+func validateIndexType(indexType types.String, typePath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if indexType.IsNull() || indexType.IsUnknown() {
+		return diags
+	}
+
+	name, parameters := parseEngine(strings.TrimSpace(indexType.ValueString()))
+	specialized, ok := specializedIndexTypes[name]
+	if !ok {
+		return diags
+	}
+	if err := specialized.Validate(parameters); err != nil {
+		diags.AddAttributeError(typePath, "Invalid index type", fmt.Sprintf("%s: %s.", indexType.ValueString(), err.Error()))
+	}
+	return diags
+}
+
+// validateFullTextIndexParameters checks the ([ngram_size[, max_rows_per_postings_list]]) parameters of a full-text index
+func validateFullTextIndexParameters(parameters []string) error {
+	if len(parameters) > 2 {
+		return fmt.Errorf("expected at most 2 parameters, got %d", len(parameters))
+	}
+	if len(parameters) > 0 {
+		if ngrams, err := strconv.Atoi(parameters[0]); err != nil || ngrams < 0 || ngrams > 8 {
+			return fmt.Errorf("the n-gram size must be between 0 (tokens) and 8, got %s", parameters[0])
+		}
+	}
+	if len(parameters) > 1 {
+		if rows, err := strconv.ParseUint(parameters[1], 10, 64); err != nil || (rows != 0 && rows < 8192) {
+			return fmt.Errorf("the maximum rows per postings list must be 0 (unlimited) or at least 8192, got %s", parameters[1])
+		}
+	}
+	return nil
+}
+
+// validateAnnoyIndexParameters checks the ([distance_function[, trees]]) parameters of an Annoy index
+func validateAnnoyIndexParameters(parameters []string) error {
+	if len(parameters) > 2 {
+		return fmt.Errorf("expected at most 2 parameters, got %d", len(parameters))
+	}
+	if len(parameters) > 0 {
+		if err := validateIndexParameterChoice("distance function", parameters[0], vectorDistanceFunctions); err != nil {
+			return err
+		}
+	}
+	if len(parameters) > 1 {
+		if err := validatePositiveIndexParameter("number of trees", parameters[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateUsearchIndexParameters checks the ([distance_function[, scalar_kind]]) parameters of a USearch index
+func validateUsearchIndexParameters(parameters []string) error {
+	if len(parameters) > 2 {
+		return fmt.Errorf("expected at most 2 parameters, got %d", len(parameters))
+	}
+	if len(parameters) > 0 {
+		if err := validateIndexParameterChoice("distance function", parameters[0], vectorDistanceFunctions); err != nil {
+			return err
+		}
+	}
+	if len(parameters) > 1 {
+		if err := validateIndexParameterChoice("scalar kind", parameters[1], vectorScalarKinds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateVectorSimilarityIndexParameters checks the (method, distance_function[, dimensions][, quantization,
+// hnsw_max_connections_per_layer, hnsw_candidate_list_size_for_construction]) parameters of a vector similarity
+// index. Servers before 25.1 do not take the dimensions.
+func validateVectorSimilarityIndexParameters(parameters []string) error {
+	if len(parameters) < 2 || len(parameters) > 6 || len(parameters) == 4 {
+		return fmt.Errorf("expected 2, 3, 5 or 6 parameters, got %d", len(parameters))
+	}
+	if err := validateIndexParameterChoice("method", parameters[0], []string{"hnsw"}); err != nil {
+		return err
+	}
+	if err := validateIndexParameterChoice("distance function", parameters[1], vectorDistanceFunctions); err != nil {
+		return err
+	}
+	hnsw := parameters[2:]
+	if len(parameters) == 3 || len(parameters) == 6 {
+		if err := validatePositiveIndexParameter("number of dimensions", parameters[2]); err != nil {
+			return err
+		}
+		hnsw = parameters[3:]
+	}
+	if len(hnsw) == 0 {
+		return nil
+	}
+	if err := validateIndexParameterChoice("quantization", hnsw[0], vectorScalarKinds); err != nil {
+		return err
+	}
+	if err := validatePositiveIndexParameter("number of HNSW connections per layer", hnsw[1]); err != nil {
+		return err
+	}
+	return validatePositiveIndexParameter("HNSW candidate list size", hnsw[2])
+}
+
+// validateIndexParameterChoice checks that a string literal parameter is one of the accepted values
+func validateIndexParameterChoice(parameter, value string, accepted []string) error {
+	if !strings.HasPrefix(value, "'") || !slices.Contains(accepted, unquoteString(value)) {
+		return fmt.Errorf("the %s must be one of '%s', got %s", parameter, strings.Join(accepted, "', '"), value)
+	}
+	return nil
+}
+
+// validatePositiveIndexParameter checks that a parameter is a positive integer
+func validatePositiveIndexParameter(parameter, value string) error {
+	if n, err := strconv.ParseUint(value, 10, 64); err != nil || n == 0 {
+		return fmt.Errorf("the %s must be a positive integer, got %s", parameter, value)
+	}
+	return nil
+}
+
+// withIndexSettings checks that the server supports the full-text and vector similarity indexes about to be
+// created and returns a context enabling the experimental settings they need. Indexes left unchanged from
+// current are not checked.
+func withIndexSettings(ctx context.Context, client *clickhouseClient, current, desired []IndexInfo) (context.Context, error) {
+	var added []IndexInfo
+	for _, index := range desired {
+		if _, ok := specializedIndexTypes[indexTypeName(index.Type)]; ok && !slices.Contains(current, index) {
+			added = append(added, index)
+		}
+	}
+	if len(added) == 0 {
+		return ctx, nil
+	}
+
+	var version string
+	if err := client.QueryRowContext(ctx, "SELECT version()").Scan(&version); err != nil {
+		return ctx, fmt.Errorf("could not read the server version: %w", err)
+	}
+
+	settings, err := indexSettings(version, added)
+	if err != nil {
+		return ctx, err
+	}
+	return withDefaultSettings(ctx, settings), nil
+}
+
+// indexSettings returns the experimental settings needed to create the indexes on a server version,
+// failing when one of them is not supported by that version
+func indexSettings(version string, indexes []IndexInfo) (clickhouse.Settings, error) {
+	settings := clickhouse.Settings{}
+	for _, index := range indexes {
+		name := indexTypeName(index.Type)
+		specialized, ok := specializedIndexTypes[name]
+		if !ok {
+			continue
+		}
+		if !versionAtLeast(version, specialized.Since) {
+			return nil, fmt.Errorf("index %s of type %s needs ClickHouse %s or later, the server runs %s", index.Name, name, specialized.Since, version)
+		}
+		if specialized.Until != "" && versionAtLeast(version, specialized.Until) {
+			return nil, fmt.Errorf("index %s of type %s is not supported since ClickHouse %s, the server runs %s. "+
+				"Use a vector_similarity index instead", index.Name, name, specialized.Until, version)
+		}
+		if specialized.SettingUntil == "" || !versionAtLeast(version, specialized.SettingUntil) {
+			settings[specialized.Setting] = 1
+		}
+	}
+	return settings, nil
+}
+
+// indexTypeName returns the name of an index type without its parameters
+func indexTypeName(indexType string) string {
+	name, _ := parseEngine(strings.TrimSpace(indexType))
+	return name
+}
+
+// versionAtLeast checks whether a server version (e.g. 24.8.4.13) is at least the given major.minor version
+func versionAtLeast(version, minimum string) bool {
+	actual, wanted := strings.Split(version, "."), strings.Split(minimum, ".")
+	for i, part := range wanted {
+		if i >= len(actual) {
+			return false
+		}
+		a, _ := strconv.Atoi(actual[i])
+		w, _ := strconv.Atoi(part)
+		if a != w {
+			return a > w
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidateIndexType(t *testing.T) {
+	tests := map[string]bool{
+		"minmax":                                  true,
+		"bloom_filter(0.01)":                      true,
+		"full_text":                               true,
+		"full_text(0)":                            true,
+		"inverted(3, 8192)":                       true,
+		"full_text(9)":                            false,
+		"inverted(0, 100)":                        false,
+		"annoy('L2Distance', 100)":                true,
+		"annoy('dotProduct')":                     false,
+		"usearch('cosineDistance')":               true,
+		"usearch('L2Distance', 'q')":              false,
+		"vector_similarity('hnsw', 'L2Distance')": true,
+		"vector_similarity('hnsw', 'cosineDistance', 768)":             true,
+		"vector_similarity('hnsw', 'L2Distance', 'bf16', 64, 256)":     true,
+		"vector_similarity('hnsw', 'L2Distance', 768, 'f32', 32, 128)": true,
+		"vector_similarity('hnsw')":                                    false,
+		"vector_similarity('ivf', 'L2Distance', 768)":                  false,
+		"vector_similarity('hnsw', 'L2Distance', 0)":                   false,
+		"vector_similarity('hnsw', 'L2Distance', 768, 'f32')":          false,
+	}
+
+	for indexType, valid := range tests {
+		diags := validateIndexType(types.StringValue(indexType), path.Root("type"))
+		if diags.HasError() == valid {
+			t.Errorf("validateIndexType(%q): expected valid = %t, got %v", indexType, valid, diags)
+		}
+	}
+}
+
+func TestIndexSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		index    string
+		settings clickhouse.Settings
+		fails    bool
+	}{
+		{"plain index", "23.3.1.1", "bloom_filter(0.01)", clickhouse.Settings{}, false},
+		{"experimental vector index", "24.8.4.13", "vector_similarity('hnsw', 'L2Distance')",
+			clickhouse.Settings{"allow_experimental_vector_similarity_index": 1}, false},
+		{"generally available vector index", "25.8.1.1", "vector_similarity('hnsw', 'L2Distance', 768)", clickhouse.Settings{}, false},
+		{"vector index before its release", "24.3.1.1", "vector_similarity('hnsw', 'L2Distance')", nil, true},
+		{"removed index type", "24.8.1.1", "annoy('L2Distance', 100)", nil, true},
+		{"usearch index", "24.3.2.23", "usearch('cosineDistance')", clickhouse.Settings{"allow_experimental_usearch_index": 1}, false},
+		{"full-text index", "24.10.1.1", "full_text(0)", clickhouse.Settings{"allow_experimental_full_text_index": 1}, false},
+		{"inverted index", "23.8.1.1", "inverted(3)", clickhouse.Settings{"allow_experimental_inverted_index": 1}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := indexSettings(test.version, []IndexInfo{{Name: "idx", Expression: "embedding", Type: test.index, Granularity: 1}})
+			if test.fails {
+				if err == nil {
+					t.Fatalf("expected an error, got settings %v", settings)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(settings) != len(test.settings) {
+				t.Fatalf("expected settings %v, got %v", test.settings, settings)
+			}
+			for name, value := range test.settings {
+				if settings[name] != value {
+					t.Errorf("expected settings %v, got %v", test.settings, settings)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version, minimum string
+		expected         bool
+	}{
+		{"24.8.4.13", "24.8", true},
+		{"24.10.1.1", "24.8", true},
+		{"24.3.1.1", "24.8", false},
+		{"25.1.1.1", "24.8", true},
+		{"23.12.1.1", "24.1", false},
+	}
+
+	for _, test := range tests {
+		if actual := versionAtLeast(test.version, test.minimum); actual != test.expected {
+			t.Errorf("versionAtLeast(%q, %q) = %t, expected %t", test.version, test.minimum, actual, test.expected)
+		}
+	}
+}
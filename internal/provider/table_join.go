@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// joinStrictnesses and joinKinds list the values accepted by the Join engine
+var (
+	joinStrictnesses = []string{"ANY", "ALL", "SEMI", "ANTI", "ASOF"}
+	joinKinds        = []string{"LEFT", "INNER", "RIGHT", "FULL", "CROSS"}
+)
+
+// JoinModel describes the parameters of a Join engine.
+type JoinModel struct {
+	Strictness types.String   `tfsdk:"strictness"`
+	Kind       types.String   `tfsdk:"kind"`
+	Keys       []types.String `tfsdk:"keys"`
+}
+
+// joinBlock returns the schema of the parameters of a Join engine
+func joinBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Parameters of a Join engine, keeping the right side of a `JOIN` in memory. The table can " +
+			"only be used in joins with the same strictness and kind",
+		Attributes: map[string]schema.Attribute{
+			"strictness": schema.StringAttribute{
+				MarkdownDescription: "Join strictness: `ANY`, `ALL`, `SEMI`, `ANTI` or `ASOF`",
+				Required:            true,
+			},
+			"kind": schema.StringAttribute{
+				MarkdownDescription: "Join kind: `LEFT`, `INNER`, `RIGHT`, `FULL` or `CROSS`",
+				Required:            true,
+			},
+			"keys": schema.ListAttribute{
+				MarkdownDescription: "Key columns of the `USING` clause of the joins",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+// arguments returns the parameters of the Join engine
+func (m *JoinModel) arguments() []types.String {
+	return append([]types.String{m.Strictness, m.Kind}, m.Keys...)
+}
+
+// joinModel converts the parameters of a Join engine read from ClickHouse into their Terraform model
+func joinModel(parameters []string) (*JoinModel, bool) {
+	if len(parameters) < 3 {
+		return nil, false
+	}
+
+	model := &JoinModel{
+		Strictness: types.StringValue(parameters[0]),
+		Kind:       types.StringValue(parameters[1]),
+	}
+	for _, key := range parameters[2:] {
+		model.Keys = append(model.Keys, types.StringValue(key))
+	}
+	return model, true
+}
+
+// validateJoin reports an unknown strictness or kind and keys that do not name a column of the table
+func validateJoin(join types.Object, columnTypes map[string]types.String, joinPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	attributes := join.Attributes()
+
+	for _, parameter := range []struct {
+		Attribute string
+		Values    []string
+	}{{"strictness", joinStrictnesses}, {"kind", joinKinds}} {
+		value, _ := attributes[parameter.Attribute].(types.String)
+		if value.IsNull() || value.IsUnknown() || slices.Contains(parameter.Values, strings.ToUpper(value.ValueString())) {
+			continue
+		}
+		diags.AddAttributeError(joinPath.AtName(parameter.Attribute), "Invalid engine parameter",
+			fmt.Sprintf("%s must be one of %s, got %s.", parameter.Attribute, strings.Join(parameter.Values, ", "), value.ValueString()))
+	}
+
+	keys, _ := attributes["keys"].(types.List)
+	if keys.IsNull() || keys.IsUnknown() {
+		return diags
+	}
+	if len(keys.Elements()) == 0 {
+		diags.AddAttributeError(joinPath.AtName("keys"), "Missing engine parameter", "A Join table needs at least one key column.")
+	}
+	if columnTypes == nil {
+		return diags
+	}
+	for i, element := range keys.Elements() {
+		key, _ := element.(types.String)
+		if key.IsNull() || key.IsUnknown() {
+			continue
+		}
+		if _, ok := columnTypes[key.ValueString()]; !ok {
+			diags.AddAttributeError(joinPath.AtName("keys").AtListIndex(i), "Unknown engine column",
+				fmt.Sprintf("keys refers to column %s, which is not defined.", key.ValueString()))
+		}
+	}
+
+	return diags
+}
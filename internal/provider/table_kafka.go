@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// hiddenSecret is reported by the server instead of the value of secret settings
+const hiddenSecret = "[HIDDEN]"
+
+// KafkaModel describes the settings of a Kafka engine.
+type KafkaModel struct {
+	BrokerList   types.String `tfsdk:"broker_list"`
+	TopicList    types.String `tfsdk:"topic_list"`
+	GroupName    types.String `tfsdk:"group_name"`
+	Format       types.String `tfsdk:"format"`
+	NumConsumers types.Int64  `tfsdk:"num_consumers"`
+
+	SecurityProtocol types.String `tfsdk:"security_protocol"`
+	SASLMechanism    types.String `tfsdk:"sasl_mechanism"`
+	SASLUsername     types.String `tfsdk:"sasl_username"`
+	SASLPassword     types.String `tfsdk:"sasl_password"`
+
+	Settings map[string]types.String `tfsdk:"settings"`
+}
+
+// kafkaBlock returns the schema of the settings of a Kafka engine
+func kafkaBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Settings of a Kafka engine, rendered in the `SETTINGS` clause of the table",
+		Attributes: map[string]schema.Attribute{
+			"broker_list": schema.StringAttribute{
+				MarkdownDescription: "Comma separated list of brokers (`kafka_broker_list`, e.g. `kafka-1:9092,kafka-2:9092`)",
+				Required:            true,
+			},
+			"topic_list": schema.StringAttribute{
+				MarkdownDescription: "Comma separated list of topics (`kafka_topic_list`)",
+				Required:            true,
+			},
+			"group_name": schema.StringAttribute{
+				MarkdownDescription: "Consumer group name (`kafka_group_name`)",
+				Required:            true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "Message format (`kafka_format`, e.g. `JSONEachRow`)",
+				Required:            true,
+			},
+			"num_consumers": schema.Int64Attribute{
+				MarkdownDescription: "Number of consumers per table (`kafka_num_consumers`)",
+				Optional:            true,
+			},
+			"security_protocol": schema.StringAttribute{
+				MarkdownDescription: "Protocol used to communicate with the brokers (`kafka_security_protocol`, e.g. `sasl_ssl`)",
+				Optional:            true,
+			},
+			"sasl_mechanism": schema.StringAttribute{
+				MarkdownDescription: "SASL mechanism (`kafka_sasl_mechanism`, e.g. `SCRAM-SHA-512`)",
+				Optional:            true,
+			},
+			"sasl_username": schema.StringAttribute{
+				MarkdownDescription: "SASL username (`kafka_sasl_username`)",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"sasl_password": schema.StringAttribute{
+				MarkdownDescription: "SASL password (`kafka_sasl_password`). The server hides it, so changes made outside of " +
+					"Terraform are not detected",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"settings": schema.MapAttribute{
+				MarkdownDescription: "Other Kafka engine settings by full name (e.g. `kafka_max_block_size`, `kafka_handle_error_mode`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+// fields maps the Kafka engine settings to the attributes holding them
+func (m *KafkaModel) fields() map[string]*types.String {
+	return map[string]*types.String{
+		"kafka_broker_list":       &m.BrokerList,
+		"kafka_topic_list":        &m.TopicList,
+		"kafka_group_name":        &m.GroupName,
+		"kafka_format":            &m.Format,
+		"kafka_security_protocol": &m.SecurityProtocol,
+		"kafka_sasl_mechanism":    &m.SASLMechanism,
+		"kafka_sasl_username":     &m.SASLUsername,
+	}
+}
+
+// settings returns the engine settings of the Kafka engine, without the secret ones
+func (m *KafkaModel) settings() map[string]string {
+	settings := map[string]string{}
+	for name, value := range m.fields() {
+		if !value.IsNull() {
+			settings[name] = value.ValueString()
+		}
+	}
+	if !m.NumConsumers.IsNull() {
+		settings["kafka_num_consumers"] = strconv.FormatInt(m.NumConsumers.ValueInt64(), 10)
+	}
+	for name, value := range m.Settings {
+		settings[name] = value.ValueString()
+	}
+	return settings
+}
+
+// secretSettings returns the engine settings the server hides
+func (m *KafkaModel) secretSettings() map[string]string {
+	if m.SASLPassword.IsNull() {
+		return nil
+	}
+	return map[string]string{"kafka_sasl_password": m.SASLPassword.ValueString()}
+}
+
+// reconcile reflects the engine settings reported by the server into the model
+func (m *KafkaModel) reconcile(actual map[string]string) {
+	reconcileEngineSettings(m.fields(), &m.NumConsumers, "kafka_num_consumers", m.Settings, actual)
+}
+
+// kafkaModel converts the engine settings of a Kafka table read from ClickHouse into their Terraform model.
+// Secret settings cannot be read back and are left null.
+func kafkaModel(actual map[string]string) *KafkaModel {
+	model := &KafkaModel{SASLPassword: types.StringNull()}
+	model.Settings = engineSettingsModel(model.fields(), &model.NumConsumers, "kafka_num_consumers", "kafka_",
+		[]string{"kafka_sasl_password"}, actual)
+	return model
+}
+
+// kafkaAttributeSettings lists the Kafka engine settings having their own attribute
+func kafkaAttributeSettings() []string {
+	return append(sortedKeys((&KafkaModel{}).fields()), "kafka_num_consumers", "kafka_sasl_password")
+}
+
+// reconcileEngineSettings reflects the engine settings reported by the server into the attributes holding
+// them and the settings map. Secret settings are kept, and settings the model does not set are ignored.
+func reconcileEngineSettings(fields map[string]*types.String, consumers *types.Int64, consumersSetting string,
+	settings map[string]types.String, actual map[string]string) {
+	for name, value := range fields {
+		if reported, ok := actual[name]; ok && reported != hiddenSecret && !value.IsNull() && reported != value.ValueString() {
+			*value = types.StringValue(reported)
+		}
+	}
+	if reported, ok := actual[consumersSetting]; ok && !consumers.IsNull() {
+		if count, err := strconv.ParseInt(reported, 10, 64); err == nil {
+			*consumers = types.Int64Value(count)
+		}
+	}
+	for name, value := range settings {
+		if reported, ok := actual[name]; ok && reported != hiddenSecret && reported != value.ValueString() {
+			settings[name] = types.StringValue(reported)
+		}
+	}
+}
+
+// engineSettingsModel fills the attributes holding the engine settings read from ClickHouse and
+// returns the other settings starting with prefix. Secret settings are skipped.
+func engineSettingsModel(fields map[string]*types.String, consumers *types.Int64, consumersSetting, prefix string,
+	secrets []string, actual map[string]string) map[string]types.String {
+	for _, value := range fields {
+		*value = types.StringNull()
+	}
+	*consumers = types.Int64Null()
+
+	var settings map[string]types.String
+	for _, name := range sortedKeys(actual) {
+		value := actual[name]
+		switch {
+		case fields[name] != nil:
+			*fields[name] = types.StringValue(value)
+		case name == consumersSetting:
+			if count, err := strconv.ParseInt(value, 10, 64); err == nil {
+				*consumers = types.Int64Value(count)
+			}
+		case slices.Contains(secrets, name):
+		case strings.HasPrefix(name, prefix):
+			if settings == nil {
+				settings = map[string]types.String{}
+			}
+			settings[name] = types.StringValue(value)
+		}
+	}
+
+	return settings
+}
+
+// validateEngineSettings reports settings of the settings map of an engine block that do not start with the
+// prefix of the engine settings or have their own attribute
+func validateEngineSettings(block, prefix string, reserved []string, settings types.Map, settingsPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if settings.IsNull() || settings.IsUnknown() {
+		return diags
+	}
+
+	for name := range settings.Elements() {
+		switch {
+		case !strings.HasPrefix(name, prefix):
+			diags.AddAttributeError(settingsPath.AtMapKey(name), "Invalid engine setting",
+				fmt.Sprintf("The settings of the %s block start with %s; table settings go in the settings attribute of the table.",
+					block, prefix))
+		case slices.Contains(reserved, name):
+			diags.AddAttributeError(settingsPath.AtMapKey(name), "Invalid engine setting",
+				fmt.Sprintf("%s has its own attribute in the %s block.", name, block))
+		}
+	}
+
+	return diags
+}
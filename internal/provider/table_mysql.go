@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// MySQLModel describes the parameters of a MySQL engine.
+type MySQLModel struct {
+	Address  types.String `tfsdk:"address"`
+	Database types.String `tfsdk:"database"`
+	Table    types.String `tfsdk:"table"`
+	User     types.String `tfsdk:"user"`
+	Password types.String `tfsdk:"password"`
+}
+
+// mysqlBlock returns the schema of the parameters of a MySQL engine
+func mysqlBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Parameters of a MySQL engine, running queries on a table of a remote MySQL server",
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address of the MySQL server as `host:port`",
+				Required:            true,
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Remote database name",
+				Required:            true,
+			},
+			"table": schema.StringAttribute{
+				MarkdownDescription: "Remote table name",
+				Required:            true,
+			},
+			"user": schema.StringAttribute{
+				MarkdownDescription: "MySQL user",
+				Required:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password of the MySQL user. The server hides it, so changes made outside of Terraform " +
+					"are not detected",
+				Optional:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+// arguments returns the parameters of the MySQL engine
+func (m *MySQLModel) arguments() []types.String {
+	var arguments []types.String
+	for _, value := range []types.String{m.Address, m.Database, m.Table, m.User, m.Password} {
+		arguments = append(arguments, types.StringValue(quoteString(value.ValueString())))
+	}
+	return arguments
+}
+
+// mysqlModel converts the parameters of a MySQL engine read from ClickHouse into their Terraform model.
+// The password cannot be read back and is left null.
+func mysqlModel(parameters []string) (*MySQLModel, bool) {
+	if len(parameters) != 5 {
+		return nil, false
+	}
+
+	return &MySQLModel{
+		Address:  types.StringValue(unquoteString(parameters[0])),
+		Database: types.StringValue(unquoteString(parameters[1])),
+		Table:    types.StringValue(unquoteString(parameters[2])),
+		User:     types.StringValue(unquoteString(parameters[3])),
+		Password: types.StringNull(),
+	}, true
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// NatsModel describes the settings of a NATS engine.
+type NatsModel struct {
+	URL          types.String `tfsdk:"url"`
+	Subjects     types.String `tfsdk:"subjects"`
+	Format       types.String `tfsdk:"format"`
+	QueueGroup   types.String `tfsdk:"queue_group"`
+	NumConsumers types.Int64  `tfsdk:"num_consumers"`
+
+	Username       types.String `tfsdk:"username"`
+	Password       types.String `tfsdk:"password"`
+	Token          types.String `tfsdk:"token"`
+	CredentialFile types.String `tfsdk:"credential_file"`
+
+	Settings map[string]types.String `tfsdk:"settings"`
+}
+
+// natsBlock returns the schema of the settings of a NATS engine
+func natsBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Settings of a NATS engine, rendered in the `SETTINGS` clause of the table",
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				MarkdownDescription: "Comma separated list of NATS servers (`nats_url`, e.g. `nats-1:4222,nats-2:4222`)",
+				Required:            true,
+			},
+			"subjects": schema.StringAttribute{
+				MarkdownDescription: "Comma separated list of subjects to subscribe to or publish on (`nats_subjects`)",
+				Required:            true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "Message format (`nats_format`, e.g. `JSONEachRow`)",
+				Required:            true,
+			},
+			"queue_group": schema.StringAttribute{
+				MarkdownDescription: "Queue group shared by the subscribers (`nats_queue_group`)",
+				Optional:            true,
+			},
+			"num_consumers": schema.Int64Attribute{
+				MarkdownDescription: "Number of consumers per table (`nats_num_consumers`)",
+				Optional:            true,
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "NATS username (`nats_username`)",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "NATS password (`nats_password`). The server hides it, so changes made outside of " +
+					"Terraform are not detected",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "NATS authentication token (`nats_token`). The server hides it, so changes made outside of " +
+					"Terraform are not detected",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"credential_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a NATS credentials file on the server (`nats_credential_file`)",
+				Optional:            true,
+			},
+			"settings": schema.MapAttribute{
+				MarkdownDescription: "Other NATS engine settings by full name (e.g. `nats_max_block_size`, `nats_handle_error_mode`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+// fields maps the NATS engine settings to the attributes holding them
+func (m *NatsModel) fields() map[string]*types.String {
+	return map[string]*types.String{
+		"nats_url":             &m.URL,
+		"nats_subjects":        &m.Subjects,
+		"nats_format":          &m.Format,
+		"nats_queue_group":     &m.QueueGroup,
+		"nats_username":        &m.Username,
+		"nats_credential_file": &m.CredentialFile,
+	}
+}
+
+// settings returns the engine settings of the NATS engine, without the secret ones
+func (m *NatsModel) settings() map[string]string {
+	settings := map[string]string{}
+	for name, value := range m.fields() {
+		if !value.IsNull() {
+			settings[name] = value.ValueString()
+		}
+	}
+	if !m.NumConsumers.IsNull() {
+		settings["nats_num_consumers"] = strconv.FormatInt(m.NumConsumers.ValueInt64(), 10)
+	}
+	for name, value := range m.Settings {
+		settings[name] = value.ValueString()
+	}
+	return settings
+}
+
+// secretSettings returns the engine settings the server hides
+func (m *NatsModel) secretSettings() map[string]string {
+	secrets := map[string]string{}
+	if !m.Password.IsNull() {
+		secrets["nats_password"] = m.Password.ValueString()
+	}
+	if !m.Token.IsNull() {
+		secrets["nats_token"] = m.Token.ValueString()
+	}
+	return secrets
+}
+
+// reconcile reflects the engine settings reported by the server into the model
+func (m *NatsModel) reconcile(actual map[string]string) {
+	reconcileEngineSettings(m.fields(), &m.NumConsumers, "nats_num_consumers", m.Settings, actual)
+}
+
+// natsModel converts the engine settings of a NATS table read from ClickHouse into their Terraform model.
+// Secret settings cannot be read back and are left null.
+func natsModel(actual map[string]string) *NatsModel {
+	model := &NatsModel{Password: types.StringNull(), Token: types.StringNull()}
+	model.Settings = engineSettingsModel(model.fields(), &model.NumConsumers, "nats_num_consumers", "nats_",
+		[]string{"nats_password", "nats_token"}, actual)
+	return model
+}
+
+// natsAttributeSettings lists the NATS engine settings having their own attribute
+func natsAttributeSettings() []string {
+	return append(sortedKeys((&NatsModel{}).fields()), "nats_num_consumers", "nats_password", "nats_token")
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// PostgreSQLModel describes the parameters of a PostgreSQL engine.
+type PostgreSQLModel struct {
+	Address    types.String `tfsdk:"address"`
+	Database   types.String `tfsdk:"database"`
+	Table      types.String `tfsdk:"table"`
+	User       types.String `tfsdk:"user"`
+	Password   types.String `tfsdk:"password"`
+	Schema     types.String `tfsdk:"schema"`
+	OnConflict types.String `tfsdk:"on_conflict"`
+}
+
+// postgresqlBlock returns the schema of the parameters of a PostgreSQL engine
+func postgresqlBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Parameters of a PostgreSQL engine, running queries on a table of a remote PostgreSQL server",
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address of the PostgreSQL server as `host:port`",
+				Required:            true,
+			},
+			"database": schema.StringAttribute{
+				MarkdownDescription: "Remote database name",
+				Required:            true,
+			},
+			"table": schema.StringAttribute{
+				MarkdownDescription: "Remote table name",
+				Required:            true,
+			},
+			"user": schema.StringAttribute{
+				MarkdownDescription: "PostgreSQL user",
+				Required:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password of the PostgreSQL user. The server hides it, so changes made outside of Terraform " +
+					"are not detected",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"schema": schema.StringAttribute{
+				MarkdownDescription: "Schema of the remote table, the search path of the user when not set",
+				Optional:            true,
+			},
+			"on_conflict": schema.StringAttribute{
+				MarkdownDescription: "Conflict resolution clause appended to the inserts (e.g. `ON CONFLICT DO NOTHING`)",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// arguments returns the parameters of the PostgreSQL engine. The schema is passed empty when only the
+// conflict clause, which follows it, is set.
+func (m *PostgreSQLModel) arguments() []types.String {
+	values := []types.String{m.Address, m.Database, m.Table, m.User, m.Password}
+	switch {
+	case !m.OnConflict.IsNull():
+		values = append(values, m.Schema, m.OnConflict)
+	case !m.Schema.IsNull():
+		values = append(values, m.Schema)
+	}
+
+	var arguments []types.String
+	for _, value := range values {
+		arguments = append(arguments, types.StringValue(quoteString(value.ValueString())))
+	}
+	return arguments
+}
+
+// postgresqlModel converts the parameters of a PostgreSQL engine read from ClickHouse into their Terraform model.
+// The password cannot be read back and is left null.
+func postgresqlModel(parameters []string) (*PostgreSQLModel, bool) {
+	if len(parameters) < 5 || len(parameters) > 7 {
+		return nil, false
+	}
+
+	model := &PostgreSQLModel{
+		Address:    types.StringValue(unquoteString(parameters[0])),
+		Database:   types.StringValue(unquoteString(parameters[1])),
+		Table:      types.StringValue(unquoteString(parameters[2])),
+		User:       types.StringValue(unquoteString(parameters[3])),
+		Password:   types.StringNull(),
+		Schema:     types.StringNull(),
+		OnConflict: types.StringNull(),
+	}
+	if len(parameters) > 5 && unquoteString(parameters[5]) != "" {
+		model.Schema = types.StringValue(unquoteString(parameters[5]))
+	}
+	if len(parameters) > 6 {
+		model.OnConflict = types.StringValue(unquoteString(parameters[6]))
+	}
+	return model, true
+}
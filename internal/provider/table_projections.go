@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ProjectionModel describes a projection of a table.
+type ProjectionModel struct {
+	Name        types.String `tfsdk:"name"`
+	Query       types.String `tfsdk:"query"`
+	Materialize types.Bool   `tfsdk:"materialize"`
+}
+
+// ProjectionInfo represents a projection as defined in the CREATE statement of a table
+type ProjectionInfo struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// projectionsBlock returns the schema of the projections block of the table resource
+func projectionsBlock() schema.Block {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "Projections, added and dropped in place with `ALTER TABLE ... ADD/DROP PROJECTION`",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					MarkdownDescription: "Projection name",
+					Required:            true,
+				},
+				"query": schema.StringAttribute{
+					MarkdownDescription: "SELECT definition of the projection (e.g. `SELECT user_id, count() GROUP BY user_id`)",
+					Required:            true,
+				},
+				"materialize": schema.BoolAttribute{
+					MarkdownDescription: "Build the projection for the existing parts with `MATERIALIZE PROJECTION` when it is " +
+						"added to an existing table. Otherwise only newly inserted data is projected",
+					Optional: true,
+					Computed: true,
+					Default:  booldefault.StaticBool(false),
+				},
+			},
+		},
+	}
+}
+
+// info converts the projection model into a projection definition
+func (m ProjectionModel) info() ProjectionInfo {
+	return ProjectionInfo{
+		Name:  m.Name.ValueString(),
+		Query: m.Query.ValueString(),
+	}
+}
+
+// projectionDefinitionSQL renders the PROJECTION clause of a projection
+func projectionDefinitionSQL(projection ProjectionInfo) string {
+	return fmt.Sprintf("PROJECTION %s (%s)", quoteIdentifier(projection.Name), projection.Query)
+}
+
+// tableProjections extracts the projections from a CREATE TABLE statement
+func tableProjections(createQuery string) []ProjectionInfo {
+	var projections []ProjectionInfo
+	for _, element := range tableElements(createQuery, "PROJECTION") {
+		if !strings.HasPrefix(element.Definition, "(") || !strings.HasSuffix(element.Definition, ")") {
+			continue
+		}
+		projections = append(projections, ProjectionInfo{
+			Name:  element.Name,
+			Query: strings.TrimSpace(element.Definition[1 : len(element.Definition)-1]),
+		})
+	}
+
+	return projections
+}
+
+// readTableProjections reads the projections of a table from its CREATE statement
+func readTableProjections(ctx context.Context, client *clickhouseClient, database, table string) ([]ProjectionInfo, error) {
+	var createQuery string
+	err := client.QueryRowContext(ctx, "SELECT create_table_query FROM system.tables WHERE database = ? AND name = ?",
+		database, table).Scan(&createQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return tableProjections(createQuery), nil
+}
+
+// reconcileProjections builds the projections to store in state from the ones found on the server,
+// keeping the configured spelling of equivalent projections and the configured order
+func reconcileProjections(ctx context.Context, client *clickhouseClient, configured []ProjectionModel, actual []ProjectionInfo) []ProjectionModel {
+	found := make(map[string]ProjectionInfo, len(actual))
+	for _, projection := range actual {
+		found[projection.Name] = projection
+	}
+
+	var projections []ProjectionModel
+	if configured != nil {
+		projections = []ProjectionModel{}
+	}
+	seen := map[string]bool{}
+	for _, model := range configured {
+		projection, ok := found[model.Name.ValueString()]
+		if !ok {
+			continue
+		}
+		seen[projection.Name] = true
+
+		if !queriesEquivalent(ctx, client, model.Query.ValueString(), projection.Query) {
+			model.Query = types.StringValue(projection.Query)
+		}
+		projections = append(projections, model)
+	}
+
+	// Projections added outside of Terraform are kept so the next apply drops them
+	for _, projection := range actual {
+		if !seen[projection.Name] {
+			projections = append(projections, ProjectionModel{
+				Name:        types.StringValue(projection.Name),
+				Query:       types.StringValue(projection.Query),
+				Materialize: types.BoolValue(false),
+			})
+		}
+	}
+
+	return projections
+}
+
+// projectionAlterStatements generates the drops and the additions turning the current projections into the
+// desired ones. Changed projections are dropped and added again, then materialized when requested.
+func projectionAlterStatements(table string, current, desired []ProjectionModel) ([]string, []string) {
+	existing := make(map[string]ProjectionInfo, len(current))
+	for _, projection := range current {
+		existing[projection.Name.ValueString()] = projection.info()
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	var drops, adds []string
+	for _, model := range desired {
+		projection := model.info()
+		wanted[projection.Name] = true
+
+		have, ok := existing[projection.Name]
+		if ok && have == projection {
+			continue
+		}
+		if ok {
+			drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP PROJECTION %s", table, quoteIdentifier(projection.Name)))
+		}
+		adds = append(adds, fmt.Sprintf("ALTER TABLE %s ADD %s", table, projectionDefinitionSQL(projection)))
+		if model.Materialize.ValueBool() {
+			adds = append(adds, fmt.Sprintf("ALTER TABLE %s MATERIALIZE PROJECTION %s", table, quoteIdentifier(projection.Name)))
+		}
+	}
+
+	for _, projection := range current {
+		if !wanted[projection.Name.ValueString()] {
+			drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP PROJECTION %s", table, quoteIdentifier(projection.Name.ValueString())))
+		}
+	}
+
+	return drops, adds
+}
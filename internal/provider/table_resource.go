@@ -2,15 +2,26 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -18,6 +29,9 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &TableResource{}
 var _ resource.ResourceWithImportState = &TableResource{}
+var _ resource.ResourceWithModifyPlan = &TableResource{}
+var _ resource.ResourceWithValidateConfig = &TableResource{}
+var _ resource.ResourceWithConfigValidators = &TableResource{}
 
 func NewTableResource() resource.Resource {
 	return &TableResource{}
@@ -25,7 +39,7 @@ func NewTableResource() resource.Resource {
 
 // TableResource defines the resource implementation.
 type TableResource struct {
-	client *sql.DB
+	client *clickhouseClient
 }
 
 // TableResourceModel describes the resource data model.
@@ -33,15 +47,69 @@ type TableResourceModel struct {
 	ID       types.String   `tfsdk:"id"`
 	Name     types.String   `tfsdk:"name"`
 	Database types.String   `tfsdk:"database"`
-	Engine   types.String   `tfsdk:"engine"`
+	Engine   *EngineModel   `tfsdk:"engine"`
 	Columns  []ColumnModel  `tfsdk:"columns"`
 	OrderBy  []types.String `tfsdk:"order_by"`
+
+	PrimaryKey []types.String `tfsdk:"primary_key"`
+	SampleBy   types.String   `tfsdk:"sample_by"`
+
+	Settings map[string]types.String `tfsdk:"settings"`
+	Comment  types.String            `tfsdk:"comment"`
+	Indexes  []IndexModel            `tfsdk:"indexes"`
+
+	Projections []ProjectionModel `tfsdk:"projections"`
+	Constraints []ConstraintModel `tfsdk:"constraints"`
+
+	AsSelect        types.String `tfsdk:"as_select"`
+	AsTable         types.String `tfsdk:"as_table"`
+	InferredColumns types.Map    `tfsdk:"inferred_columns"`
+
+	OnExisting         types.String `tfsdk:"on_existing"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	DestroyBehavior    types.String `tfsdk:"destroy_behavior"`
+	DropSync           types.Bool   `tfsdk:"drop_sync"`
+
+	CascadeDependents types.Bool   `tfsdk:"cascade_dependents"`
+	AllowExtraColumns types.Bool   `tfsdk:"allow_extra_columns"`
+	ExecutionSettings types.Map    `tfsdk:"execution_settings"`
+	Cluster           types.String `tfsdk:"cluster"`
+
+	MetadataModificationTime types.String `tfsdk:"metadata_modification_time"`
+	CreateStatementHash      types.String `tfsdk:"create_statement_hash"`
+	SchemaFingerprint        types.String `tfsdk:"schema_fingerprint"`
+	AppliedShards            types.List   `tfsdk:"applied_shards"`
+
+	Timeouts *TimeoutsModel `tfsdk:"timeouts"`
 }
 
+// Behaviors of Create when the table already exists
+const (
+	onExistingFail        = "fail"
+	onExistingAdopt       = "adopt"
+	onExistingIfNotExists = "if_not_exists"
+)
+
+// Behaviors of Delete
+const (
+	destroyBehaviorDrop    = "drop"
+	destroyBehaviorAbandon = "abandon"
+	destroyBehaviorDetach  = "detach"
+)
+
 type ColumnModel struct {
-	Name    types.String `tfsdk:"name"`
-	Type    types.String `tfsdk:"type"`
-	Comment types.String `tfsdk:"comment"`
+	Name    types.String    `tfsdk:"name"`
+	Type    ColumnTypeValue `tfsdk:"type"`
+	Comment types.String    `tfsdk:"comment"`
+	Default types.String    `tfsdk:"default_expression"`
+	Codec   types.String    `tfsdk:"codec"`
+	TTL     types.String    `tfsdk:"ttl"`
+
+	Materialized types.String `tfsdk:"materialized"`
+	Alias        types.String `tfsdk:"alias"`
+	Ephemeral    types.String `tfsdk:"ephemeral"`
+
+	PreviousName types.String `tfsdk:"previous_name"`
 }
 
 func (r *TableResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -77,51 +145,508 @@ func (r *TableResource) Schema(ctx context.Context, req resource.SchemaRequest,
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"engine": schema.StringAttribute{
-				MarkdownDescription: "Table engine (e.g., MergeTree, Log, Memory)",
-				Required:            true,
-			},
 			"order_by": schema.ListAttribute{
-				MarkdownDescription: "Columns to order by (required for MergeTree family engines)",
+				MarkdownDescription: "Sorting key expressions: column names or expressions such as `cityHash64(user_id)` " +
+					"(required for MergeTree family engines, which are the only ones supporting it)",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"primary_key": schema.ListAttribute{
+				MarkdownDescription: "Primary key columns when they differ from the sorting key. Must be a prefix of `order_by`; " +
+					"defaults to the sorting key",
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"sample_by": schema.StringAttribute{
+				MarkdownDescription: "Sampling expression (e.g. `intHash32(user_id)`), which must be part of the primary key",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"settings": schema.MapAttribute{
+				MarkdownDescription: "Table settings (e.g. `index_granularity`, `storage_policy`, merge settings) rendered in the " +
+					"`SETTINGS` clause. Changes are applied in place with `ALTER TABLE ... MODIFY SETTING`",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Table comment, changed in place with `ALTER TABLE ... MODIFY COMMENT`",
 				Optional:            true,
+			},
+			"as_select": schema.StringAttribute{
+				MarkdownDescription: "Query the table is created from with `CREATE TABLE ... AS SELECT`, which also inserts its " +
+					"result. Columns not configured in `columns` are inferred from the query. Changing it replaces the table",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"as_table": schema.StringAttribute{
+				MarkdownDescription: "Table whose structure is cloned with `CREATE TABLE ... AS`, as `database.table` or a " +
+					"table of the same database. Its columns, indexes, projections and constraints are copied, so none can be " +
+					"configured; `engine` may differ from the source one, e.g. to create a staging copy. Changing it replaces the table",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"inferred_columns": schema.MapAttribute{
+				Computed:            true,
 				ElementType:         types.StringType,
+				MarkdownDescription: "Types of the columns inferred from `as_select` or cloned from `as_table`, by column name",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"on_existing": schema.StringAttribute{
+				MarkdownDescription: "What to do when the table already exists on creation: `fail` (default), `adopt` it into " +
+					"state when its engine and columns match the configuration, or `if_not_exists` to keep it as is with " +
+					"`CREATE TABLE IF NOT EXISTS`. Useful to bring existing clusters under Terraform",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(onExistingFail),
+			},
+			"deletion_protection": schema.BoolAttribute{
+				MarkdownDescription: "Prevent the table from being dropped, by `terraform destroy` or by a change replacing it. " +
+					"Set it to false and apply before destroying the table",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"destroy_behavior": schema.StringAttribute{
+				MarkdownDescription: "What happens to the table when the resource is destroyed: `drop` it (default), `detach` " +
+					"it with `DETACH TABLE ... PERMANENTLY`, keeping its data on disk for a manual recovery, or `abandon` it, " +
+					"only removing it from state, e.g. when handing it over to another team or tool",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(destroyBehaviorDrop),
+			},
+			"drop_sync": schema.BoolAttribute{
+				MarkdownDescription: "Drop the table and its dependent views with `SYNC`, waiting for the data to be removed. " +
+					"On Atomic and Replicated databases this lets a table of the same name be recreated right away",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"cascade_dependents": schema.BoolAttribute{
+				MarkdownDescription: "When the table is replaced or destroyed, drop the views depending on it and recreate them " +
+					"once the table is recreated in the same apply. When false, dependent views block the replacement.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"allow_extra_columns": schema.BoolAttribute{
+				MarkdownDescription: "Ignore columns that exist on the server but not in the configuration, so they are " +
+					"neither reported as drift nor dropped. Useful when ingestion tooling adds columns to the table.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the DDL of the table runs with `ON CLUSTER`, defaulting to the provider " +
+					"`cluster`. An empty string runs it on the connected server only. Changing it only affects later statements",
+				Optional: true,
+			},
+			"execution_settings": schema.MapAttribute{
+				MarkdownDescription: "ClickHouse settings (e.g. `max_execution_time`, `alter_sync`) applied to the DDL " +
+					"statements of this table, overriding the provider defaults",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"metadata_modification_time": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Last modification time of the table metadata, used to skip the full schema comparison on refresh",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"create_statement_hash": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA-256 hash of the CREATE statement reported by the server",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"schema_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Stable hash of the normalized table definition (engine, columns and keys), independent of the table name",
+			},
+			"applied_shards": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.Int64Type,
+				MarkdownDescription: "Shards on which the table exists, when the provider executes DDL shard by shard",
 			},
 		},
 		Blocks: map[string]schema.Block{
 			"columns": schema.ListNestedBlock{
-				MarkdownDescription: "Table columns definition",
+				MarkdownDescription: "Table columns definition. Columns are added, dropped and modified in place with `ALTER TABLE`",
 				NestedObject: schema.NestedBlockObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							MarkdownDescription: "Column name",
-							Required:            true,
-						},
-						"type": schema.StringAttribute{
-							MarkdownDescription: "Column type (e.g., UInt64, String, DateTime)",
-							Required:            true,
-						},
-						"comment": schema.StringAttribute{
-							MarkdownDescription: "Column comment",
-							Optional:            true,
-						},
-					},
+					Attributes: columnAttributes(),
 				},
 			},
+			"engine":      engineBlock(),
+			"indexes":     indexesBlock(),
+			"projections": projectionsBlock(),
+			"constraints": constraintsBlock(),
+			"timeouts":    timeoutsBlock(),
 		},
 	}
 }
 
+func (r *TableResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var engine types.Object
+	var columns types.List
+	var asSelect, asTable types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("engine"), &engine)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("columns"), &columns)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("as_select"), &asSelect)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("as_table"), &asTable)...)
+	if resp.Diagnostics.HasError() || columns.IsUnknown() {
+		return
+	}
+
+	var columnModels []ColumnModel
+	resp.Diagnostics.Append(columns.ElementsAs(ctx, &columnModels, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.validateAsTable(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateTimeouts(ctx, req.Config)...)
+
+	for _, attribute := range []string{"database", "name"} {
+		var name types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &name)...)
+		resp.Diagnostics.Append(validateObjectName(name, path.Root(attribute))...)
+	}
+
+	var onExisting types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("on_existing"), &onExisting)...)
+	switch onExisting.ValueString() {
+	case "", onExistingFail, onExistingAdopt, onExistingIfNotExists:
+	default:
+		if !onExisting.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(path.Root("on_existing"), "Invalid on_existing",
+				fmt.Sprintf("on_existing must be %s, %s or %s, got %s.",
+					onExistingFail, onExistingAdopt, onExistingIfNotExists, onExisting.ValueString()))
+		}
+	}
+
+	var destroyBehavior types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("destroy_behavior"), &destroyBehavior)...)
+	switch destroyBehavior.ValueString() {
+	case "", destroyBehaviorDrop, destroyBehaviorDetach, destroyBehaviorAbandon:
+	default:
+		if !destroyBehavior.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(path.Root("destroy_behavior"), "Invalid destroy_behavior",
+				fmt.Sprintf("destroy_behavior must be %s, %s or %s, got %s.",
+					destroyBehaviorDrop, destroyBehaviorDetach, destroyBehaviorAbandon, destroyBehavior.ValueString()))
+		}
+	}
+
+	// Tables created from a query may leave all their columns to be inferred, and clones copy them
+	if len(columnModels) > 0 || (asSelect.IsNull() && asTable.IsNull()) {
+		resp.Diagnostics.Append(validateColumnModels(columnModels, path.Root("columns"))...)
+	}
+	if !engine.IsUnknown() {
+		resp.Diagnostics.Append(validateEngine(engine, columnModels, path.Root("engine"))...)
+	}
+
+	var indexes, projections, constraints types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("indexes"), &indexes)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("projections"), &projections)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("constraints"), &constraints)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !indexes.IsNull() && !indexes.IsUnknown() {
+		var indexModels []IndexModel
+		resp.Diagnostics.Append(indexes.ElementsAs(ctx, &indexModels, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		names := make([]types.String, len(indexModels))
+		for i, index := range indexModels {
+			names[i] = index.Name
+			resp.Diagnostics.Append(validateIndexType(index.Type, path.Root("indexes").AtListIndex(i).AtName("type"))...)
+		}
+		resp.Diagnostics.Append(validateUniqueNames("index", names, path.Root("indexes"))...)
+	}
+	if !projections.IsNull() && !projections.IsUnknown() {
+		var projectionModels []ProjectionModel
+		resp.Diagnostics.Append(projections.ElementsAs(ctx, &projectionModels, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		names := make([]types.String, len(projectionModels))
+		for i, projection := range projectionModels {
+			names[i] = projection.Name
+		}
+		resp.Diagnostics.Append(validateUniqueNames("projection", names, path.Root("projections"))...)
+	}
+	if !constraints.IsNull() && !constraints.IsUnknown() {
+		var constraintModels []ConstraintModel
+		resp.Diagnostics.Append(constraints.ElementsAs(ctx, &constraintModels, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		names := make([]types.String, len(constraintModels))
+		for i, constraint := range constraintModels {
+			names[i] = constraint.Name
+		}
+		resp.Diagnostics.Append(validateUniqueNames("constraint", names, path.Root("constraints"))...)
+	}
+
+	var orderBy, primaryKey types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("order_by"), &orderBy)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("primary_key"), &primaryKey)...)
+	if resp.Diagnostics.HasError() || orderBy.IsNull() || orderBy.IsUnknown() || primaryKey.IsNull() || primaryKey.IsUnknown() {
+		return
+	}
+
+	var orderByValues, primaryKeyValues []types.String
+	resp.Diagnostics.Append(orderBy.ElementsAs(ctx, &orderByValues, false)...)
+	resp.Diagnostics.Append(primaryKey.ElementsAs(ctx, &primaryKeyValues, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, value := range append(orderByValues, primaryKeyValues...) {
+		if value.IsUnknown() {
+			return
+		}
+	}
+
+	// ClickHouse requires the primary key to be a prefix of the sorting key
+	orderByColumns, primaryKeyColumns := sortingKeyExpressions(orderByValues), sortingKeyExpressions(primaryKeyValues)
+	if len(primaryKeyColumns) > len(orderByColumns) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("primary_key"),
+			"Primary key is not a prefix of the sorting key",
+			fmt.Sprintf("primary_key has %d expressions but order_by only has %d.", len(primaryKeyColumns), len(orderByColumns)),
+		)
+		return
+	}
+	for i, col := range primaryKeyColumns {
+		if !sortingKeysEquivalent([]string{col}, []string{orderByColumns[i]}) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("primary_key"),
+				"Primary key is not a prefix of the sorting key",
+				fmt.Sprintf("primary_key expression %d is '%s' but order_by expression %d is '%s'.",
+					i+1, col, i+1, orderByColumns[i]),
+			)
+			return
+		}
+	}
+}
+
+// validateAsTable reports elements of the column list configured on a clone, which copies them from its source,
+// and clones also created from a query
+func (r *TableResource) validateAsTable(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var asTable, asSelect types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("as_table"), &asTable)...)
+	diags.Append(config.GetAttribute(ctx, path.Root("as_select"), &asSelect)...)
+	if diags.HasError() || asTable.IsNull() {
+		return diags
+	}
+
+	if !asSelect.IsNull() {
+		diags.AddAttributeError(path.Root("as_select"), "Conflicting table source",
+			"as_table and as_select cannot both be set.")
+	}
+	for _, attribute := range []string{"columns", "indexes", "projections", "constraints"} {
+		var elements types.List
+		diags.Append(config.GetAttribute(ctx, path.Root(attribute), &elements)...)
+		if !elements.IsNull() && !elements.IsUnknown() && len(elements.Elements()) > 0 {
+			diags.AddAttributeError(path.Root(attribute), "Invalid table attribute",
+				fmt.Sprintf("%s cannot be configured with as_table, which copies them from %s.", attribute, asTable.ValueString()))
+		}
+	}
+
+	return diags
+}
+
+func (r *TableResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Destroying a protected table is reported at plan time already
+	if req.Plan.Raw.IsNull() {
+		resp.Diagnostics.Append(r.checkDeletionProtection(ctx, req.State, "destroyed")...)
+		return
+	}
+	if r.client == nil {
+		return
+	}
+
+	var engine types.String
+	var settings types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("engine").AtName("name"), &engine)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("settings"), &settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if isMergeTreeFamily(engine.ValueString()) && !settings.IsNull() && !settings.IsUnknown() {
+		var settingValues map[string]types.String
+		resp.Diagnostics.Append(settings.ElementsAs(ctx, &settingValues, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(r.checkSettings(ctx, settingValues)...)
+	}
+
+	resp.Diagnostics.Append(r.checkRemoteTable(ctx, req.Plan)...)
+
+	var cluster types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("cluster"), &cluster)...)
+	if r.client.shardMode() && cluster.ValueString() != "" {
+		resp.Diagnostics.AddAttributeError(path.Root("cluster"), "Conflicting cluster",
+			"The provider runs table DDL shard by shard, so the table cannot also use ON CLUSTER.")
+	}
+	resp.Diagnostics.Append(r.plannedSQL(ctx, req, resp.RequiresReplace)...)
+
+	// Only replacements of existing tables are checked for deletion protection and dependent views
+	if req.State.Raw.IsNull() || len(resp.RequiresReplace) == 0 {
+		return
+	}
+	resp.Diagnostics.Append(r.checkDeletionProtection(ctx, req.State, "replaced")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plannedEngine, priorEngine types.Object
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("engine"), &plannedEngine)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("engine"), &priorEngine)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !plannedEngine.IsUnknown() && !plannedEngine.Equal(priorEngine) {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("engine"),
+			"Table engine change replaces the table",
+			fmt.Sprintf("ClickHouse cannot change the engine of an existing table, so the table is dropped and recreated "+
+				"with engine %s. Its data is lost unless it is copied beforehand.", engine.ValueString()),
+		)
+	}
+
+	var state, plan TableResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dependents, err := r.getDependentViews(ctx, state.Database.ValueString(), state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Could not check dependent views",
+			fmt.Sprintf("Could not list views depending on table %s: %s", state.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+	if len(dependents) == 0 {
+		return
+	}
+
+	if plan.CascadeDependents.IsUnknown() || plan.CascadeDependents.ValueBool() {
+		resp.Diagnostics.AddWarning(
+			"Dependent views will be recreated",
+			fmt.Sprintf("Replacing table %s drops and recreates these dependent views: %s. "+
+				"Materialized views keeping their own storage lose their data.",
+				state.ID.ValueString(), dependentViewList(dependents)),
+		)
+		return
+	}
+
+	resp.Diagnostics.AddError(
+		"Table has dependent views",
+		fmt.Sprintf("Table %s must be replaced but these views depend on it: %s. "+
+			"Remove them first or set cascade_dependents = true to drop and recreate them with the table.",
+			state.ID.ValueString(), dependentViewList(dependents)),
+	)
+}
+
+// plannedSQL reports the statements the apply runs on the table as a warning, so the DDL can be reviewed with the plan
+func (r *TableResource) plannedSQL(ctx context.Context, req resource.ModifyPlanRequest, replace path.Paths) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// The statements are only known once every configured value is
+	if !req.Config.Raw.IsFullyKnown() {
+		return diags
+	}
+
+	var plan, state TableResourceModel
+	if req.Plan.Get(ctx, &plan).HasError() {
+		return diags
+	}
+	if plan.Database.IsNull() || plan.Database.IsUnknown() {
+		plan.Database = types.StringValue("default")
+	}
+
+	var statements []string
+	switch {
+	case req.State.Raw.IsNull():
+		statements = append(statements, r.withDefaultCluster(plan).redactedCreateStatement())
+	case req.State.Get(ctx, &state).HasError():
+		return diags
+	case len(replace) > 0:
+		if state.DestroyBehavior.ValueString() != destroyBehaviorAbandon {
+			statements = append(statements, r.withDefaultCluster(state).dropStatement())
+		}
+		statements = append(statements, r.withDefaultCluster(plan).redactedCreateStatement())
+	default:
+		statements = tableAlterStatements(state, r.withDefaultCluster(plan))
+	}
+	if len(statements) == 0 {
+		return diags
+	}
+
+	diags.AddWarning(
+		"Planned DDL",
+		fmt.Sprintf("Applying this plan runs these statements for table %s.%s:\n\n%s;",
+			plan.Database.ValueString(), plan.Name.ValueString(), strings.Join(statements, ";\n\n")),
+	)
+	return diags
+}
+
+// checkDeletionProtection reports a table with deletion protection about to be dropped. Abandoned tables are
+// left in place, so they are not protected.
+func (r *TableResource) checkDeletionProtection(ctx context.Context, state tfsdk.State, action string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if state.Raw.IsNull() {
+		return diags
+	}
+
+	var id, destroyBehavior types.String
+	var protected types.Bool
+	diags.Append(state.GetAttribute(ctx, path.Root("id"), &id)...)
+	diags.Append(state.GetAttribute(ctx, path.Root("deletion_protection"), &protected)...)
+	diags.Append(state.GetAttribute(ctx, path.Root("destroy_behavior"), &destroyBehavior)...)
+	if protected.ValueBool() && destroyBehavior.ValueString() != destroyBehaviorAbandon {
+		diags.AddError(
+			"Table is protected from deletion",
+			fmt.Sprintf("Table %s cannot be %s while deletion_protection is enabled. "+
+				"Set deletion_protection = false and apply before dropping it.", id.ValueString(), action),
+		)
+	}
+	return diags
+}
+
 func (r *TableResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*sql.DB)
+	client, ok := req.ProviderData.(*clickhouseClient)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *sql.DB, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
@@ -138,33 +663,108 @@ func (r *TableResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+	ctx, cancel := withTimeout(ctx, data.Timeouts.createTimeout())
+	defer cancel()
+
 	// Set default database if not provided
 	if data.Database.IsNull() || data.Database.IsUnknown() {
 		data.Database = types.StringValue("default")
 	}
 
+	// Full-text and vector similarity indexes are gated on the server version and may need experimental settings
+	ctx, err := withIndexSettings(ctx, r.client, nil, data.definition().Indexes)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unsupported table index",
+			fmt.Sprintf("Could not create table %s.%s: %s", data.Database.ValueString(), data.Name.ValueString(), err.Error()),
+		)
+		return
+	}
+
 	// Generate the CREATE TABLE SQL
-	createSQL := r.generateCreateTableSQL(data)
+	createSQL := r.generateCreateTableSQL(r.withDefaultCluster(data))
 
 	tflog.Info(ctx, "Creating ClickHouse table", map[string]interface{}{
 		"sql": createSQL,
 	})
 
-	// Execute the SQL against ClickHouse
-	_, err := r.client.ExecContext(ctx, createSQL)
+	// Set the ID (combination of database and table name)
+	data.ID = types.StringValue(fmt.Sprintf("%s.%s", data.Database.ValueString(), data.Name.ValueString()))
+	data.InferredColumns = types.MapNull(types.StringType)
+
+	adopted := false
+	if data.OnExisting.ValueString() == onExistingAdopt {
+		var diags diag.Diagnostics
+		adopted, diags = r.adoptTable(ctx, &data)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if !adopted {
+		// Execute the SQL against ClickHouse
+		applied, err := r.client.execOnShards(ctx, createSQL, nil)
+		data.AppliedShards = shardList(r.client, applied)
+		if err != nil {
+			resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+				"Error creating table",
+				fmt.Sprintf("Could not create table %s.%s",
+					data.Database.ValueString(),
+					data.Name.ValueString()),
+				withStatement(createSQL, err),
+			))
+			if len(applied) > 0 {
+				// Keep track of the shards holding the table so the replacement drops them
+				data.MetadataModificationTime = types.StringValue("")
+				data.CreateStatementHash = types.StringValue("")
+				data.SchemaFingerprint = types.StringValue("")
+				resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			}
+			return
+		}
+
+		// Recreate the views dropped when this table was replaced
+		for _, view := range r.client.takeDependents(data.ID.ValueString()) {
+			tflog.Info(ctx, "Recreating dependent view", map[string]interface{}{
+				"view": view.ID(),
+				"sql":  view.CreateQuery,
+			})
+
+			if _, err := r.client.execOnShards(ctx, view.CreateQuery, nil); err != nil {
+				resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+					"Error recreating dependent view",
+					fmt.Sprintf("Table %s was recreated but its dependent view %s could not be", data.ID.ValueString(), view.ID()),
+					withStatement(view.CreateQuery, err),
+				))
+			}
+		}
+	}
+
+	metadata, err := r.getTableMetadata(ctx, data.Database.ValueString(), data.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error creating table",
-			fmt.Sprintf("Could not create table %s.%s: %s",
-				data.Database.ValueString(),
-				data.Name.ValueString(),
-				err.Error()),
+			"Error reading table metadata",
+			fmt.Sprintf("Could not read metadata of table %s: %s", data.ID.ValueString(), err.Error()),
 		)
 		return
 	}
+	data.MetadataModificationTime = types.StringValue(metadata.ModificationTime)
+	data.CreateStatementHash = types.StringValue(metadata.CreateStatementHash)
+	data.SchemaFingerprint = types.StringValue(data.definition().fingerprint())
 
-	// Set the ID (combination of database and table name)
-	data.ID = types.StringValue(fmt.Sprintf("%s.%s", data.Database.ValueString(), data.Name.ValueString()))
+	if !data.AsSelect.IsNull() || !data.AsTable.IsNull() {
+		actualColumns, err := r.getTableColumns(ctx, data.Database.ValueString(), data.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table schema",
+				fmt.Sprintf("Could not read the columns inferred for table %s: %s", data.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+		data.InferredColumns, _ = inferredColumns(data.Columns, actualColumns)
+	}
 
 	tflog.Info(ctx, "Successfully created ClickHouse table", map[string]interface{}{
 		"id": data.ID.ValueString(),
@@ -174,6 +774,71 @@ func (r *TableResource) Create(ctx context.Context, req resource.CreateRequest,
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// adoptTable reports whether the table to create already exists with the configured engine and columns, in
+// which case it is adopted into state. A different existing table is reported as an error.
+func (r *TableResource) adoptTable(ctx context.Context, data *TableResourceModel) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	database, tableName := data.Database.ValueString(), data.Name.ValueString()
+
+	metadata, err := r.getTableMetadata(ctx, database, tableName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, diags
+	}
+	if err != nil {
+		diags.AddError(
+			"Error checking table existence",
+			fmt.Sprintf("Could not check if table %s exists: %s", data.ID.ValueString(), err.Error()),
+		)
+		return false, diags
+	}
+
+	if !sameEngine(data.Engine.Name.ValueString(), metadata.Engine) {
+		diags.AddError(
+			"Existing table cannot be adopted",
+			fmt.Sprintf("Table %s already exists with engine '%s' instead of '%s'.",
+				data.ID.ValueString(), metadata.Engine, data.Engine.Name.ValueString()),
+		)
+		return false, diags
+	}
+
+	actualColumns, err := r.getTableColumns(ctx, database, tableName)
+	if err != nil {
+		diags.AddError(
+			"Error reading table schema",
+			fmt.Sprintf("Could not read schema for table %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return false, diags
+	}
+	if !data.AsSelect.IsNull() || !data.AsTable.IsNull() {
+		_, actualColumns = inferredColumns(data.Columns, actualColumns)
+	}
+	if err := r.validateColumns(ctx, data.Columns, actualColumns, data.AllowExtraColumns.ValueBool()); err != nil {
+		diags.AddError(
+			"Existing table cannot be adopted",
+			fmt.Sprintf("Table %s already exists but does not match the configuration: %s", data.ID.ValueString(), err.Error()),
+		)
+		return false, diags
+	}
+
+	data.AppliedShards = shardList(r.client, nil)
+	if r.client.shardMode() {
+		shards, err := r.client.shardsWithTable(ctx, database, tableName)
+		if err != nil {
+			diags.AddError(
+				"Error checking table existence",
+				fmt.Sprintf("Could not check on which shards table %s exists: %s", data.ID.ValueString(), err.Error()),
+			)
+			return false, diags
+		}
+		data.AppliedShards = shardList(r.client, shards)
+	}
+
+	tflog.Info(ctx, "Adopting existing ClickHouse table", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+	return true, diags
+}
+
 func (r *TableResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data TableResourceModel
 
@@ -196,15 +861,8 @@ func (r *TableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	database := parts[0]
 	tableName := parts[1]
 
-	// Query to check if table exists and get engine
-	tableQuery := `
-        SELECT engine
-        FROM system.tables 
-        WHERE database = ? AND name = ?
-    `
-
-	var actualEngine string
-	err := r.client.QueryRowContext(ctx, tableQuery, database, tableName).Scan(&actualEngine)
+	// Check if table exists and get its engine and metadata version
+	metadata, err := r.getTableMetadata(ctx, database, tableName)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Table doesn't exist, remove from state
@@ -221,15 +879,64 @@ func (r *TableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	actualEngine := metadata.Engine
+
+	if r.client.shardMode() {
+		shards, err := r.client.shardsWithTable(ctx, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error checking table existence",
+				fmt.Sprintf("Could not check on which shards table %s exists: %s", data.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+		if len(shards) < len(r.client.shards) {
+			resp.Diagnostics.AddWarning(
+				"Table missing on some shards",
+				fmt.Sprintf("Table %s only exists on shards %v. Replace the resource to create it on every shard.",
+					data.ID.ValueString(), shards),
+			)
+		}
+		data.AppliedShards = shardList(r.client, shards)
+	}
+
+	// Skip the full comparison when the table metadata has not changed since the last refresh
+	if data.MetadataModificationTime.ValueString() == metadata.ModificationTime &&
+		data.CreateStatementHash.ValueString() == metadata.CreateStatementHash {
+		tflog.Debug(ctx, "Table metadata unchanged, skipping schema comparison", map[string]interface{}{
+			"id": data.ID.ValueString(),
+		})
+		data.SchemaFingerprint = types.StringValue(data.definition().fingerprint())
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
 	// Validate engine matches
-	if actualEngine != data.Engine.ValueString() {
+	if !sameEngine(data.Engine.Name.ValueString(), actualEngine) {
 		resp.Diagnostics.AddError(
 			"Table engine mismatch",
 			fmt.Sprintf("Expected engine '%s', but table has engine '%s'",
-				data.Engine.ValueString(), actualEngine),
+				data.Engine.Name.ValueString(), actualEngine),
+		)
+		return
+	}
+
+	// The server rewrites engine parameters, so they are compared with the configured ones by meaning
+	_, actualParameters := parseEngine(metadata.EngineFull)
+	if !engineParametersMatch(ctx, r.client, data.Engine, actualEngine, database, tableName, actualParameters) {
+		resp.Diagnostics.AddError(
+			"Table engine mismatch",
+			fmt.Sprintf("Expected engine '%s', but table has engine '%s(%s)'",
+				data.Engine.redactedSQL(), actualEngine, strings.Join(actualParameters, ", ")),
 		)
 		return
 	}
+	if data.Engine.Kafka != nil {
+		data.Engine.Kafka.reconcile(parseEngineSettings(metadata.EngineFull))
+	}
+	if data.Engine.Nats != nil {
+		data.Engine.Nats.reconcile(parseEngineSettings(metadata.EngineFull))
+	}
 
 	// Get actual column schema
 	actualColumns, err := r.getTableColumns(ctx, database, tableName)
@@ -240,9 +947,12 @@ func (r *TableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		)
 		return
 	}
+	if !data.AsSelect.IsNull() || !data.AsTable.IsNull() {
+		data.InferredColumns, actualColumns = inferredColumns(data.Columns, actualColumns)
+	}
 
 	// Validate columns match expected schema
-	if err := r.validateColumns(data.Columns, actualColumns); err != nil {
+	if err := r.validateColumns(ctx, data.Columns, actualColumns, data.AllowExtraColumns.ValueBool()); err != nil {
 		resp.Diagnostics.AddError(
 			"Table schema mismatch",
 			fmt.Sprintf("Table schema does not match configuration: %s", err.Error()),
@@ -250,8 +960,18 @@ func (r *TableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	// Column comments can be changed in place, so differences are reported as drift. Types are equivalent
+	// at this point and keep their configured spelling.
+	for i, col := range data.Columns {
+		actual := actualColumns[col.Name.ValueString()]
+		data.Columns[i].Type = NewColumnTypeValue(actual.Type)
+		if actual.Comment != col.Comment.ValueString() {
+			data.Columns[i].Comment = optionalString(actual.Comment)
+		}
+	}
+
 	// Get actual ORDER BY clause if it's a MergeTree family engine
-	if r.isMergeTreeFamily(actualEngine) {
+	if isMergeTreeFamily(actualEngine) {
 		actualOrderBy, err := r.getTableOrderBy(ctx, database, tableName)
 		if err != nil {
 			resp.Diagnostics.AddError(
@@ -261,14 +981,116 @@ func (r *TableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 			return
 		}
 
+		// Without ORDER BY, the primary key is used as the sorting key
+		expectedOrderBy := data.OrderBy
+		if len(expectedOrderBy) == 0 {
+			expectedOrderBy = data.PrimaryKey
+		}
+
 		// Validate ORDER BY matches
-		if err := r.validateOrderBy(data.OrderBy, actualOrderBy); err != nil {
+		if err := r.validateKeyColumns("ORDER BY", expectedOrderBy, actualOrderBy); err != nil {
 			resp.Diagnostics.AddError(
 				"Table ORDER BY mismatch",
 				fmt.Sprintf("Table ORDER BY does not match configuration: %s", err.Error()),
 			)
 			return
 		}
+
+		// The primary key defaults to the sorting key, so it is only checked when configured
+		if len(data.PrimaryKey) > 0 {
+			actualPrimaryKey, err := r.getTablePrimaryKey(ctx, database, tableName)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error reading table PRIMARY KEY",
+					fmt.Sprintf("Could not read PRIMARY KEY for table %s: %s", data.ID.ValueString(), err.Error()),
+				)
+				return
+			}
+
+			if err := r.validateKeyColumns("PRIMARY KEY", data.PrimaryKey, actualPrimaryKey); err != nil {
+				resp.Diagnostics.AddError(
+					"Table PRIMARY KEY mismatch",
+					fmt.Sprintf("Table PRIMARY KEY does not match configuration: %s", err.Error()),
+				)
+				return
+			}
+		}
+
+		actualSampleBy, err := r.getTableSamplingKey(ctx, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table SAMPLE BY",
+				fmt.Sprintf("Could not read SAMPLE BY for table %s: %s", data.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+
+		if expected := data.SampleBy.ValueString(); normalizeQuery(expected) != normalizeQuery(actualSampleBy) {
+			resp.Diagnostics.AddError(
+				"Table SAMPLE BY mismatch",
+				fmt.Sprintf("Table SAMPLE BY does not match configuration: expected '%s', found '%s'", expected, actualSampleBy),
+			)
+			return
+		}
+	}
+
+	// Settings can be changed in place, so differences are reported as drift rather than errors
+	if data.Settings != nil {
+		actualSettings, err := r.getTableSettings(ctx, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table settings",
+				fmt.Sprintf("Could not read settings for table %s: %s", data.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+
+		settings := make(map[string]types.String, len(data.Settings))
+		for name := range data.Settings {
+			if value, ok := actualSettings[name]; ok {
+				settings[name] = types.StringValue(value)
+			}
+		}
+		data.Settings = settings
+	}
+
+	if metadata.Comment != data.Comment.ValueString() {
+		data.Comment = optionalString(metadata.Comment)
+	}
+
+	// Indexes can be changed in place too. Those of a clone come from its source and are left alone.
+	if isMergeTreeFamily(actualEngine) && data.AsTable.IsNull() {
+		actualIndexes, err := readTableIndexes(ctx, r.client, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table indexes",
+				fmt.Sprintf("Could not read data skipping indexes for table %s: %s", data.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+		data.Indexes = reconcileIndexes(ctx, r.client, data.Indexes, actualIndexes)
+
+		actualProjections, err := readTableProjections(ctx, r.client, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table projections",
+				fmt.Sprintf("Could not read projections for table %s: %s", data.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+		data.Projections = reconcileProjections(ctx, r.client, data.Projections, actualProjections)
+	}
+
+	if data.AsTable.IsNull() {
+		actualConstraints, err := readTableConstraints(ctx, r.client, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table constraints",
+				fmt.Sprintf("Could not read constraints for table %s: %s", data.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+		data.Constraints = reconcileConstraints(ctx, r.client, data.Constraints, actualConstraints)
 	}
 
 	tflog.Info(ctx, "Table schema validation successful", map[string]interface{}{
@@ -276,47 +1098,177 @@ func (r *TableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		"engine": actualEngine,
 	})
 
+	data.MetadataModificationTime = types.StringValue(metadata.ModificationTime)
+	data.CreateStatementHash = types.StringValue(metadata.CreateStatementHash)
+	data.SchemaFingerprint = types.StringValue(data.definition().fingerprint())
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *TableResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data TableResourceModel
+	var data, state TableResourceModel
 
-	// Read Terraform plan data into the model
+	// Read Terraform plan and prior state data into the models
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Not implemented yet
-	resp.Diagnostics.AddError("Update is not implemented", "Update is not implemented")
-}
+	desired, current := data.definition(), state.definition()
+
+	// The engine and the sorting key cannot be changed with ALTER TABLE
+	if desired.Engine != current.Engine || !sortingKeysEquivalent(desired.OrderBy, current.OrderBy) {
+		resp.Diagnostics.AddError(
+			"Unsupported table change",
+			fmt.Sprintf("The engine and ORDER BY of table %s cannot be changed in place. "+
+				"Recreate the table, e.g. with terraform apply -replace.", state.ID.ValueString()),
+		)
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+	ctx, cancel := withTimeout(ctx, data.Timeouts.updateTimeout())
+	defer cancel()
+
+	table := state.ID.ValueString()
+	ctx, err := withIndexSettings(ctx, r.client, current.Indexes, desired.Indexes)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unsupported table index",
+			fmt.Sprintf("Could not alter table %s: %s", table, err.Error()),
+		)
+		return
+	}
+
+	statements := tableAlterStatements(state, r.withDefaultCluster(data))
+	if len(statements) > 0 {
+		if err := r.client.waitForReplicaHealth(ctx, state.Database.ValueString(), state.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating table",
+				fmt.Sprintf("Could not alter table %s: %s", table, err.Error()),
+			)
+			return
+		}
+	}
+
+	for _, alterSQL := range statements {
+		tflog.Info(ctx, "Updating ClickHouse table", map[string]interface{}{
+			"sql": alterSQL,
+		})
+
+		if _, err := r.client.execOnShards(ctx, alterSQL, nil); err != nil {
+			resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+				"Error updating table",
+				fmt.Sprintf("Could not alter table %s", state.ID.ValueString()),
+				withStatement(alterSQL, err),
+			))
+			return
+		}
+	}
+
+	metadata, err := r.getTableMetadata(ctx, state.Database.ValueString(), state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading table metadata",
+			fmt.Sprintf("Could not read metadata of table %s: %s", state.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+	data.MetadataModificationTime = types.StringValue(metadata.ModificationTime)
+	data.CreateStatementHash = types.StringValue(metadata.CreateStatementHash)
+	data.SchemaFingerprint = types.StringValue(data.definition().fingerprint())
+	data.AppliedShards = state.AppliedShards
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TableResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data TableResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withExecutionSettings(ctx, data.ExecutionSettings)
+	ctx, cancel := withTimeout(ctx, data.Timeouts.deleteTimeout())
+	defer cancel()
+
+	resp.Diagnostics.Append(r.checkDeletionProtection(ctx, req.State, "dropped")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.DestroyBehavior.ValueString() == destroyBehaviorAbandon {
+		tflog.Info(ctx, "Abandoning ClickHouse table, removing it from state only", map[string]interface{}{
+			"id": data.ID.ValueString(),
+		})
+		return
+	}
+
+	dependents, err := r.getDependentViews(ctx, data.Database.ValueString(), data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading dependent views",
+			fmt.Sprintf("Could not list views depending on table %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	if len(dependents) > 0 {
+		if !data.CascadeDependents.ValueBool() {
+			resp.Diagnostics.AddError(
+				"Table has dependent views",
+				fmt.Sprintf("Table %s cannot be dropped while these views depend on it: %s. "+
+					"Remove them first or set cascade_dependents = true to drop and recreate them with the table.",
+					data.ID.ValueString(), dependentViewList(dependents)),
+			)
+			return
+		}
 
-func (r *TableResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var data TableResourceModel
+		cluster := onCluster(r.withDefaultCluster(data).Cluster.ValueString())
+		for _, view := range dependents {
+			viewDropSQL := "DROP VIEW IF EXISTS " + qualifiedName(view.Database, view.Name) + cluster
+			if data.DropSync.ValueBool() {
+				viewDropSQL += " SYNC"
+			}
 
-	// Read Terraform prior state data into the model
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() {
-		return
+			tflog.Info(ctx, "Dropping dependent view", map[string]interface{}{
+				"sql": viewDropSQL,
+			})
+
+			if _, err := r.client.execOnShards(ctx, viewDropSQL, nil); err != nil {
+				resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+					"Error dropping dependent view",
+					fmt.Sprintf("Could not drop view %s depending on table %s", view.ID(), data.ID.ValueString()),
+					withStatement(viewDropSQL, err),
+				))
+				return
+			}
+		}
+
+		// Keep the definitions so a replacement in the same apply recreates them
+		r.client.stashDependents(data.ID.ValueString(), dependents)
 	}
 
 	// Execute DROP TABLE statement
-	dropSQL := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s",
-		data.Database.ValueString(),
-		data.Name.ValueString())
+	dropSQL := r.withDefaultCluster(data).dropStatement()
 
 	tflog.Info(ctx, "Dropping ClickHouse table", map[string]interface{}{
 		"sql": dropSQL,
 	})
 
-	_, err := r.client.ExecContext(ctx, dropSQL)
+	_, err = r.client.execOnShards(ctx, dropSQL, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
 			"Error dropping table",
-			fmt.Sprintf("Could not drop table %s: %s", data.ID.ValueString(), err.Error()),
-		)
+			fmt.Sprintf("Could not drop table %s", data.ID.ValueString()),
+			withStatement(dropSQL, err),
+		))
 		return
 	}
 
@@ -355,14 +1307,7 @@ func (r *TableResource) ImportState(ctx context.Context, req resource.ImportStat
 	})
 
 	// Check if table exists and get its properties
-	tableQuery := `
-        SELECT engine
-        FROM system.tables 
-        WHERE database = ? AND name = ?
-    `
-
-	var engine string
-	err := r.client.QueryRowContext(ctx, tableQuery, database, tableName).Scan(&engine)
+	metadata, err := r.getTableMetadata(ctx, database, tableName)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			resp.Diagnostics.AddError(
@@ -378,6 +1323,8 @@ func (r *TableResource) ImportState(ctx context.Context, req resource.ImportStat
 		return
 	}
 
+	engine := metadata.Engine
+
 	// Get table columns
 	columns, err := r.getTableColumns(ctx, database, tableName)
 	if err != nil {
@@ -388,26 +1335,40 @@ func (r *TableResource) ImportState(ctx context.Context, req resource.ImportStat
 		return
 	}
 
-	// Convert columns map to slice for the model
+	// Keep the column order of the table so the generated configuration recreates it as is
+	columnNames, err := r.getTableColumnNames(ctx, database, tableName)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading table schema",
+			fmt.Sprintf("Could not read the column order of table %s.%s: %s", database, tableName, err.Error()),
+		)
+		return
+	}
 	var columnModels []ColumnModel
-	for _, col := range columns {
-		columnModel := ColumnModel{
-			Name: types.StringValue(col.Name),
-			Type: types.StringValue(col.Type),
+	for _, name := range columnNames {
+		if col, ok := columns[name]; ok {
+			columnModels = append(columnModels, columnModel(col))
 		}
+	}
 
-		if col.Comment != "" {
-			columnModel.Comment = types.StringValue(col.Comment)
-		} else {
-			columnModel.Comment = types.StringNull()
+	// The settings of Kafka and NATS tables belong to their engine block
+	tableEngine := engineModel(metadata.EngineFull)
+	var settings map[string]types.String
+	if tableEngine.Kafka == nil && tableEngine.Nats == nil {
+		for name, value := range parseEngineSettings(metadata.EngineFull) {
+			if settings == nil {
+				settings = map[string]types.String{}
+			}
+			settings[name] = types.StringValue(value)
 		}
-
-		columnModels = append(columnModels, columnModel)
 	}
 
-	// Get ORDER BY clause if it's a MergeTree family engine
-	var orderBy []types.String
-	if r.isMergeTreeFamily(engine) {
+	// Get ORDER BY and PRIMARY KEY clauses if it's a MergeTree family engine
+	var orderBy, primaryKey []types.String
+	sampleBy := types.StringNull()
+	var indexes []IndexModel
+	var projections []ProjectionModel
+	if isMergeTreeFamily(engine) {
 		orderByColumns, err := r.getTableOrderBy(ctx, database, tableName)
 		if err != nil {
 			resp.Diagnostics.AddError(
@@ -421,6 +1382,68 @@ func (r *TableResource) ImportState(ctx context.Context, req resource.ImportStat
 		for _, col := range orderByColumns {
 			orderBy = append(orderBy, types.StringValue(col))
 		}
+
+		// Only keep the primary key when it differs from the sorting key
+		primaryKeyColumns, err := r.getTablePrimaryKey(ctx, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table PRIMARY KEY",
+				fmt.Sprintf("Could not read PRIMARY KEY for table %s.%s: %s", database, tableName, err.Error()),
+			)
+			return
+		}
+		if !equalStrings(primaryKeyColumns, orderByColumns) {
+			for _, col := range primaryKeyColumns {
+				primaryKey = append(primaryKey, types.StringValue(col))
+			}
+		}
+
+		samplingKey, err := r.getTableSamplingKey(ctx, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table SAMPLE BY",
+				fmt.Sprintf("Could not read SAMPLE BY for table %s.%s: %s", database, tableName, err.Error()),
+			)
+			return
+		}
+		if samplingKey != "" {
+			sampleBy = types.StringValue(samplingKey)
+		}
+
+		actualIndexes, err := readTableIndexes(ctx, r.client, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table indexes",
+				fmt.Sprintf("Could not read data skipping indexes for table %s.%s: %s", database, tableName, err.Error()),
+			)
+			return
+		}
+		for _, index := range actualIndexes {
+			indexes = append(indexes, indexModel(index))
+		}
+
+		actualProjections, err := readTableProjections(ctx, r.client, database, tableName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading table projections",
+				fmt.Sprintf("Could not read projections for table %s.%s: %s", database, tableName, err.Error()),
+			)
+			return
+		}
+		projections = reconcileProjections(ctx, r.client, nil, actualProjections)
+	}
+
+	actualConstraints, err := readTableConstraints(ctx, r.client, database, tableName)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading table constraints",
+			fmt.Sprintf("Could not read constraints for table %s.%s: %s", database, tableName, err.Error()),
+		)
+		return
+	}
+	var constraints []ConstraintModel
+	for _, constraint := range actualConstraints {
+		constraints = append(constraints, constraintModel(constraint))
 	}
 
 	// Create the resource model with imported data
@@ -428,10 +1451,34 @@ func (r *TableResource) ImportState(ctx context.Context, req resource.ImportStat
 		ID:       types.StringValue(req.ID),
 		Name:     types.StringValue(tableName),
 		Database: types.StringValue(database),
-		Engine:   types.StringValue(engine),
+		Engine:   tableEngine,
 		Columns:  columnModels,
 		OrderBy:  orderBy,
+
+		PrimaryKey: primaryKey,
+		SampleBy:   sampleBy,
+		Indexes:    indexes,
+
+		Projections: projections,
+		Constraints: constraints,
+
+		Settings: settings,
+		Comment:  optionalString(metadata.Comment),
+
+		OnExisting:         types.StringValue(onExistingFail),
+		DeletionProtection: types.BoolValue(false),
+		DestroyBehavior:    types.StringValue(destroyBehaviorDrop),
+		DropSync:           types.BoolValue(false),
+		CascadeDependents:  types.BoolValue(false),
+		AllowExtraColumns:  types.BoolValue(false),
+		ExecutionSettings:  types.MapNull(types.StringType),
+		AppliedShards:      types.ListNull(types.Int64Type),
+		InferredColumns:    types.MapNull(types.StringType),
+
+		MetadataModificationTime: types.StringValue(metadata.ModificationTime),
+		CreateStatementHash:      types.StringValue(metadata.CreateStatementHash),
 	}
+	data.SchemaFingerprint = types.StringValue(data.definition().fingerprint())
 
 	tflog.Info(ctx, "Successfully imported ClickHouse table", map[string]interface{}{
 		"id":      data.ID.ValueString(),
@@ -443,45 +1490,199 @@ func (r *TableResource) ImportState(ctx context.Context, req resource.ImportStat
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// definition converts the resource model into a table definition
+func (m TableResourceModel) definition() tableDefinition {
+	def := tableDefinition{
+		Name:   m.Name.ValueString(),
+		Engine: m.Engine.redactedSQL(),
+	}
+	if engine := m.Engine.sql(); engine != def.Engine {
+		def.SecretEngine = engine
+	}
+	for _, col := range m.Columns {
+		def.Columns = append(def.Columns, col.info())
+	}
+	def.OrderBy = sortingKeyExpressions(m.OrderBy)
+	def.PrimaryKey = sortingKeyExpressions(m.PrimaryKey)
+	def.SampleBy = m.SampleBy.ValueString()
+	def.Comment = m.Comment.ValueString()
+	def.AsSelect = m.AsSelect.ValueString()
+	def.AsTable = m.AsTable.ValueString()
+	def.IfNotExists = m.OnExisting.ValueString() == onExistingIfNotExists
+	def.Cluster = m.Cluster.ValueString()
+	for _, index := range m.Indexes {
+		def.Indexes = append(def.Indexes, index.info())
+	}
+	for _, projection := range m.Projections {
+		def.Projections = append(def.Projections, projection.info())
+	}
+	for _, constraint := range m.Constraints {
+		def.Constraints = append(def.Constraints, constraint.info())
+	}
+	if m.Engine.Kafka != nil {
+		def.EngineSettings = m.Engine.Kafka.settings()
+		def.SecretSettings = m.Engine.Kafka.secretSettings()
+	}
+	if m.Engine.Nats != nil {
+		def.EngineSettings = m.Engine.Nats.settings()
+		def.SecretSettings = m.Engine.Nats.secretSettings()
+	}
+	for name, value := range m.Settings {
+		if def.Settings == nil {
+			def.Settings = map[string]string{}
+		}
+		def.Settings[name] = value.ValueString()
+	}
+	return def
+}
+
+// redactedCreateStatement renders the CREATE TABLE statement with the engine credentials hidden
+func (m TableResourceModel) redactedCreateStatement() string {
+	def := m.definition()
+	def.SecretEngine = ""
+	for name := range def.SecretSettings {
+		def.SecretSettings[name] = hiddenSecret
+	}
+	return tableCreateStatement(m.Database.ValueString(), def)
+}
+
+// dropStatement renders the statement removing the table on destroy
+func (m TableResourceModel) dropStatement() string {
+	table := qualifiedName(m.Database.ValueString(), m.Name.ValueString()) + onCluster(m.Cluster.ValueString())
+	statement := "DROP TABLE IF EXISTS " + table
+	if m.DestroyBehavior.ValueString() == destroyBehaviorDetach {
+		// The data directory stays on disk and the table is not attached back on restart
+		statement = fmt.Sprintf("DETACH TABLE IF EXISTS %s PERMANENTLY", table)
+	}
+	if m.DropSync.ValueBool() {
+		statement += " SYNC"
+	}
+	return statement
+}
+
+// tableAlterStatements returns the ALTER TABLE statements changing a table from its state to its plan
+func tableAlterStatements(state, plan TableResourceModel) []string {
+	desired, current := plan.definition(), state.definition()
+
+	table := qualifiedName(state.Database.ValueString(), state.Name.ValueString()) + onCluster(plan.Cluster.ValueString())
+	indexDrops, indexAdds := indexAlterStatements(table, current.Indexes, desired.Indexes)
+	projectionDrops, projectionAdds := projectionAlterStatements(table, state.Projections, plan.Projections)
+	constraintDrops, constraintAdds := constraintAlterStatements(table, current.Constraints, desired.Constraints)
+
+	// Elements depending on columns are dropped before the columns change and added afterwards
+	var statements []string
+	statements = append(statements, constraintDrops...)
+	statements = append(statements, projectionDrops...)
+	statements = append(statements, indexDrops...)
+	statements = append(statements, columnAlterStatements(table, current.Columns, desired.Columns)...)
+	statements = append(statements, tableSettingsStatements(table, state.Settings, plan.Settings)...)
+	statements = append(statements, indexAdds...)
+	statements = append(statements, projectionAdds...)
+	statements = append(statements, constraintAdds...)
+	if desired.Comment != current.Comment {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COMMENT %s", table, quoteString(desired.Comment)))
+	}
+	return statements
+}
+
+// withDefaultCluster returns the model with the provider cluster when the table does not set one. Tables
+// created shard by shard do not use ON CLUSTER.
+func (r *TableResource) withDefaultCluster(m TableResourceModel) TableResourceModel {
+	if m.Cluster.IsNull() && r.client.shardMode() {
+		return m
+	}
+	m.Cluster = types.StringValue(r.client.defaultCluster(m.Cluster))
+	return m
+}
+
 // generateCreateTableSQL generates the CREATE TABLE SQL statement
 func (r *TableResource) generateCreateTableSQL(data TableResourceModel) string {
-	sql := fmt.Sprintf("CREATE TABLE %s.%s (\n",
-		data.Database.ValueString(),
-		data.Name.ValueString())
+	return tableCreateStatement(data.Database.ValueString(), data.definition())
+}
 
-	// Add columns
-	for i, col := range data.Columns {
-		if i > 0 {
-			sql += ",\n"
-		}
-		sql += fmt.Sprintf("    %s %s", col.Name.ValueString(), col.Type.ValueString())
+// getTableMetadata retrieves the engine, comment and metadata version of a table from ClickHouse
+func (r *TableResource) getTableMetadata(ctx context.Context, database, tableName string) (tableMetadata, error) {
+	query := `
+        SELECT engine, engine_full, metadata_modification_time, create_table_query, comment
+        FROM system.tables
+        WHERE database = ? AND name = ?
+    `
+
+	var metadata tableMetadata
+	var modificationTime time.Time
+	var createQuery string
+	err := r.client.QueryRowContext(ctx, query, database, tableName).Scan(&metadata.Engine, &metadata.EngineFull,
+		&modificationTime, &createQuery, &metadata.Comment)
+	if err != nil {
+		return metadata, err
+	}
+
+	hash := sha256.Sum256([]byte(createQuery))
+	metadata.ModificationTime = modificationTime.UTC().Format(time.RFC3339)
+	metadata.CreateStatementHash = hex.EncodeToString(hash[:])
+
+	return metadata, nil
+}
 
-		if !col.Comment.IsNull() && !col.Comment.IsUnknown() {
-			sql += fmt.Sprintf(" COMMENT '%s'", col.Comment.ValueString())
+// getDependentViews lists the views reading from a table, and the materialized views writing to it
+func (r *TableResource) getDependentViews(ctx context.Context, database, tableName string) ([]dependentView, error) {
+	var dependencyDatabases, dependencyTables []string
+	err := r.client.QueryRowContext(ctx, `
+        SELECT dependencies_database, dependencies_table
+        FROM system.tables
+        WHERE database = ? AND name = ?
+    `, database, tableName).Scan(&dependencyDatabases, &dependencyTables)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
 		}
+		return nil, err
 	}
 
-	sql += fmt.Sprintf("\n) ENGINE = %s", data.Engine.ValueString())
+	readers := make(map[string]bool, len(dependencyTables))
+	for i := range dependencyTables {
+		readers[fmt.Sprintf("%s.%s", dependencyDatabases[i], dependencyTables[i])] = true
+	}
 
-	// Add ORDER BY clause if specified (needed for MergeTree engines)
-	if len(data.OrderBy) > 0 {
-		sql += "\nORDER BY ("
-		for i, orderCol := range data.OrderBy {
-			if i > 0 {
-				sql += ", "
-			}
-			sql += orderCol.ValueString()
+	rows, err := r.client.QueryContext(ctx, `
+        SELECT database, name, engine, create_table_query
+        FROM system.tables
+        WHERE engine IN ('View', 'MaterializedView', 'LiveView', 'WindowView')
+        ORDER BY database, name
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	target := fmt.Sprintf("%s.%s", database, tableName)
+	var views []dependentView
+	for rows.Next() {
+		var view dependentView
+		var engine string
+		if err := rows.Scan(&view.Database, &view.Name, &engine, &view.CreateQuery); err != nil {
+			return nil, err
+		}
+
+		if readers[view.ID()] {
+			views = append(views, view)
+			continue
+		}
+
+		// Materialized views writing to the table depend on it too
+		if match := materializedViewToPattern.FindStringSubmatch(view.CreateQuery); match != nil &&
+			strings.ReplaceAll(match[1], "`", "") == target {
+			views = append(views, view)
 		}
-		sql += ")"
 	}
 
-	return sql
+	return views, rows.Err()
 }
 
 // getTableColumns retrieves the actual column schema from ClickHouse
 func (r *TableResource) getTableColumns(ctx context.Context, database, tableName string) (map[string]ColumnInfo, error) {
 	query := `
-        SELECT name, type, comment
+        SELECT name, type, comment, default_kind, default_expression, compression_codec
         FROM system.columns
         WHERE database = ? AND table = ?
         ORDER BY position
@@ -496,9 +1697,9 @@ func (r *TableResource) getTableColumns(ctx context.Context, database, tableName
 	columns := make(map[string]ColumnInfo)
 	for rows.Next() {
 		var name, colType string
-		var comment sql.NullString
+		var comment, defaultKind, defaultExpr, codec sql.NullString
 
-		if err := rows.Scan(&name, &colType, &comment); err != nil {
+		if err := rows.Scan(&name, &colType, &comment, &defaultKind, &defaultExpr, &codec); err != nil {
 			return nil, err
 		}
 
@@ -506,10 +1707,57 @@ func (r *TableResource) getTableColumns(ctx context.Context, database, tableName
 			Name:    name,
 			Type:    colType,
 			Comment: comment.String,
+			Codec:   codec.String,
+
+			DefaultKind:       defaultKind.String,
+			DefaultExpression: defaultExpression(defaultKind.String, defaultExpr.String),
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Column TTLs are only exposed through the CREATE statement
+	var createQuery string
+	err = r.client.QueryRowContext(ctx, "SELECT create_table_query FROM system.tables WHERE database = ? AND name = ?",
+		database, tableName).Scan(&createQuery)
+	if err != nil {
+		return nil, err
+	}
+	for name, ttl := range columnTTLs(createQuery) {
+		if col, ok := columns[name]; ok {
+			col.TTL = ttl
+			columns[name] = col
 		}
 	}
 
-	return columns, rows.Err()
+	return columns, nil
+}
+
+// getTableColumnNames retrieves the column names of a table in their order
+func (r *TableResource) getTableColumnNames(ctx context.Context, database, tableName string) ([]string, error) {
+	query := `
+        SELECT name
+        FROM system.columns
+        WHERE database = ? AND table = ?
+        ORDER BY position
+    `
+
+	rows, err := r.client.QueryContext(ctx, query, database, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
 }
 
 // getTableOrderBy retrieves the ORDER BY clause from ClickHouse
@@ -526,28 +1774,130 @@ func (r *TableResource) getTableOrderBy(ctx context.Context, database, tableName
 		return nil, err
 	}
 
-	if !sortingKey.Valid || sortingKey.String == "" {
+	if !sortingKey.Valid {
 		return []string{}, nil
 	}
 
-	// Parse the sorting key (remove parentheses and split by comma)
-	orderBy := strings.Trim(sortingKey.String, "()")
-	if orderBy == "" {
+	return parseSortingKey(sortingKey.String), nil
+}
+
+// getTablePrimaryKey retrieves the PRIMARY KEY clause from ClickHouse
+func (r *TableResource) getTablePrimaryKey(ctx context.Context, database, tableName string) ([]string, error) {
+	query := `
+        SELECT primary_key
+        FROM system.tables
+        WHERE database = ? AND name = ?
+    `
+
+	var primaryKey sql.NullString
+	err := r.client.QueryRowContext(ctx, query, database, tableName).Scan(&primaryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !primaryKey.Valid {
 		return []string{}, nil
 	}
 
-	columns := strings.Split(orderBy, ",")
-	for i, col := range columns {
-		columns[i] = strings.TrimSpace(col)
+	return parseSortingKey(primaryKey.String), nil
+}
+
+// getTableSamplingKey retrieves the SAMPLE BY expression from ClickHouse, empty when the table has none
+func (r *TableResource) getTableSamplingKey(ctx context.Context, database, tableName string) (string, error) {
+	query := `
+        SELECT sampling_key
+        FROM system.tables
+        WHERE database = ? AND name = ?
+    `
+
+	var samplingKey sql.NullString
+	err := r.client.QueryRowContext(ctx, query, database, tableName).Scan(&samplingKey)
+	if err != nil {
+		return "", err
+	}
+
+	return samplingKey.String, nil
+}
+
+// getTableSettings retrieves the settings of the SETTINGS clause of a table from its full engine definition
+func (r *TableResource) getTableSettings(ctx context.Context, database, tableName string) (map[string]string, error) {
+	query := `
+        SELECT engine_full
+        FROM system.tables
+        WHERE database = ? AND name = ?
+    `
+
+	var engineFull string
+	if err := r.client.QueryRowContext(ctx, query, database, tableName).Scan(&engineFull); err != nil {
+		return nil, err
 	}
 
-	return columns, nil
+	return parseEngineSettings(engineFull), nil
+}
+
+// checkRemoteTable warns when the table a Distributed or Buffer engine refers to does not exist on the server
+func (r *TableResource) checkRemoteTable(ctx context.Context, plan tfsdk.Plan) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var engine, database, table types.String
+	diags.Append(plan.GetAttribute(ctx, path.Root("engine").AtName("name"), &engine)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("engine").AtName("remote_database"), &database)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("engine").AtName("remote_table"), &table)...)
+	if diags.HasError() || database.IsNull() || database.IsUnknown() || table.IsNull() || table.IsUnknown() {
+		return diags
+	}
+
+	var exists uint64
+	err := r.client.QueryRowContext(ctx, "SELECT count() FROM system.tables WHERE database = ? AND name = ?",
+		database.ValueString(), table.ValueString()).Scan(&exists)
+	if err != nil {
+		diags.AddWarning(
+			"Could not check the remote table",
+			fmt.Sprintf("Could not check if table %s.%s exists: %s", database.ValueString(), table.ValueString(), err.Error()),
+		)
+		return diags
+	}
+	if exists == 0 {
+		diags.AddAttributeWarning(
+			path.Root("engine").AtName("remote_table"),
+			"Remote table not found",
+			fmt.Sprintf("The %s table refers to %s.%s, which does not exist on the server. "+
+				"Queries fail until it is created.", engine.ValueString(), database.ValueString(), table.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// checkSettings validates the table settings against the ones known by the server
+func (r *TableResource) checkSettings(ctx context.Context, settings map[string]types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	values := make(map[string]string, len(settings))
+	for name, value := range settings {
+		values[name] = value.ValueString()
+	}
+
+	problems, err := validateMergeTreeSettings(ctx, r.client, values)
+	if err != nil {
+		diags.AddWarning(
+			"Could not validate table settings",
+			fmt.Sprintf("Table settings could not be checked against the server: %s", err.Error()),
+		)
+		return diags
+	}
+	for _, problem := range problems {
+		diags.AddAttributeError(path.Root("settings"), "Invalid table setting", problem)
+	}
+
+	return diags
 }
 
-// validateColumns compares expected vs actual columns
-func (r *TableResource) validateColumns(expectedCols []ColumnModel, actualCols map[string]ColumnInfo) error {
+// validateColumns compares expected vs actual columns. When allowExtra is set,
+// columns that only exist on the server are ignored.
+func (r *TableResource) validateColumns(ctx context.Context, expectedCols []ColumnModel, actualCols map[string]ColumnInfo, allowExtra bool) error {
 	// Check if we have the right number of columns
-	if len(expectedCols) != len(actualCols) {
+	if !allowExtra && len(expectedCols) != len(actualCols) {
 		return fmt.Errorf("expected %d columns, found %d columns", len(expectedCols), len(actualCols))
 	}
 
@@ -559,67 +1909,205 @@ func (r *TableResource) validateColumns(expectedCols []ColumnModel, actualCols m
 		}
 
 		// Validate column type
-		if actual.Type != expected.Type.ValueString() {
+		if !typesEquivalent(expected.Type.ValueString(), actual.Type) {
 			return fmt.Errorf("column '%s': expected type '%s', found type '%s'",
 				expected.Name.ValueString(), expected.Type.ValueString(), actual.Type)
 		}
 
-		// Validate comment if specified
-		expectedComment := ""
-		if !expected.Comment.IsNull() && !expected.Comment.IsUnknown() {
-			expectedComment = expected.Comment.ValueString()
+		// Validate the DEFAULT, MATERIALIZED, ALIAS or EPHEMERAL expression
+		want := expected.info()
+		if want.DefaultKind != actual.DefaultKind ||
+			!expressionsEquivalent(ctx, r.client, want.DefaultExpression, actual.DefaultExpression) {
+			return fmt.Errorf("column '%s': expected %s, found %s",
+				expected.Name.ValueString(), defaultDescription(want), defaultDescription(actual))
+		}
+
+		if expectedCodec := expected.Codec.ValueString(); !codecsEquivalent(expectedCodec, actual.Codec) {
+			return fmt.Errorf("column '%s': expected codec '%s', found codec '%s'",
+				expected.Name.ValueString(), expectedCodec, actual.Codec)
 		}
 
-		if actual.Comment != expectedComment {
-			return fmt.Errorf("column '%s': expected comment '%s', found comment '%s'",
-				expected.Name.ValueString(), expectedComment, actual.Comment)
+		// The server rewrites TTL expressions, e.g. INTERVAL 30 DAY becomes toIntervalDay(30)
+		if expectedTTL := expected.TTL.ValueString(); !expressionsEquivalent(ctx, r.client, expectedTTL, actual.TTL) {
+			return fmt.Errorf("column '%s': expected TTL '%s', found TTL '%s'",
+				expected.Name.ValueString(), expectedTTL, actual.TTL)
 		}
 	}
 
 	return nil
 }
 
-// validateOrderBy compares expected vs actual ORDER BY clauses
-func (r *TableResource) validateOrderBy(expected []types.String, actual []string) error {
-	expectedStrs := make([]string, len(expected))
-	for i, e := range expected {
-		expectedStrs[i] = e.ValueString()
+// inferredColumns splits the columns of a table created from a query or cloned from another one into the types
+// of the ones missing from the configuration, which were inferred, and the configured ones left to validate
+func inferredColumns(configured []ColumnModel, actual map[string]ColumnInfo) (types.Map, map[string]ColumnInfo) {
+	remaining := make(map[string]ColumnInfo, len(configured))
+	for _, col := range configured {
+		if info, ok := actual[col.Name.ValueString()]; ok {
+			remaining[col.Name.ValueString()] = info
+		}
+	}
+
+	inferred := map[string]attr.Value{}
+	for name, info := range actual {
+		if _, ok := remaining[name]; !ok {
+			inferred[name] = types.StringValue(info.Type)
+		}
 	}
+	return types.MapValueMust(types.StringType, inferred), remaining
+}
+
+// validateKeyColumns compares expected vs actual key clauses such as ORDER BY
+func (r *TableResource) validateKeyColumns(clause string, expected []types.String, actual []string) error {
+	expectedStrs := sortingKeyExpressions(expected)
 
 	if len(expectedStrs) != len(actual) {
-		return fmt.Errorf("expected ORDER BY with %d columns, found %d columns",
-			len(expectedStrs), len(actual))
+		return fmt.Errorf("expected %s with %d expressions, found %d expressions",
+			clause, len(expectedStrs), len(actual))
 	}
 
 	for i, expectedCol := range expectedStrs {
-		if expectedCol != actual[i] {
-			return fmt.Errorf("ORDER BY column %d: expected '%s', found '%s'",
-				i+1, expectedCol, actual[i])
+		if !sortingKeysEquivalent([]string{expectedCol}, []string{actual[i]}) {
+			return fmt.Errorf("%s expression %d: expected '%s', found '%s'",
+				clause, i+1, expectedCol, actual[i])
 		}
 	}
 
 	return nil
 }
 
+// sortingKeyExpressions returns the expressions of a configured sorting or primary key. Elements may be
+// column names, expressions such as cityHash64(user_id), or a whole tuple of them.
+func sortingKeyExpressions(values []types.String) []string {
+	var expressions []string
+	for _, value := range values {
+		expressions = append(expressions, parseSortingKey(value.ValueString())...)
+	}
+	return expressions
+}
+
 // isMergeTreeFamily checks if the engine is part of MergeTree family
-func (r *TableResource) isMergeTreeFamily(engine string) bool {
-	mergeTreeEngines := []string{
-		"MergeTree", "ReplacingMergeTree", "SummingMergeTree",
-		"AggregatingMergeTree", "CollapsingMergeTree", "VersionedCollapsingMergeTree",
-		"GraphiteMergeTree",
+func isMergeTreeFamily(engine string) bool {
+	// Replicated and Shared (ClickHouse Cloud) variants belong to the family of the engine they replicate
+	_, ok := engineParameterCounts[engineFamily(engine)]
+	return ok
+}
+
+// tableSettingsStatements generates the ALTER TABLE statements turning the current settings into the desired ones
+func tableSettingsStatements(table string, current, desired map[string]types.String) []string {
+	changed := map[string]types.String{}
+	for name, value := range desired {
+		if previous, ok := current[name]; !ok || previous.ValueString() != value.ValueString() {
+			changed[name] = value
+		}
 	}
 
-	for _, mt := range mergeTreeEngines {
-		if strings.HasPrefix(engine, mt) {
-			return true
+	var removed []string
+	for _, name := range sortedKeys(current) {
+		if _, ok := desired[name]; !ok {
+			removed = append(removed, name)
 		}
 	}
-	return false
+
+	var statements []string
+	if len(changed) > 0 {
+		values := make(map[string]string, len(changed))
+		for name, value := range changed {
+			values[name] = value.ValueString()
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY SETTING %s", table, settingAssignments(values)))
+	}
+	if len(removed) > 0 {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RESET SETTING %s", table, strings.Join(removed, ", ")))
+	}
+	return statements
+}
+
+// settingAssignments renders settings as a comma separated list of assignments
+func settingAssignments(settings map[string]string) string {
+	assignments := make([]string, 0, len(settings))
+	for _, name := range sortedKeys(settings) {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", name, settingValueSQL(settings[name])))
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// parseEngineSettings extracts the SETTINGS clause of a full engine definition, unquoting string values
+func parseEngineSettings(engineFull string) map[string]string {
+	settings := map[string]string{}
+
+	start := topLevelIndex(engineFull, " SETTINGS ")
+	if start < 0 {
+		return settings
+	}
+
+	for _, assignment := range splitTopLevel(engineFull[start+len(" SETTINGS "):] + ")") {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = strings.ReplaceAll(value[1:len(value)-1], "\\'", "'")
+		}
+		settings[strings.TrimSpace(name)] = value
+	}
+
+	return settings
+}
+
+// shardList converts shard numbers into the applied_shards value, null when DDL is not run shard by shard
+func shardList(client *clickhouseClient, shards []int) types.List {
+	if !client.shardMode() {
+		return types.ListNull(types.Int64Type)
+	}
+
+	values := make([]attr.Value, 0, len(shards))
+	for _, shard := range shards {
+		values = append(values, types.Int64Value(int64(shard)))
+	}
+	return types.ListValueMust(types.Int64Type, values)
+}
+
+// dependentView is a view depending on a table, with the statement recreating it
+type dependentView struct {
+	Database    string
+	Name        string
+	CreateQuery string
+}
+
+// ID returns the qualified name of the view
+func (v dependentView) ID() string {
+	return fmt.Sprintf("%s.%s", v.Database, v.Name)
+}
+
+func dependentViewList(views []dependentView) string {
+	names := make([]string, len(views))
+	for i, view := range views {
+		names[i] = view.ID()
+	}
+	return strings.Join(names, ", ")
+}
+
+// tableMetadata represents the engine, comment and metadata version of a table
+type tableMetadata struct {
+	Engine              string
+	EngineFull          string
+	Comment             string
+	ModificationTime    string
+	CreateStatementHash string
 }
 
 // ColumnInfo represents actual column information from ClickHouse
 type ColumnInfo struct {
-	Name    string
-	Type    string
-	Comment string
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Comment string `json:"comment,omitempty"`
+	Codec   string `json:"codec,omitempty"`
+	TTL     string `json:"ttl,omitempty"`
+
+	DefaultKind       string `json:"default_kind,omitempty"`
+	DefaultExpression string `json:"default_expression,omitempty"`
+
+	// PreviousName is the name the column is renamed from; it is not part of the definition
+	PreviousName string `json:"-"`
 }
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// S3Model describes the parameters of an S3 engine.
+type S3Model struct {
+	Path            types.String `tfsdk:"path"`
+	Format          types.String `tfsdk:"format"`
+	Compression     types.String `tfsdk:"compression"`
+	NamedCollection types.String `tfsdk:"named_collection"`
+}
+
+// s3Block returns the schema of the parameters of an S3 engine
+func s3Block() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Parameters of an S3 engine, reading and writing files in a bucket",
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Bucket URL with the path of the files, which may contain globs when the table is read only " +
+					"(e.g. `https://bucket.s3.amazonaws.com/events/*.parquet`). Required unless `named_collection` provides it",
+				Optional: true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "File format (e.g. `Parquet`, `CSVWithNames`). Detected from the file extension when not set",
+				Optional:            true,
+			},
+			"compression": schema.StringAttribute{
+				MarkdownDescription: "Compression of the files (e.g. `gzip`, `zstd`). Detected from the file extension when not set",
+				Optional:            true,
+			},
+			"named_collection": schema.StringAttribute{
+				MarkdownDescription: "Named collection holding the URL and credentials of the bucket. `path`, `format` and " +
+					"`compression` override its keys",
+				Optional: true,
+			},
+		},
+	}
+}
+
+// arguments returns the parameters of the S3 engine, as key-value overrides when a named collection is used
+func (m *S3Model) arguments() []types.String {
+	if !m.NamedCollection.IsNull() {
+		arguments := []types.String{m.NamedCollection}
+		for _, argument := range []struct {
+			Key   string
+			Value types.String
+		}{{"url", m.Path}, {"format", m.Format}, {"compression", m.Compression}} {
+			if !argument.Value.IsNull() {
+				arguments = append(arguments, types.StringValue(fmt.Sprintf("%s = %s", argument.Key, quoteString(argument.Value.ValueString()))))
+			}
+		}
+		return arguments
+	}
+
+	arguments := []types.String{types.StringValue(quoteString(m.Path.ValueString()))}
+	switch {
+	case !m.Compression.IsNull():
+		// The compression is the third positional parameter, so the format must be passed too
+		format := "auto"
+		if !m.Format.IsNull() {
+			format = m.Format.ValueString()
+		}
+		arguments = append(arguments, types.StringValue(quoteString(format)), types.StringValue(quoteString(m.Compression.ValueString())))
+	case !m.Format.IsNull():
+		arguments = append(arguments, types.StringValue(quoteString(m.Format.ValueString())))
+	}
+	return arguments
+}
+
+// s3Model converts the parameters of an S3 engine read from ClickHouse into their Terraform model.
+// Tables created with credentials in their parameters are not converted.
+func s3Model(parameters []string) (*S3Model, bool) {
+	model := &S3Model{
+		Path:            types.StringNull(),
+		Format:          types.StringNull(),
+		Compression:     types.StringNull(),
+		NamedCollection: types.StringNull(),
+	}
+	if len(parameters) == 0 {
+		return nil, false
+	}
+
+	if !strings.HasPrefix(parameters[0], "'") {
+		model.NamedCollection = types.StringValue(parameters[0])
+		for _, parameter := range parameters[1:] {
+			key, value, ok := strings.Cut(parameter, "=")
+			if !ok {
+				return nil, false
+			}
+			value = unquoteString(strings.TrimSpace(value))
+			switch strings.TrimSpace(key) {
+			case "url":
+				model.Path = types.StringValue(value)
+			case "format":
+				model.Format = types.StringValue(value)
+			case "compression", "compression_method":
+				model.Compression = types.StringValue(value)
+			default:
+				return nil, false
+			}
+		}
+		return model, true
+	}
+
+	model.Path = types.StringValue(unquoteString(parameters[0]))
+	switch len(parameters) {
+	case 1:
+	case 2:
+		model.Format = types.StringValue(unquoteString(parameters[1]))
+	case 3:
+		// Three parameters are either the format and compression or credentials, whose secret key the server hides
+		if strings.EqualFold(unquoteString(parameters[1]), "NOSIGN") || unquoteString(parameters[2]) == hiddenSecret {
+			return nil, false
+		}
+		model.Format = types.StringValue(unquoteString(parameters[1]))
+		model.Compression = types.StringValue(unquoteString(parameters[2]))
+	default:
+		return nil, false
+	}
+	return model, true
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TimeoutsModel describes how long the create, update and delete operations of a table may take
+type TimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// timeoutsBlock returns the schema of the operation timeouts of a table
+func timeoutsBlock() schema.Block {
+	timeout := func(operation string) schema.StringAttribute {
+		return schema.StringAttribute{
+			MarkdownDescription: fmt.Sprintf("Duration the %s may take (e.g. `30m`), parsed with Go's `time.ParseDuration`", operation),
+			Optional:            true,
+		}
+	}
+
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Timeouts of the table operations. A timeout also raises the `max_execution_time` of the " +
+			"statements, so large `ON CLUSTER` DDL and mutations are not stopped by the provider default of 60 seconds",
+		Attributes: map[string]schema.Attribute{
+			"create": timeout("creation of the table"),
+			"update": timeout("update of the table"),
+			"delete": timeout("removal of the table"),
+		},
+	}
+}
+
+// validateTimeouts reports timeouts that are not positive durations
+func validateTimeouts(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, operation := range []string{"create", "update", "delete"} {
+		var timeout types.String
+		diags.Append(config.GetAttribute(ctx, path.Root("timeouts").AtName(operation), &timeout)...)
+		if timeout.IsNull() || timeout.IsUnknown() {
+			continue
+		}
+		if duration, err := time.ParseDuration(timeout.ValueString()); err != nil || duration <= 0 {
+			diags.AddAttributeError(path.Root("timeouts").AtName(operation), "Invalid timeout",
+				fmt.Sprintf("%s timeout must be a positive duration such as 30m or 2h, got %s.", operation, timeout.ValueString()))
+		}
+	}
+
+	return diags
+}
+
+// withTimeout bounds ctx by the configured timeout of an operation. Without a timeout the provider
+// default max_execution_time applies.
+func withTimeout(ctx context.Context, timeout types.String) (context.Context, context.CancelFunc) {
+	if timeout.IsNull() || timeout.IsUnknown() {
+		return ctx, func() {}
+	}
+	duration, err := time.ParseDuration(timeout.ValueString())
+	if err != nil || duration <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, duration)
+}
+
+// createTimeout returns the configured timeout of the creation, or null
+func (t *TimeoutsModel) createTimeout() types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+	return t.Create
+}
+
+// updateTimeout returns the configured timeout of updates, or null
+func (t *TimeoutsModel) updateTimeout() types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+	return t.Update
+}
+
+// deleteTimeout returns the configured timeout of the removal, or null
+func (t *TimeoutsModel) deleteTimeout() types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+	return t.Delete
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// URLModel describes the parameters of a URL engine.
+type URLModel struct {
+	URL         types.String `tfsdk:"url"`
+	Format      types.String `tfsdk:"format"`
+	Compression types.String `tfsdk:"compression"`
+}
+
+// FileModel describes the parameters of a File engine.
+type FileModel struct {
+	Format types.String `tfsdk:"format"`
+}
+
+// urlBlock returns the schema of the parameters of a URL engine
+func urlBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Parameters of a URL engine, reading with `GET` and writing with `POST` requests to an HTTP server",
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				MarkdownDescription: "URL of the data (e.g. `https://example.com/events.csv`)",
+				Required:            true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "Data format (e.g. `CSVWithNames`, `JSONEachRow`)",
+				Required:            true,
+			},
+			"compression": schema.StringAttribute{
+				MarkdownDescription: "Compression of the data (e.g. `gzip`, `zstd`). Detected from the URL extension when not set",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// fileBlock returns the schema of the parameters of a File engine
+func fileBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Parameters of a File engine, storing the data in a file of the table directory",
+		Attributes: map[string]schema.Attribute{
+			"format": schema.StringAttribute{
+				MarkdownDescription: "File format (e.g. `TabSeparated`, `Parquet`)",
+				Required:            true,
+			},
+		},
+	}
+}
+
+// arguments returns the parameters of the URL engine
+func (m *URLModel) arguments() []types.String {
+	arguments := []types.String{
+		types.StringValue(quoteString(m.URL.ValueString())),
+		types.StringValue(quoteString(m.Format.ValueString())),
+	}
+	if !m.Compression.IsNull() {
+		arguments = append(arguments, types.StringValue(quoteString(m.Compression.ValueString())))
+	}
+	return arguments
+}
+
+// arguments returns the parameters of the File engine
+func (m *FileModel) arguments() []types.String {
+	return []types.String{m.Format}
+}
+
+// urlModel converts the parameters of a URL engine read from ClickHouse into their Terraform model
+func urlModel(parameters []string) (*URLModel, bool) {
+	if len(parameters) < 2 || len(parameters) > 3 {
+		return nil, false
+	}
+
+	model := &URLModel{
+		URL:         types.StringValue(unquoteString(parameters[0])),
+		Format:      types.StringValue(unquoteString(parameters[1])),
+		Compression: types.StringNull(),
+	}
+	if len(parameters) == 3 {
+		model.Compression = types.StringValue(unquoteString(parameters[2]))
+	}
+	return model, true
+}
+
+// fileModel converts the parameters of a File engine read from ClickHouse into their Terraform model.
+// Tables reading from a file descriptor or a path outside of the table directory are not converted.
+func fileModel(parameters []string) (*FileModel, bool) {
+	if len(parameters) != 1 {
+		return nil, false
+	}
+	return &FileModel{Format: types.StringValue(unquoteString(parameters[0]))}, true
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ConfigValidator = sortingKeyRequiredValidator{}
+	_ resource.ConfigValidator = sortingKeyEngineValidator{}
+	_ resource.ConfigValidator = sortingKeyColumnsValidator{}
+)
+
+func (r *TableResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		sortingKeyRequiredValidator{},
+		sortingKeyEngineValidator{},
+		sortingKeyColumnsValidator{},
+	}
+}
+
+// sortingKeyRequiredValidator reports MergeTree family tables configured without a sorting key, which the
+// server rejects on create
+type sortingKeyRequiredValidator struct{}
+
+func (v sortingKeyRequiredValidator) Description(ctx context.Context) string {
+	return "MergeTree family engines require order_by or primary_key"
+}
+
+func (v sortingKeyRequiredValidator) MarkdownDescription(ctx context.Context) string {
+	return "MergeTree family engines require `order_by` or `primary_key`"
+}
+
+func (v sortingKeyRequiredValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var engine, asTable types.String
+	var orderBy, primaryKey types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("engine").AtName("name"), &engine)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("as_table"), &asTable)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("order_by"), &orderBy)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("primary_key"), &primaryKey)...)
+	if resp.Diagnostics.HasError() || engine.IsNull() || engine.IsUnknown() || !isMergeTreeFamily(engine.ValueString()) {
+		return
+	}
+	// Clones copy the sorting key of their source
+	if !asTable.IsNull() || orderBy.IsUnknown() || primaryKey.IsUnknown() {
+		return
+	}
+
+	if len(orderBy.Elements()) == 0 && len(primaryKey.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("order_by"), "Missing sorting key",
+			fmt.Sprintf("%s tables require order_by or primary_key; set order_by to the columns the rows are sorted by.",
+				engine.ValueString()))
+	}
+}
+
+// sortingKeyEngineValidator reports sorting, primary and sampling keys configured on engines without them, such as
+// Memory, Null, Set or Log
+type sortingKeyEngineValidator struct{}
+
+func (v sortingKeyEngineValidator) Description(ctx context.Context) string {
+	return "order_by, primary_key and sample_by are only supported by MergeTree family engines"
+}
+
+func (v sortingKeyEngineValidator) MarkdownDescription(ctx context.Context) string {
+	return "`order_by`, `primary_key` and `sample_by` are only supported by MergeTree family engines"
+}
+
+func (v sortingKeyEngineValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var engine, sampleBy types.String
+	var orderBy, primaryKey types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("engine").AtName("name"), &engine)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("order_by"), &orderBy)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("primary_key"), &primaryKey)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("sample_by"), &sampleBy)...)
+	if resp.Diagnostics.HasError() || engine.IsNull() || engine.IsUnknown() || isMergeTreeFamily(engine.ValueString()) {
+		return
+	}
+
+	for _, key := range []struct {
+		Attribute string
+		Value     attr.Value
+	}{{"order_by", orderBy}, {"primary_key", primaryKey}, {"sample_by", sampleBy}} {
+		if !key.Value.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root(key.Attribute), "Unsupported table key",
+				fmt.Sprintf("%s is only supported by the MergeTree family engines, not by %s.", key.Attribute, engine.ValueString()))
+		}
+	}
+}
+
+// sortingKeyColumnsValidator reports sorting and primary key columns missing from the column list. Elements
+// other than plain column names are expressions, which the server validates.
+type sortingKeyColumnsValidator struct{}
+
+func (v sortingKeyColumnsValidator) Description(ctx context.Context) string {
+	return "order_by and primary_key columns must be defined in columns"
+}
+
+func (v sortingKeyColumnsValidator) MarkdownDescription(ctx context.Context) string {
+	return "`order_by` and `primary_key` columns must be defined in `columns`"
+}
+
+func (v sortingKeyColumnsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var columns types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("columns"), &columns)...)
+	// Columns inferred from a query or copied from a clone are only known on create
+	if resp.Diagnostics.HasError() || columns.IsNull() || columns.IsUnknown() || len(columns.Elements()) == 0 {
+		return
+	}
+
+	var columnModels []ColumnModel
+	resp.Diagnostics.Append(columns.ElementsAs(ctx, &columnModels, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defined := map[string]bool{}
+	for _, column := range columnModels {
+		if column.Name.IsUnknown() {
+			return
+		}
+		defined[identifierName(column.Name.ValueString())] = true
+	}
+
+	for _, attribute := range []string{"order_by", "primary_key"} {
+		var key types.List
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &key)...)
+		if resp.Diagnostics.HasError() || key.IsNull() || key.IsUnknown() {
+			continue
+		}
+
+		var keyColumns []types.String
+		resp.Diagnostics.Append(key.ElementsAs(ctx, &keyColumns, false)...)
+		for i, column := range keyColumns {
+			if column.IsUnknown() || column.IsNull() {
+				continue
+			}
+			name := column.ValueString()
+			if !plainIdentifierPattern.MatchString(name) && !quotedIdentifier.MatchString(name) {
+				continue
+			}
+			if !defined[identifierName(name)] {
+				resp.Diagnostics.AddAttributeError(path.Root(attribute).AtListIndex(i), "Unknown sorting key column",
+					fmt.Sprintf("%s references column %s, which is not defined in columns.", attribute, name))
+			}
+		}
+	}
+}
+
+// identifierName returns the name an identifier stands for, without its backticks
+func identifierName(identifier string) string {
+	if !quotedIdentifier.MatchString(identifier) {
+		return identifier
+	}
+	return strings.NewReplacer("\\`", "`", `\\`, `\`).Replace(identifier[1 : len(identifier)-1])
+}
@@ -0,0 +1,560 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &UserResource{}
+var _ resource.ResourceWithImportState = &UserResource{}
+var _ resource.ResourceWithValidateConfig = &UserResource{}
+
+func NewUserResource() resource.Resource {
+	return &UserResource{}
+}
+
+// UserResource manages a ClickHouse user.
+type UserResource struct {
+	client *clickhouseClient
+}
+
+// UserResourceModel describes the resource data model.
+type UserResourceModel struct {
+	ID              types.String   `tfsdk:"id"`
+	Name            types.String   `tfsdk:"name"`
+	AuthType        types.String   `tfsdk:"auth_type"`
+	Password        types.String   `tfsdk:"password"`
+	HostIPs         []types.String `tfsdk:"host_ips"`
+	HostNames       []types.String `tfsdk:"host_names"`
+	HostRegexps     []types.String `tfsdk:"host_regexps"`
+	HostLikes       []types.String `tfsdk:"host_likes"`
+	DefaultRoles    []types.String `tfsdk:"default_roles"`
+	DefaultDatabase types.String   `tfsdk:"default_database"`
+	SettingsProfile types.String   `tfsdk:"settings_profile"`
+	Grantees        []types.String `tfsdk:"grantees"`
+	Cluster         types.String   `tfsdk:"cluster"`
+	Grants          types.List     `tfsdk:"grants"`
+	GrantedRoles    types.List     `tfsdk:"granted_roles"`
+}
+
+const (
+	authTypeSHA256    = "sha256_password"
+	authTypePlaintext = "plaintext_password"
+	authTypeNone      = "no_password"
+)
+
+func (r *UserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a ClickHouse user created with `CREATE USER`. Renaming the user runs " +
+			"`ALTER USER ... RENAME TO` instead of recreating it. Passwords cannot be read back from the server, " +
+			"so changes made to them outside of Terraform are not detected. The privileges and roles granted to the " +
+			"user are reported by `grants` and `granted_roles`, so that they can be adopted with `clickhouse_grant` " +
+			"resources after importing the user.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "User identifier",
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "User name",
+				Required:            true,
+			},
+			"auth_type": schema.StringAttribute{
+				MarkdownDescription: "Identification method: `sha256_password` (default), `plaintext_password` or `no_password`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(authTypeSHA256),
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password of the user, required unless `auth_type` is `no_password`",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"host_ips": schema.ListAttribute{
+				MarkdownDescription: "IP addresses or subnets the user may connect from (e.g. `10.0.0.0/8`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"host_names": schema.ListAttribute{
+				MarkdownDescription: "Host names the user may connect from",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"host_regexps": schema.ListAttribute{
+				MarkdownDescription: "Regular expressions matching the host names the user may connect from",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"host_likes": schema.ListAttribute{
+				MarkdownDescription: "LIKE patterns matching the host names the user may connect from. " +
+					"When no host restriction is set, the user may connect from any host.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"default_roles": schema.ListAttribute{
+				MarkdownDescription: "Roles enabled when the user logs in. All granted roles when unset, none when empty.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"default_database": schema.StringAttribute{
+				MarkdownDescription: "Database selected when the user logs in",
+				Optional:            true,
+			},
+			"settings_profile": schema.StringAttribute{
+				MarkdownDescription: "Settings profile applied to the user",
+				Optional:            true,
+			},
+			"grantees": schema.ListAttribute{
+				MarkdownDescription: "Users or roles the user may grant its privileges to. Anyone when unset, nobody when empty.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"cluster": schema.StringAttribute{
+				MarkdownDescription: "Cluster on which the user is created with `ON CLUSTER`, defaulting to the provider `cluster`. " +
+					"An empty string creates it on the connected server only",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"grants":        grantsAttribute("user"),
+			"granted_roles": grantedRolesAttribute("user"),
+		},
+	}
+}
+
+func (r *UserResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var authType, password types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("auth_type"), &authType)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password"), &password)...)
+	if resp.Diagnostics.HasError() || authType.IsUnknown() {
+		return
+	}
+
+	switch authType.ValueString() {
+	case "", authTypeSHA256, authTypePlaintext:
+		if password.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password"),
+				"Missing password",
+				"A password is required unless auth_type is no_password.",
+			)
+		}
+	case authTypeNone:
+		if !password.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password"),
+				"Unexpected password",
+				"A password cannot be set when auth_type is no_password.",
+			)
+		}
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("auth_type"),
+			"Invalid identification method",
+			fmt.Sprintf("Expected one of %s, %s or %s, got: %s", authTypeSHA256, authTypePlaintext, authTypeNone, authType.ValueString()),
+		)
+	}
+}
+
+func (r *UserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*clickhouseClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *clickhouseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data UserResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createSQL := fmt.Sprintf("CREATE USER %s%s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)), userClauses(data, false))
+
+	tflog.Info(ctx, "Creating ClickHouse user", map[string]interface{}{
+		"name": data.Name.ValueString(),
+	})
+
+	if _, err := r.client.ExecContext(ctx, createSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error creating user",
+			fmt.Sprintf("Could not create user %s", data.Name.ValueString()),
+			withStatement(redactPassword(createSQL, data.Password), err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+	data.Grants, data.GrantedRoles = grantList(nil), roleGrantList(nil)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data UserResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.readUser(ctx, data.ID.ValueString(), data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			tflog.Info(ctx, "User no longer exists, removing from state", map[string]interface{}{
+				"id": data.ID.ValueString(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading user",
+			fmt.Sprintf("Could not read user %s: %s", data.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &current)...)
+}
+
+func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state UserResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alterSQL := userAlterStatement(state, data, r.client.defaultCluster(data.Cluster))
+
+	tflog.Info(ctx, "Updating ClickHouse user", map[string]interface{}{
+		"name": state.Name.ValueString(),
+	})
+
+	if _, err := r.client.ExecContext(ctx, alterSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error updating user",
+			fmt.Sprintf("Could not alter user %s", state.Name.ValueString()),
+			withStatement(redactPassword(alterSQL, data.Password), err),
+		))
+		return
+	}
+
+	data.ID = data.Name
+
+	// State saved without the granted privileges, e.g. not refreshed before the plan, has nothing to keep
+	if data.Grants.IsUnknown() || data.GrantedRoles.IsUnknown() {
+		var err error
+		data.Grants, data.GrantedRoles, err = readAccessRights(ctx, r.client, data.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading user grants",
+				fmt.Sprintf("Could not read the privileges of user %s: %s", data.Name.ValueString(), err.Error()),
+			)
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data UserResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dropSQL := fmt.Sprintf("DROP USER IF EXISTS %s%s", quoteIdentifier(data.Name.ValueString()), onCluster(r.client.defaultCluster(data.Cluster)))
+
+	tflog.Info(ctx, "Dropping ClickHouse user", map[string]interface{}{
+		"sql": dropSQL,
+	})
+
+	if _, err := r.client.ExecContext(ctx, dropSQL); err != nil {
+		resp.Diagnostics.Append(clickhouseErrorDiagnostic(
+			"Error dropping user",
+			fmt.Sprintf("Could not drop user %s", data.Name.ValueString()),
+			withStatement(dropSQL, err),
+		))
+	}
+}
+
+func (r *UserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	data, err := r.readUser(ctx, req.ID, UserResourceModel{Cluster: types.StringNull()})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error importing user",
+			fmt.Sprintf("Could not read user %s from system.users: %s", req.ID, err.Error()),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readUser reads a user from system.users. Values the server reports differently
+// from the configuration (e.g. no host restriction) keep their prior spelling.
+func (r *UserResource) readUser(ctx context.Context, name string, prior UserResourceModel) (UserResourceModel, error) {
+	query := `
+        SELECT toString(auth_type), host_ip, host_names, host_names_regexp, host_names_like,
+               default_roles_all, default_roles_list, default_database, grantees_any, grantees_list
+        FROM system.users
+        WHERE name = ?
+    `
+
+	var authType, defaultDatabase string
+	var hostIPs, hostNames, hostRegexps, hostLikes, defaultRoles, grantees []string
+	var defaultRolesAll, granteesAny uint8
+	err := r.client.QueryRowContext(ctx, query, name).Scan(&authType, &hostIPs, &hostNames, &hostRegexps, &hostLikes,
+		&defaultRolesAll, &defaultRoles, &defaultDatabase, &granteesAny, &grantees)
+	if err != nil {
+		return UserResourceModel{}, err
+	}
+
+	// Newer servers report the identification methods as an array
+	authType = strings.Trim(strings.SplitN(strings.Trim(authType, "[]"), ",", 2)[0], "' ")
+
+	// HOST ANY is reported as the ::/0 subnet
+	if len(hostIPs) == 1 && hostIPs[0] == "::/0" {
+		hostIPs = nil
+	}
+
+	data := UserResourceModel{
+		ID:              types.StringValue(name),
+		Name:            types.StringValue(name),
+		AuthType:        types.StringValue(authType),
+		Password:        prior.Password,
+		HostIPs:         stringValues(hostIPs, prior.HostIPs),
+		HostNames:       stringValues(hostNames, prior.HostNames),
+		HostRegexps:     stringValues(hostRegexps, prior.HostRegexps),
+		HostLikes:       stringValues(hostLikes, prior.HostLikes),
+		DefaultDatabase: types.StringNull(),
+		SettingsProfile: types.StringNull(),
+		Cluster:         prior.Cluster,
+	}
+	if defaultDatabase != "" {
+		data.DefaultDatabase = types.StringValue(defaultDatabase)
+	}
+	if defaultRolesAll == 0 {
+		data.DefaultRoles = stringValues(defaultRoles, []types.String{})
+	}
+	if granteesAny == 0 {
+		data.Grantees = stringValues(grantees, []types.String{})
+	}
+
+	_, profile, err := readSettingsProfileElements(ctx, r.client, "user_name", name)
+	if err != nil {
+		return UserResourceModel{}, err
+	}
+	if profile != "" {
+		data.SettingsProfile = types.StringValue(profile)
+	}
+
+	data.Grants, data.GrantedRoles, err = readAccessRights(ctx, r.client, name)
+	if err != nil {
+		return UserResourceModel{}, err
+	}
+
+	return data, nil
+}
+
+// userAlterStatement builds the ALTER USER statement turning the user in state into the planned one. A name
+// change renames the user in the same statement, so its grants, role memberships and settings are kept.
+func userAlterStatement(state, data UserResourceModel, cluster string) string {
+	statement := fmt.Sprintf("ALTER USER %s%s", quoteIdentifier(state.Name.ValueString()), onCluster(cluster))
+	if data.Name.ValueString() != state.Name.ValueString() {
+		statement += fmt.Sprintf(" RENAME TO %s", quoteIdentifier(data.Name.ValueString()))
+	}
+	return statement + userClauses(data, true)
+}
+
+// userClauses builds the clauses shared by CREATE USER and ALTER USER. When
+// altering, unset options are reset explicitly.
+func userClauses(data UserResourceModel, alter bool) string {
+	var clauses []string
+
+	switch data.AuthType.ValueString() {
+	case authTypeNone:
+		clauses = append(clauses, "NOT IDENTIFIED")
+	default:
+		clauses = append(clauses, fmt.Sprintf("IDENTIFIED WITH %s BY %s", data.AuthType.ValueString(), quoteString(data.Password.ValueString())))
+	}
+
+	var hosts []string
+	for _, host := range data.HostIPs {
+		hosts = append(hosts, "IP "+quoteString(host.ValueString()))
+	}
+	for _, host := range data.HostNames {
+		hosts = append(hosts, "NAME "+quoteString(host.ValueString()))
+	}
+	for _, host := range data.HostRegexps {
+		hosts = append(hosts, "REGEXP "+quoteString(host.ValueString()))
+	}
+	for _, host := range data.HostLikes {
+		hosts = append(hosts, "LIKE "+quoteString(host.ValueString()))
+	}
+	switch {
+	case len(hosts) > 0:
+		clauses = append(clauses, "HOST "+strings.Join(hosts, ", "))
+	case alter:
+		clauses = append(clauses, "HOST ANY")
+	}
+
+	switch {
+	case data.DefaultRoles == nil:
+		if alter {
+			clauses = append(clauses, "DEFAULT ROLE ALL")
+		}
+	case len(data.DefaultRoles) == 0:
+		clauses = append(clauses, "DEFAULT ROLE NONE")
+	default:
+		clauses = append(clauses, "DEFAULT ROLE "+joinIdentifiers(data.DefaultRoles))
+	}
+
+	switch {
+	case !data.DefaultDatabase.IsNull():
+		clauses = append(clauses, "DEFAULT DATABASE "+quoteIdentifier(data.DefaultDatabase.ValueString()))
+	case alter:
+		clauses = append(clauses, "DEFAULT DATABASE NONE")
+	}
+
+	switch {
+	case data.Grantees == nil:
+		if alter {
+			clauses = append(clauses, "GRANTEES ANY")
+		}
+	case len(data.Grantees) == 0:
+		clauses = append(clauses, "GRANTEES NONE")
+	default:
+		clauses = append(clauses, "GRANTEES "+joinIdentifiers(data.Grantees))
+	}
+
+	switch {
+	case !data.SettingsProfile.IsNull():
+		clauses = append(clauses, "SETTINGS PROFILE "+quoteString(data.SettingsProfile.ValueString()))
+	case alter:
+		clauses = append(clauses, "SETTINGS NONE")
+	}
+
+	return " " + strings.Join(clauses, " ")
+}
+
+// quoteString renders s as a ClickHouse string literal
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// quoteIdentifier renders name as a ClickHouse identifier quoted with backticks, so names with spaces, quotes
+// or keywords cannot break the statement. Names the configuration already quotes are kept as is.
+func quoteIdentifier(name string) string {
+	if quotedIdentifier.MatchString(name) {
+		return name
+	}
+	return "`" + strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(name) + "`"
+}
+
+// qualifiedName renders the quoted name of a table or view of a database
+func qualifiedName(database, name string) string {
+	return quoteIdentifier(database) + "." + quoteIdentifier(name)
+}
+
+// quoteTableReference quotes a table reference configured as table or database.table. References
+// already containing quotes are kept as is.
+func quoteTableReference(reference string) string {
+	if strings.Contains(reference, "`") {
+		return reference
+	}
+	if database, name, ok := strings.Cut(reference, "."); ok {
+		return qualifiedName(database, name)
+	}
+	return quoteIdentifier(reference)
+}
+
+// joinIdentifiers joins names into a comma separated list of quoted identifiers, keeping the ALL keyword
+func joinIdentifiers(values []types.String) string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = quoteIdentifier(value.ValueString())
+		if strings.EqualFold(value.ValueString(), "ALL") {
+			names[i] = value.ValueString()
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// redactPassword hides the password in a statement reported in diagnostics
+func redactPassword(statement string, password types.String) string {
+	if password.IsNull() || password.ValueString() == "" {
+		return statement
+	}
+	return strings.ReplaceAll(statement, quoteString(password.ValueString()), "'[redacted]'")
+}
+
+// joinValues joins string values into a comma separated list
+func joinValues(values []types.String) string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = value.ValueString()
+	}
+	return strings.Join(names, ", ")
+}
+
+// stringValues converts values read from the server, keeping the prior value when
+// both are empty so an unset list is not reported as an empty one.
+func stringValues(values []string, prior []types.String) []types.String {
+	if len(values) == 0 {
+		if prior == nil {
+			return nil
+		}
+		return []types.String{}
+	}
+
+	result := make([]types.String, len(values))
+	for i, value := range values {
+		result[i] = types.StringValue(value)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestUserAlterStatementRename(t *testing.T) {
+	state := UserResourceModel{
+		Name:            types.StringValue("analyst"),
+		AuthType:        types.StringValue(authTypeSHA256),
+		Password:        types.StringValue("secret"),
+		DefaultDatabase: types.StringNull(),
+		SettingsProfile: types.StringValue("readonly"),
+	}
+	renamed := state
+	renamed.Name = types.StringValue("data analyst")
+
+	statement := userAlterStatement(state, renamed, "main")
+	expected := "ALTER USER `analyst` ON CLUSTER `main` RENAME TO `data analyst` IDENTIFIED WITH sha256_password BY 'secret'"
+	if !strings.HasPrefix(statement, expected) {
+		t.Errorf("expected the statement to start with %q, got %q", expected, statement)
+	}
+	if !strings.Contains(statement, "SETTINGS PROFILE 'readonly'") {
+		t.Errorf("expected the settings profile to be kept, got %q", statement)
+	}
+
+	if statement := userAlterStatement(state, state, ""); strings.Contains(statement, "RENAME") {
+		t.Errorf("expected no rename when the name is unchanged, got %q", statement)
+	}
+}
+
+func TestUserResourceStateAccessRights(t *testing.T) {
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	(&UserResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected schema diagnostics: %v", schemaResp.Diagnostics)
+	}
+
+	name := func(value string) sql.NullString { return sql.NullString{String: value, Valid: true} }
+	data := UserResourceModel{
+		ID:              types.StringValue("analyst"),
+		Name:            types.StringValue("analyst"),
+		AuthType:        types.StringValue(authTypeNone),
+		Password:        types.StringNull(),
+		DefaultDatabase: types.StringNull(),
+		SettingsProfile: types.StringNull(),
+		Cluster:         types.StringNull(),
+		Grants: grantList([]grantRow{
+			{AccessType: "SELECT", Database: name("analytics")},
+			{AccessType: "SELECT", Database: name("analytics"), Table: name("secrets"), PartialRevoke: true},
+			{AccessType: "INSERT", Database: name("analytics"), Table: name("events"), Column: name("id"), GrantOption: true},
+		}),
+		GrantedRoles: roleGrantList([]roleGrantRow{{Role: "reader", Default: true}}),
+	}
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	if diags := state.Set(ctx, &data); diags.HasError() {
+		t.Fatalf("could not set the user state: %v", diags)
+	}
+
+	var read UserResourceModel
+	if diags := state.Get(ctx, &read); diags.HasError() {
+		t.Fatalf("could not read the user state: %v", diags)
+	}
+	if !read.Grants.Equal(data.Grants) || !read.GrantedRoles.Equal(data.GrantedRoles) {
+		t.Errorf("expected grants %v and roles %v, got %v and %v", data.Grants, data.GrantedRoles, read.Grants, read.GrantedRoles)
+	}
+
+	grant := read.Grants.Elements()[0].(types.Object).Attributes()
+	if !grant["table"].IsNull() || grant["database"].(types.String).ValueString() != "analytics" {
+		t.Errorf("expected a database wide grant, got %v", grant)
+	}
+}
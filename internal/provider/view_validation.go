@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// validateView reports view attributes that do not apply to the kind of the view and window views
+// whose query does not group by a time window.
+func validateView(attributes map[string]attr.Value, viewPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	materialized, _ := attributes["materialized"].(types.Bool)
+	window, _ := attributes["window"].(types.Bool)
+	if materialized.IsUnknown() || window.IsUnknown() {
+		return diags
+	}
+
+	if materialized.ValueBool() && window.ValueBool() {
+		diags.AddAttributeError(viewPath.AtName("window"), "Invalid view kind",
+			"A view cannot be both materialized and a window view.")
+		return diags
+	}
+
+	if refresh := attributes["refresh"]; !materialized.ValueBool() && !refresh.IsNull() {
+		diags.AddAttributeError(viewPath.AtName("refresh"), "Invalid view attribute",
+			"refresh is only supported by materialized views.")
+	}
+	if attributes["refresh"].IsNull() {
+		if !attributes["depends_on"].IsNull() {
+			diags.AddAttributeError(viewPath.AtName("depends_on"), "Invalid view attribute", "depends_on requires refresh.")
+		}
+		if appendRows, _ := attributes["append"].(types.Bool); appendRows.ValueBool() {
+			diags.AddAttributeError(viewPath.AtName("append"), "Invalid view attribute", "append requires refresh.")
+		}
+	}
+
+	if populate, _ := attributes["populate"].(types.Bool); populate.ValueBool() {
+		switch {
+		case !materialized.ValueBool() && !window.ValueBool():
+			diags.AddAttributeError(viewPath.AtName("populate"), "Invalid view attribute",
+				"populate is only supported by materialized and window views.")
+		case materialized.ValueBool() && !attributes["to"].IsNull():
+			diags.AddAttributeError(viewPath.AtName("populate"), "Invalid view attribute",
+				"populate is not supported by materialized views with to; insert the existing rows into the target table instead.")
+		case !attributes["refresh"].IsNull():
+			diags.AddAttributeError(viewPath.AtName("populate"), "Invalid view attribute",
+				"populate is not supported by refreshable materialized views, which are filled by their first refresh.")
+		}
+	}
+
+	sqlSecurity, _ := attributes["sql_security"].(types.String)
+	if !sqlSecurity.IsNull() && !sqlSecurity.IsUnknown() {
+		switch value := strings.ToUpper(sqlSecurity.ValueString()); {
+		case window.ValueBool():
+			diags.AddAttributeError(viewPath.AtName("sql_security"), "Invalid view attribute",
+				"sql_security is not supported by window views.")
+		case !slices.Contains([]string{"DEFINER", "INVOKER", "NONE"}, value):
+			diags.AddAttributeError(viewPath.AtName("sql_security"), "Invalid view attribute",
+				fmt.Sprintf("sql_security must be DEFINER, INVOKER or NONE, got %s.", sqlSecurity.ValueString()))
+		case value == "INVOKER" && materialized.ValueBool():
+			diags.AddAttributeError(viewPath.AtName("sql_security"), "Invalid view attribute",
+				"Materialized views run on insert without a caller, so they support DEFINER and NONE only.")
+		}
+	}
+	if !attributes["definer"].IsNull() && !sqlSecurity.IsUnknown() && !strings.EqualFold(sqlSecurity.ValueString(), "DEFINER") {
+		diags.AddAttributeError(viewPath.AtName("definer"), "Invalid view attribute",
+			"definer requires sql_security = \"DEFINER\".")
+	}
+
+	if !window.ValueBool() {
+		for _, attribute := range []string{"watermark", "allowed_lateness"} {
+			if !attributes[attribute].IsNull() {
+				diags.AddAttributeError(viewPath.AtName(attribute), "Invalid view attribute",
+					attribute+" is only supported by window views.")
+			}
+		}
+		if to := attributes["to"]; !materialized.ValueBool() && !to.IsNull() {
+			diags.AddAttributeError(viewPath.AtName("to"), "Invalid view attribute",
+				"to is only supported by materialized and window views.")
+		}
+		return diags
+	}
+
+	if !attributes["allowed_lateness"].IsNull() && attributes["watermark"].IsNull() {
+		diags.AddAttributeError(viewPath.AtName("allowed_lateness"), "Invalid view attribute",
+			"allowed_lateness requires watermark.")
+	}
+	if query, _ := attributes["query"].(types.String); !query.IsUnknown() && !timeWindowPattern.MatchString(query.ValueString()) {
+		diags.AddAttributeError(viewPath.AtName("query"), "Missing time window",
+			"The query of a window view must group by a tumble or hop time window, e.g. "+
+				"GROUP BY tumble(timestamp, INTERVAL '1' MINUTE).")
+	}
+
+	return diags
+}